@@ -15,10 +15,12 @@
 package agent
 
 import (
+	"fmt"
 	"sync"
 
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-log-collection/errors"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
 	"github.com/open-telemetry/opentelemetry-log-collection/pipeline"
 )
@@ -29,15 +31,42 @@ type LogAgent struct {
 	*zap.SugaredLogger
 	startOnce sync.Once
 	stopOnce  sync.Once
+
+	metricsAddress string
+	metricsServer  *metricsServer
+
+	// The context and default output used to build the pipeline, so that it can be rebuilt on reload
+	buildContext  operator.BuildContext
+	defaultOutput operator.Operator
+
+	mux       sync.Mutex
+	persister operator.Persister
+	stopped   bool
+}
+
+// reloadablePipeline is implemented by pipelines that can replace their operators while running
+type reloadablePipeline interface {
+	Reload(bc operator.BuildContext, cfg pipeline.Config, defaultOperator operator.Operator, persister operator.Persister) error
 }
 
 // Start will start the log monitoring process
 func (a *LogAgent) Start(persister operator.Persister) (err error) {
 	a.startOnce.Do(func() {
+		if a.metricsAddress != "" {
+			a.metricsServer, err = newMetricsServer(a, a.metricsAddress)
+			if err != nil {
+				return
+			}
+		}
+
+		a.mux.Lock()
+		defer a.mux.Unlock()
 		err = a.pipeline.Start(persister)
 		if err != nil {
+			a.stopMetricsServer()
 			return
 		}
+		a.persister = persister
 	})
 	return
 }
@@ -45,6 +74,11 @@ func (a *LogAgent) Start(persister operator.Persister) (err error) {
 // Stop will stop the log monitoring process
 func (a *LogAgent) Stop() (err error) {
 	a.stopOnce.Do(func() {
+		a.stopMetricsServer()
+
+		a.mux.Lock()
+		defer a.mux.Unlock()
+		a.stopped = true
 		err = a.pipeline.Stop()
 		if err != nil {
 			return
@@ -52,3 +86,59 @@ func (a *LogAgent) Stop() (err error) {
 	})
 	return
 }
+
+// stopMetricsServer stops the metrics server, if it is running
+func (a *LogAgent) stopMetricsServer() {
+	if a.metricsServer == nil {
+		return
+	}
+	if err := a.metricsServer.stop(); err != nil {
+		a.Errorw("Failed to stop metrics server", "error", err)
+	}
+	a.metricsServer = nil
+}
+
+// Metrics returns the current metrics of the operators in the agent's pipeline,
+// or nil if the pipeline does not report metrics
+func (a *LogAgent) Metrics() []operator.Metric {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if provider, ok := a.pipeline.(pipeline.MetricsProvider); ok {
+		return provider.Metrics()
+	}
+	return nil
+}
+
+// Reload will apply a new config to the agent.
+//
+// If the agent is running, only the operators whose config or outputs changed are replaced,
+// and operators that keep their ID keep their persisted state, so files are not read again.
+// An invalid config is rejected, and the agent continues to run with its previous config.
+func (a *LogAgent) Reload(cfg *Config) error {
+	if len(cfg.Pipeline) == 0 {
+		return errors.NewError("empty pipeline not allowed", "")
+	}
+
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	if a.stopped {
+		return errors.NewError("agent cannot be reloaded after it has been stopped", "")
+	}
+
+	// The agent has not been started, so the pipeline can simply be replaced
+	if a.persister == nil {
+		pipeline, err := cfg.Pipeline.BuildPipeline(a.buildContext, a.defaultOutput)
+		if err != nil {
+			return err
+		}
+		a.pipeline = pipeline
+		return nil
+	}
+
+	reloadable, ok := a.pipeline.(reloadablePipeline)
+	if !ok {
+		return fmt.Errorf("pipeline of type %T does not support reloading", a.pipeline)
+	}
+	return reloadable.Reload(a.buildContext, cfg.Pipeline, a.defaultOutput, a.persister)
+}
@@ -16,11 +16,21 @@ package agent
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/input/file"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/transformer/add"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/pipeline"
 	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
 )
 
@@ -55,6 +65,31 @@ func TestStartAgentFailure(t *testing.T) {
 	pipeline.AssertCalled(t, "Start", persister)
 }
 
+func TestStartAgentFailureStopsMetricsServer(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	pipeline := &testutil.Pipeline{}
+	persister := testutil.NewMockPersister("test")
+	pipeline.On("Start", persister).Return(fmt.Errorf("failed to start pipeline"))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	agent := LogAgent{
+		SugaredLogger:  logger,
+		pipeline:       pipeline,
+		metricsAddress: address,
+	}
+	require.Error(t, agent.Start(persister))
+	require.Nil(t, agent.metricsServer)
+
+	// The address is free again once the metrics server has stopped
+	listener, err = net.Listen("tcp", address)
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+}
+
 func TestStopAgentSuccess(t *testing.T) {
 	logger := zap.NewNop().Sugar()
 	pipeline := &testutil.Pipeline{}
@@ -83,3 +118,103 @@ func TestStopAgentPipelineFailure(t *testing.T) {
 	require.Error(t, err, failure)
 	pipeline.AssertCalled(t, "Stop")
 }
+
+func newReloadConfig(dir, value string) *Config {
+	fileCfg := file.NewInputConfig("file")
+	fileCfg.Include = []string{filepath.Join(dir, "*.log")}
+	fileCfg.StartAt = "beginning"
+	fileCfg.PollInterval = helper.Duration{Duration: 50 * time.Millisecond}
+
+	addCfg := add.NewAddOperatorConfig("add")
+	addCfg.Field = entry.NewAttributeField("value")
+	addCfg.Value = value
+
+	return &Config{
+		Pipeline: pipeline.Config{
+			{Builder: fileCfg},
+			{Builder: addCfg},
+		},
+	}
+}
+
+func newReloadAgent(t *testing.T, cfg *Config) (*LogAgent, *testutil.FakeOutput) {
+	fake := testutil.NewFakeOutput(t)
+	agent, err := NewBuilder(zap.NewNop().Sugar()).
+		WithConfig(cfg).
+		WithDefaultOutput(fake).
+		Build()
+	require.NoError(t, err)
+	return agent, fake
+}
+
+func expectLine(t *testing.T, fake *testutil.FakeOutput, body, value string) {
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, body, e.Body)
+		require.Equal(t, value, e.Attributes["value"])
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
+
+func TestReloadPreservesOffsets(t *testing.T) {
+	dir := testutil.NewTempDir(t)
+	path := filepath.Join(dir, "test.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("one\ntwo\n"), 0600))
+
+	agent, fake := newReloadAgent(t, newReloadConfig(dir, "before"))
+	require.NoError(t, agent.Start(testutil.NewMockPersister("test")))
+	defer agent.Stop()
+
+	expectLine(t, fake, "one", "before")
+	expectLine(t, fake, "two", "before")
+
+	require.NoError(t, agent.Reload(newReloadConfig(dir, "after")))
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString("three\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// The file input was restarted to connect it to the new add operator, but continues from its offset
+	expectLine(t, fake, "three", "after")
+	fake.ExpectNoEntry(t, 200*time.Millisecond)
+}
+
+func TestReloadInvalidConfig(t *testing.T) {
+	dir := testutil.NewTempDir(t)
+	agent, fake := newReloadAgent(t, newReloadConfig(dir, "before"))
+	require.NoError(t, agent.Start(testutil.NewMockPersister("test")))
+	defer agent.Stop()
+
+	cfg := newReloadConfig(dir, "after")
+	cfg.Pipeline[1].Builder.(*add.AddOperatorConfig).OutputIDs = []string{"missing"}
+	require.Error(t, agent.Reload(cfg))
+	require.Error(t, agent.Reload(&Config{}))
+
+	// The previous pipeline is still running
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "test.log"), []byte("one\n"), 0600))
+	expectLine(t, fake, "one", "before")
+}
+
+func TestReloadBeforeStart(t *testing.T) {
+	dir := testutil.NewTempDir(t)
+	agent, fake := newReloadAgent(t, newReloadConfig(dir, "before"))
+	require.NoError(t, agent.Reload(newReloadConfig(dir, "after")))
+
+	require.NoError(t, agent.Start(testutil.NewMockPersister("test")))
+	defer agent.Stop()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "test.log"), []byte("one\n"), 0600))
+	expectLine(t, fake, "one", "after")
+}
+
+func TestReloadAfterStop(t *testing.T) {
+	dir := testutil.NewTempDir(t)
+	agent, _ := newReloadAgent(t, newReloadConfig(dir, "before"))
+	require.NoError(t, agent.Start(testutil.NewMockPersister("test")))
+	require.NoError(t, agent.Stop())
+
+	require.Error(t, agent.Reload(newReloadConfig(dir, "after")))
+}
@@ -32,6 +32,7 @@ type LogAgentBuilder struct {
 	logger        *zap.SugaredLogger
 	pluginDir     string
 	configFiles   []string
+	metricsAddr   string
 }
 
 // NewBuilder creates a new LogAgentBuilder
@@ -59,6 +60,12 @@ func (b *LogAgentBuilder) WithConfig(cfg *Config) *LogAgentBuilder {
 	return b
 }
 
+// WithMetricsAddress serves the agent's metrics in the Prometheus text format on the given address while it is running
+func (b *LogAgentBuilder) WithMetricsAddress(address string) *LogAgentBuilder {
+	b.metricsAddr = address
+	return b
+}
+
 // WithDefaultOutput adds a default output when building a log agent
 func (b *LogAgentBuilder) WithDefaultOutput(defaultOutput operator.Operator) *LogAgentBuilder {
 	b.defaultOutput = defaultOutput
@@ -105,7 +112,10 @@ func (b *LogAgentBuilder) Build() (*LogAgent, error) {
 	}
 
 	return &LogAgent{
-		pipeline:      pipeline,
-		SugaredLogger: b.logger,
+		pipeline:       pipeline,
+		SugaredLogger:  b.logger,
+		metricsAddress: b.metricsAddr,
+		buildContext:   buildContext,
+		defaultOutput:  b.defaultOutput,
 	}, nil
 }
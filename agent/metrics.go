@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+)
+
+const metricsPath = "/metrics"
+
+// metricsServer serves the metrics of an agent in the Prometheus text format
+type metricsServer struct {
+	agent    *LogAgent
+	server   *http.Server
+	listener net.Listener
+}
+
+func newMetricsServer(agent *LogAgent, address string) (*metricsServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listen on metrics address: %s", err)
+	}
+
+	s := &metricsServer{
+		agent:    agent,
+		listener: listener,
+	}
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, s)
+	s.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			agent.Errorw("Metrics server failed", zap.Error(err))
+		}
+	}()
+	return s, nil
+}
+
+func (s *metricsServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := writePrometheus(w, s.agent.Metrics()); err != nil {
+		s.agent.Errorw("Failed to write metrics", zap.Error(err))
+	}
+}
+
+func (s *metricsServer) stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.server.Shutdown(ctx)
+	// Shutdown only closes the listener once the server has started serving on it
+	_ = s.listener.Close()
+	return err
+}
+
+// writePrometheus writes metrics in the Prometheus text exposition format.
+// Metrics with the same name are grouped together under a single HELP and TYPE line.
+func writePrometheus(w io.Writer, metrics []operator.Metric) error {
+	names := make([]string, 0)
+	families := make(map[string][]operator.Metric)
+	for _, metric := range metrics {
+		if _, ok := families[metric.Name]; !ok {
+			names = append(names, metric.Name)
+		}
+		families[metric.Name] = append(families[metric.Name], metric)
+	}
+
+	buf := bufio.NewWriter(w)
+	for _, name := range names {
+		family := families[name]
+		fmt.Fprintf(buf, "# HELP %s %s\n", name, escapeHelp(family[0].Help))
+		fmt.Fprintf(buf, "# TYPE %s %s\n", name, family[0].Type)
+
+		for _, metric := range family {
+			if metric.Type != operator.HistogramMetric {
+				fmt.Fprintf(buf, "%s%s %s\n", name, formatLabels(metric.Labels, "", ""), formatFloat(metric.Value))
+				continue
+			}
+
+			h := metric.Histogram
+			if h == nil {
+				continue
+			}
+			for i, bound := range h.Bounds {
+				fmt.Fprintf(buf, "%s_bucket%s %d\n", name, formatLabels(metric.Labels, "le", formatFloat(bound)), h.Counts[i])
+			}
+			fmt.Fprintf(buf, "%s_bucket%s %d\n", name, formatLabels(metric.Labels, "le", "+Inf"), h.Count)
+			fmt.Fprintf(buf, "%s_sum%s %s\n", name, formatLabels(metric.Labels, "", ""), formatFloat(h.Sum))
+			fmt.Fprintf(buf, "%s_count%s %d\n", name, formatLabels(metric.Labels, "", ""), h.Count)
+		}
+	}
+	return buf.Flush()
+}
+
+// formatLabels renders labels sorted by key, with an optional extra label appended
+func formatLabels(labels map[string]string, extraKey, extraValue string) string {
+	if len(labels) == 0 && extraKey == "" {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, k, escapeLabelValue(labels[k])))
+	}
+	if extraKey != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extraKey, escapeLabelValue(extraValue)))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/pipeline"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+var testMetrics = []operator.Metric{
+	{
+		Name:   "entries_total",
+		Help:   "Entries received.",
+		Type:   operator.CounterMetric,
+		Labels: map[string]string{"operator_id": "$.a", "operator_type": "noop"},
+		Value:  3,
+	},
+	{
+		Name:   "queue_length",
+		Help:   "Entries queued.",
+		Type:   operator.GaugeMetric,
+		Labels: map[string]string{"path": `C:\logs\"app".log`},
+		Value:  1.5,
+	},
+	{
+		Name:   "entries_total",
+		Help:   "Entries received.",
+		Type:   operator.CounterMetric,
+		Labels: map[string]string{"operator_id": "$.b", "operator_type": "noop"},
+		Value:  4,
+	},
+	{
+		Name:   "duration_seconds",
+		Help:   "Time spent.",
+		Type:   operator.HistogramMetric,
+		Labels: map[string]string{"operator_id": "$.a"},
+		Histogram: &operator.HistogramValue{
+			Bounds: []float64{0.1, 1},
+			Counts: []uint64{1, 3},
+			Count:  4,
+			Sum:    2.25,
+		},
+	},
+}
+
+const expectedPrometheus = `# HELP entries_total Entries received.
+# TYPE entries_total counter
+entries_total{operator_id="$.a",operator_type="noop"} 3
+entries_total{operator_id="$.b",operator_type="noop"} 4
+# HELP queue_length Entries queued.
+# TYPE queue_length gauge
+queue_length{path="C:\\logs\\\"app\".log"} 1.5
+# HELP duration_seconds Time spent.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{operator_id="$.a",le="0.1"} 1
+duration_seconds_bucket{operator_id="$.a",le="1"} 3
+duration_seconds_bucket{operator_id="$.a",le="+Inf"} 4
+duration_seconds_sum{operator_id="$.a"} 2.25
+duration_seconds_count{operator_id="$.a"} 4
+`
+
+func TestWritePrometheus(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writePrometheus(&buf, testMetrics))
+	require.Equal(t, expectedPrometheus, buf.String())
+}
+
+func TestMetricsServer(t *testing.T) {
+	pipeline := &testutil.Pipeline{}
+	persister := testutil.NewMockPersister("test")
+	pipeline.On("Start", persister).Return(nil)
+	pipeline.On("Stop").Return(nil)
+	pipeline.On("Metrics").Return(testMetrics)
+
+	agent := &LogAgent{
+		SugaredLogger:  zap.NewNop().Sugar(),
+		pipeline:       pipeline,
+		metricsAddress: "127.0.0.1:0",
+	}
+	require.NoError(t, agent.Start(persister))
+	defer func() { require.NoError(t, agent.Stop()) }()
+
+	resp, err := http.Get("http://" + agent.metricsServer.listener.Addr().String() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, resp.Header.Get("Content-Type"), "text/plain")
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, expectedPrometheus, string(body))
+}
+
+// basicPipeline is a pipeline that does not report metrics
+type basicPipeline struct {
+	pipeline.Pipeline
+}
+
+func TestMetricsWithoutProvider(t *testing.T) {
+	agent := &LogAgent{
+		SugaredLogger: zap.NewNop().Sugar(),
+		pipeline:      basicPipeline{&testutil.Pipeline{}},
+	}
+	require.Nil(t, agent.Metrics())
+}
+
+func TestMetricsServerInvalidAddress(t *testing.T) {
+	pipeline := &testutil.Pipeline{}
+	persister := testutil.NewMockPersister("test")
+
+	agent := &LogAgent{
+		SugaredLogger:  zap.NewNop().Sugar(),
+		pipeline:       pipeline,
+		metricsAddress: "127.0.0.1:notaport",
+	}
+	require.Error(t, agent.Start(persister))
+	pipeline.AssertNotCalled(t, "Start", persister)
+}
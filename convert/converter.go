@@ -0,0 +1,283 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package convert translates between entries and OpenTelemetry log data.
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+// Convert converts a single entry into logs containing one log record.
+func Convert(e *entry.Entry) pdata.Logs {
+	return ConvertBatch([]*entry.Entry{e})
+}
+
+// ConvertBatch converts entries into logs. Entries that share the same resource
+// are placed in the same ResourceLogs, preserving their relative order.
+func ConvertBatch(entries []*entry.Entry) pdata.Logs {
+	logs := pdata.NewLogs()
+	resourceLogs := logs.ResourceLogs()
+	records := make(map[string]pdata.LogSlice)
+
+	for _, e := range entries {
+		key := resourceKey(e.Resource)
+		slice, ok := records[key]
+		if !ok {
+			rls := resourceLogs.AppendEmpty()
+			insertToAttributeMap(e.Resource, rls.Resource().Attributes())
+			slice = rls.InstrumentationLibraryLogs().AppendEmpty().Logs()
+			records[key] = slice
+		}
+		ConvertInto(e, slice.AppendEmpty())
+	}
+
+	return logs
+}
+
+// ConvertInto converts an entry into an existing log record.
+func ConvertInto(e *entry.Entry, dest pdata.LogRecord) {
+	if !e.Timestamp.IsZero() {
+		dest.SetTimestamp(pdata.NewTimestampFromTime(e.Timestamp))
+	}
+
+	dest.SetSeverityNumber(toSeverityNumber(e.Severity))
+	dest.SetSeverityText(e.SeverityText)
+
+	insertToAttributeMap(e.Attributes, dest.Attributes())
+	insertToAttributeVal(e.Body, dest.Body())
+
+	if len(e.TraceId) == 16 {
+		var traceID [16]byte
+		copy(traceID[:], e.TraceId)
+		dest.SetTraceID(pdata.NewTraceID(traceID))
+	}
+	if len(e.SpanId) == 8 {
+		var spanID [8]byte
+		copy(spanID[:], e.SpanId)
+		dest.SetSpanID(pdata.NewSpanID(spanID))
+	}
+	if len(e.TraceFlags) > 0 {
+		dest.SetFlags(uint32(e.TraceFlags[0]))
+	}
+}
+
+// ConvertFrom converts every log record in logs into an entry, with the resource
+// of the enclosing ResourceLogs copied onto each entry.
+func ConvertFrom(logs pdata.Logs) []*entry.Entry {
+	entries := make([]*entry.Entry, 0, logs.LogRecordCount())
+
+	resourceLogs := logs.ResourceLogs()
+	for i := 0; i < resourceLogs.Len(); i++ {
+		rls := resourceLogs.At(i)
+		resource := rls.Resource().Attributes()
+		ills := rls.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			records := ills.At(j).Logs()
+			for k := 0; k < records.Len(); k++ {
+				e := ConvertFromRecord(records.At(k))
+				e.Resource = fromAttributeMap(resource)
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	return entries
+}
+
+// ConvertFromRecord converts a log record into an entry. The entry has no resource.
+func ConvertFromRecord(record pdata.LogRecord) *entry.Entry {
+	e := &entry.Entry{
+		Body:         fromAttributeVal(record.Body()),
+		Attributes:   fromAttributeMap(record.Attributes()),
+		Severity:     fromSeverityNumber(record.SeverityNumber()),
+		SeverityText: record.SeverityText(),
+	}
+
+	if ts := record.Timestamp(); ts != 0 {
+		e.Timestamp = ts.AsTime()
+	}
+
+	if traceID := record.TraceID(); !traceID.IsEmpty() {
+		b := traceID.Bytes()
+		e.TraceId = b[:]
+	}
+	if spanID := record.SpanID(); !spanID.IsEmpty() {
+		b := spanID.Bytes()
+		e.SpanId = b[:]
+	}
+	if flags := record.Flags(); flags != 0 {
+		e.TraceFlags = []byte{byte(flags)}
+	}
+
+	return e
+}
+
+// resourceKey returns a key that is equal for resources with equal contents
+func resourceKey(resource map[string]interface{}) string {
+	if len(resource) == 0 {
+		return ""
+	}
+
+	// Maps are marshalled with sorted keys, so equal resources produce equal keys
+	if encoded, err := json.Marshal(resource); err == nil {
+		return string(encoded)
+	}
+
+	keys := make([]string, 0, len(resource))
+	for k := range resource {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	key := ""
+	for _, k := range keys {
+		key += fmt.Sprintf("%q=%v;", k, resource[k])
+	}
+	return key
+}
+
+func toSeverityNumber(severity entry.Severity) pdata.SeverityNumber {
+	if severity < entry.Default || severity > entry.Fatal4 {
+		return pdata.SeverityNumberUNDEFINED
+	}
+	// Entry severities are defined in the same order as the OpenTelemetry severity numbers
+	return pdata.SeverityNumber(severity)
+}
+
+func fromSeverityNumber(number pdata.SeverityNumber) entry.Severity {
+	if number < pdata.SeverityNumberUNDEFINED || number > pdata.SeverityNumberFATAL4 {
+		return entry.Default
+	}
+	return entry.Severity(number)
+}
+
+func insertToAttributeMap(values map[string]interface{}, dest pdata.AttributeMap) {
+	dest.EnsureCapacity(len(values))
+	for k, v := range values {
+		val := pdata.NewAttributeValueEmpty()
+		insertToAttributeVal(v, val)
+		dest.Insert(k, val)
+	}
+}
+
+func insertToAttributeVal(value interface{}, dest pdata.AttributeValue) {
+	switch t := value.(type) {
+	case nil:
+	case bool:
+		dest.SetBoolVal(t)
+	case string:
+		dest.SetStringVal(t)
+	case []byte:
+		dest.SetBytesVal(t)
+	case int64:
+		dest.SetIntVal(t)
+	case int32:
+		dest.SetIntVal(int64(t))
+	case int16:
+		dest.SetIntVal(int64(t))
+	case int8:
+		dest.SetIntVal(int64(t))
+	case int:
+		dest.SetIntVal(int64(t))
+	case uint64:
+		dest.SetIntVal(int64(t))
+	case uint32:
+		dest.SetIntVal(int64(t))
+	case uint16:
+		dest.SetIntVal(int64(t))
+	case uint8:
+		dest.SetIntVal(int64(t))
+	case uint:
+		dest.SetIntVal(int64(t))
+	case float64:
+		dest.SetDoubleVal(t)
+	case float32:
+		dest.SetDoubleVal(float64(t))
+	case map[string]interface{}:
+		pdata.NewAttributeValueMap().CopyTo(dest)
+		insertToAttributeMap(t, dest.MapVal())
+	case map[string]string:
+		pdata.NewAttributeValueMap().CopyTo(dest)
+		m := dest.MapVal()
+		for k, v := range t {
+			m.InsertString(k, v)
+		}
+	case []interface{}:
+		pdata.NewAttributeValueArray().CopyTo(dest)
+		slice := dest.SliceVal()
+		slice.EnsureCapacity(len(t))
+		for _, v := range t {
+			insertToAttributeVal(v, slice.AppendEmpty())
+		}
+	case []string:
+		pdata.NewAttributeValueArray().CopyTo(dest)
+		slice := dest.SliceVal()
+		slice.EnsureCapacity(len(t))
+		for _, v := range t {
+			slice.AppendEmpty().SetStringVal(v)
+		}
+	default:
+		dest.SetStringVal(fmt.Sprintf("%v", t))
+	}
+}
+
+func fromAttributeMap(attributes pdata.AttributeMap) map[string]interface{} {
+	if attributes.Len() == 0 {
+		return nil
+	}
+
+	values := make(map[string]interface{}, attributes.Len())
+	attributes.Range(func(k string, v pdata.AttributeValue) bool {
+		values[k] = fromAttributeVal(v)
+		return true
+	})
+	return values
+}
+
+func fromAttributeVal(value pdata.AttributeValue) interface{} {
+	switch value.Type() {
+	case pdata.AttributeValueTypeString:
+		return value.StringVal()
+	case pdata.AttributeValueTypeBool:
+		return value.BoolVal()
+	case pdata.AttributeValueTypeInt:
+		return value.IntVal()
+	case pdata.AttributeValueTypeDouble:
+		return value.DoubleVal()
+	case pdata.AttributeValueTypeBytes:
+		return value.BytesVal()
+	case pdata.AttributeValueTypeMap:
+		values := fromAttributeMap(value.MapVal())
+		if values == nil {
+			values = map[string]interface{}{}
+		}
+		return values
+	case pdata.AttributeValueTypeArray:
+		slice := value.SliceVal()
+		values := make([]interface{}, 0, slice.Len())
+		for i := 0; i < slice.Len(); i++ {
+			values = append(values, fromAttributeVal(slice.At(i)))
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+)
+
+func newTestEntry() *entry.Entry {
+	return &entry.Entry{
+		Timestamp: time.Date(2022, time.January, 2, 3, 4, 5, 6, time.UTC),
+		Body: map[string]interface{}{
+			"message": "hello",
+			"count":   int64(3),
+			"tags":    []interface{}{"a", "b"},
+		},
+		Attributes: map[string]interface{}{
+			"string": "value",
+			"bool":   true,
+			"double": 1.5,
+			"http": map[string]interface{}{
+				"status": int64(200),
+			},
+		},
+		Resource: map[string]interface{}{
+			"host": "example",
+		},
+		Severity:     entry.Error,
+		SeverityText: "ERROR",
+		TraceId:      []byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+		SpanId:       []byte{0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+		TraceFlags:   []byte{0x01},
+	}
+}
+
+func TestConvert(t *testing.T) {
+	e := newTestEntry()
+	logs := Convert(e)
+
+	require.Equal(t, 1, logs.ResourceLogs().Len())
+	rls := logs.ResourceLogs().At(0)
+	require.Equal(t, map[string]interface{}{"host": "example"}, rls.Resource().Attributes().AsRaw())
+
+	require.Equal(t, 1, rls.InstrumentationLibraryLogs().Len())
+	records := rls.InstrumentationLibraryLogs().At(0).Logs()
+	require.Equal(t, 1, records.Len())
+
+	record := records.At(0)
+	require.Equal(t, e.Timestamp, record.Timestamp().AsTime())
+	require.Equal(t, pdata.SeverityNumberERROR, record.SeverityNumber())
+	require.Equal(t, "ERROR", record.SeverityText())
+	require.Equal(t, "480140f3d770a5ae32f0a22b6a812cff", record.TraceID().HexString())
+	require.Equal(t, "32f0a22b6a812cff", record.SpanID().HexString())
+	require.Equal(t, uint32(1), record.Flags())
+
+	require.Equal(t, pdata.AttributeValueTypeMap, record.Body().Type())
+	require.Equal(t, map[string]interface{}{
+		"message": "hello",
+		"count":   int64(3),
+		"tags":    []interface{}{"a", "b"},
+	}, record.Body().MapVal().AsRaw())
+
+	require.Equal(t, map[string]interface{}{
+		"string": "value",
+		"bool":   true,
+		"double": 1.5,
+		"http": map[string]interface{}{
+			"status": int64(200),
+		},
+	}, record.Attributes().AsRaw())
+}
+
+func TestConvertBodyTypes(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     interface{}
+		expected pdata.AttributeValue
+	}{
+		{"String", "hello", pdata.NewAttributeValueString("hello")},
+		{"Bytes", []byte("hello"), pdata.NewAttributeValueBytes([]byte("hello"))},
+		{"Int", 12, pdata.NewAttributeValueInt(12)},
+		{"Uint8", uint8(12), pdata.NewAttributeValueInt(12)},
+		{"Float32", float32(1.5), pdata.NewAttributeValueDouble(1.5)},
+		{"Bool", false, pdata.NewAttributeValueBool(false)},
+		{"Nil", nil, pdata.NewAttributeValueEmpty()},
+		{"Unknown", struct{ A int }{1}, pdata.NewAttributeValueString("{1}")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			record := pdata.NewLogRecord()
+			ConvertInto(&entry.Entry{Body: tc.body}, record)
+			require.True(t, tc.expected.Equal(record.Body()), "expected %s, got %s", tc.expected.AsString(), record.Body().AsString())
+		})
+	}
+}
+
+func TestConvertBatchGroupsByResource(t *testing.T) {
+	newEntry := func(body string, resource map[string]interface{}) *entry.Entry {
+		return &entry.Entry{Body: body, Resource: resource}
+	}
+
+	logs := ConvertBatch([]*entry.Entry{
+		newEntry("a1", map[string]interface{}{"host": "a", "env": "prod"}),
+		newEntry("b1", map[string]interface{}{"host": "b"}),
+		newEntry("a2", map[string]interface{}{"env": "prod", "host": "a"}),
+		newEntry("none", nil),
+		newEntry("b2", map[string]interface{}{"host": "b"}),
+	})
+
+	require.Equal(t, 5, logs.LogRecordCount())
+	require.Equal(t, 3, logs.ResourceLogs().Len())
+
+	bodies := func(i int) []string {
+		records := logs.ResourceLogs().At(i).InstrumentationLibraryLogs().At(0).Logs()
+		result := make([]string, 0, records.Len())
+		for j := 0; j < records.Len(); j++ {
+			result = append(result, records.At(j).Body().StringVal())
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"a1", "a2"}, bodies(0))
+	require.Equal(t, []string{"b1", "b2"}, bodies(1))
+	require.Equal(t, []string{"none"}, bodies(2))
+	require.Equal(t, 0, logs.ResourceLogs().At(2).Resource().Attributes().Len())
+}
+
+func TestConvertSeverity(t *testing.T) {
+	cases := []struct {
+		severity entry.Severity
+		expected pdata.SeverityNumber
+	}{
+		{entry.Default, pdata.SeverityNumberUNDEFINED},
+		{entry.Trace, pdata.SeverityNumberTRACE},
+		{entry.Debug3, pdata.SeverityNumberDEBUG3},
+		{entry.Info, pdata.SeverityNumberINFO},
+		{entry.Warn2, pdata.SeverityNumberWARN2},
+		{entry.Error4, pdata.SeverityNumberERROR4},
+		{entry.Fatal, pdata.SeverityNumberFATAL},
+		{entry.Fatal4, pdata.SeverityNumberFATAL4},
+		{entry.Severity(100), pdata.SeverityNumberUNDEFINED},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.severity.String(), func(t *testing.T) {
+			record := pdata.NewLogRecord()
+			ConvertInto(&entry.Entry{Severity: tc.severity}, record)
+			require.Equal(t, tc.expected, record.SeverityNumber())
+		})
+	}
+}
+
+func TestConvertInvalidTraceContext(t *testing.T) {
+	record := pdata.NewLogRecord()
+	ConvertInto(&entry.Entry{
+		TraceId: []byte{0x01, 0x02},
+		SpanId:  []byte{0x01},
+	}, record)
+	require.True(t, record.TraceID().IsEmpty())
+	require.True(t, record.SpanID().IsEmpty())
+	require.Equal(t, uint32(0), record.Flags())
+}
+
+func TestConvertFrom(t *testing.T) {
+	original := newTestEntry()
+	entries := ConvertFrom(Convert(original))
+	require.Len(t, entries, 1)
+	require.Equal(t, original, entries[0])
+}
+
+func TestConvertFromBatch(t *testing.T) {
+	entries := []*entry.Entry{
+		{Body: "a1", Resource: map[string]interface{}{"host": "a"}},
+		{Body: "b1", Resource: map[string]interface{}{"host": "b"}},
+		{Body: "a2", Resource: map[string]interface{}{"host": "a"}},
+	}
+
+	converted := ConvertFrom(ConvertBatch(entries))
+	require.Len(t, converted, 3)
+
+	// Entries are returned grouped by resource
+	require.Equal(t, entries[0], converted[0])
+	require.Equal(t, entries[2], converted[1])
+	require.Equal(t, entries[1], converted[2])
+}
+
+func TestConvertFromRecord(t *testing.T) {
+	record := pdata.NewLogRecord()
+	record.Body().SetStringVal("hello")
+	record.Attributes().InsertInt("count", 1)
+	record.SetSeverityNumber(pdata.SeverityNumber(99))
+
+	e := ConvertFromRecord(record)
+	require.Equal(t, "hello", e.Body)
+	require.Equal(t, map[string]interface{}{"count": int64(1)}, e.Attributes)
+	require.Nil(t, e.Resource)
+	require.Equal(t, entry.Default, e.Severity)
+	require.True(t, e.Timestamp.IsZero())
+	require.Nil(t, e.TraceId)
+	require.Nil(t, e.SpanId)
+	require.Nil(t, e.TraceFlags)
+}
+
+func BenchmarkConvertBatch(b *testing.B) {
+	entries := make([]*entry.Entry, 0, 100)
+	for i := 0; i < 100; i++ {
+		entries = append(entries, newTestEntry())
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ConvertBatch(entries)
+	}
+}
@@ -14,58 +14,77 @@
 
 package entry
 
-import (
-	"fmt"
-	"strings"
-)
+import "fmt"
 
-// AttributeField is the path to an entry attribute
+// AttributeField is the path to an entry attribute.
+// Keys beyond the first refer to values nested in maps.
 type AttributeField struct {
-	key string
+	Keys []string
 }
 
-// Get will return the attribute value and a boolean indicating if it exists
-func (l AttributeField) Get(entry *Entry) (interface{}, bool) {
-	if entry.Attributes == nil {
-		return "", false
+// Parent returns the parent of the current field.
+// In the case that the field points to the root node, it is a no-op.
+func (l AttributeField) Parent() AttributeField {
+	if l.isRoot() {
+		return l
 	}
-	val, ok := entry.Attributes[l.key]
-	return val, ok
+
+	keys := l.Keys[:len(l.Keys)-1]
+	return AttributeField{keys}
 }
 
-// Set will set the attribute value on an entry
-func (l AttributeField) Set(entry *Entry, val interface{}) error {
-	if entry.Attributes == nil {
-		entry.Attributes = make(map[string]string, 1)
+// Child returns a child of the current field using the given key.
+func (l AttributeField) Child(key string) AttributeField {
+	child := make([]string, len(l.Keys), len(l.Keys)+1)
+	copy(child, l.Keys)
+	child = append(child, key)
+	return AttributeField{child}
+}
+
+// isRoot returns a boolean indicating if this field refers to the whole map.
+func (l AttributeField) isRoot() bool {
+	return len(l.Keys) == 0
+}
+
+// Get will return the attributes value and a boolean indicating if it exists
+func (l AttributeField) Get(entry *Entry) (interface{}, bool) {
+	if l.isRoot() {
+		if entry.Attributes == nil {
+			return nil, false
+		}
+		return entry.Attributes, true
 	}
+	return getNested(entry.Attributes, l.Keys)
+}
 
-	str, ok := val.(string)
-	if !ok {
-		return fmt.Errorf("cannot set a attribute to a non-string value")
+// Set will set the attributes value on an entry.
+// If a key already exists, it will be overwritten, and map values will be merged.
+func (l AttributeField) Set(entry *Entry, val interface{}) error {
+	if _, ok := val.(map[string]interface{}); !ok && l.isRoot() {
+		return fmt.Errorf("cannot set the attributes root to a non-map value")
 	}
-	entry.Attributes[l.key] = str
+	entry.Attributes = setNested(entry.Attributes, l.Keys, val)
 	return nil
 }
 
-// Delete will delete a attribute from an entry
+// Delete will delete the attributes value from an entry
 func (l AttributeField) Delete(entry *Entry) (interface{}, bool) {
-	if entry.Attributes == nil {
-		return "", false
+	if l.isRoot() {
+		old := entry.Attributes
+		entry.Attributes = nil
+		return old, old != nil
 	}
-
-	val, ok := entry.Attributes[l.key]
-	delete(entry.Attributes, l.key)
-	return val, ok
+	return deleteNested(entry.Attributes, l.Keys)
 }
 
 func (l AttributeField) String() string {
-	if strings.Contains(l.key, ".") {
-		return fmt.Sprintf(`$attributes['%s']`, l.key)
-	}
-	return "$attributes." + l.key
+	return nestedString(AttributesPrefix, l.Keys)
 }
 
-// NewAttributeField will creat a new attribute field from a key
-func NewAttributeField(key string) Field {
-	return Field{AttributeField{key}}
+// NewAttributeField will create a new attributes field from a key path
+func NewAttributeField(keys ...string) Field {
+	if keys == nil {
+		keys = []string{}
+	}
+	return Field{AttributeField{keys}}
 }
@@ -20,37 +20,85 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func testAttributes() map[string]interface{} {
+	return map[string]interface{}{
+		"test": "val",
+		"int":  200,
+		"nested": map[string]interface{}{
+			"key": "nestedval",
+		},
+	}
+}
+
 func TestAttributeFieldGet(t *testing.T) {
 	cases := []struct {
 		name       string
-		attributes map[string]string
+		attributes map[string]interface{}
 		field      Field
 		expected   interface{}
 		expectedOK bool
 	}{
 		{
 			"Simple",
-			map[string]string{
-				"test": "val",
-			},
+			testAttributes(),
 			NewAttributeField("test"),
 			"val",
 			true,
 		},
+		{
+			"NonString",
+			testAttributes(),
+			NewAttributeField("int"),
+			200,
+			true,
+		},
+		{
+			"Nested",
+			testAttributes(),
+			NewAttributeField("nested", "key"),
+			"nestedval",
+			true,
+		},
+		{
+			"NestedMap",
+			testAttributes(),
+			NewAttributeField("nested"),
+			map[string]interface{}{"key": "nestedval"},
+			true,
+		},
+		{
+			"Root",
+			testAttributes(),
+			NewAttributeField(),
+			testAttributes(),
+			true,
+		},
 		{
 			"NonexistentKey",
-			map[string]string{
-				"test": "val",
-			},
+			testAttributes(),
 			NewAttributeField("nonexistent"),
-			"",
+			nil,
+			false,
+		},
+		{
+			"NonexistentNestedKey",
+			testAttributes(),
+			NewAttributeField("test", "nonexistent"),
+			nil,
 			false,
 		},
 		{
 			"NilMap",
 			nil,
 			NewAttributeField("nonexistent"),
-			"",
+			nil,
+			false,
+		},
+		{
+			"NilMapRoot",
+			nil,
+			NewAttributeField(),
+			nil,
 			false,
 		},
 	}
@@ -69,31 +117,51 @@ func TestAttributeFieldGet(t *testing.T) {
 func TestAttributeFieldDelete(t *testing.T) {
 	cases := []struct {
 		name               string
-		attributes         map[string]string
+		attributes         map[string]interface{}
 		field              Field
 		expected           interface{}
 		expectedOK         bool
-		expectedAttributes map[string]string
+		expectedAttributes map[string]interface{}
 	}{
 		{
 			"Simple",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewAttributeField("test"),
 			"val",
 			true,
-			map[string]string{},
+			map[string]interface{}{},
+		},
+		{
+			"Nested",
+			testAttributes(),
+			NewAttributeField("nested", "key"),
+			"nestedval",
+			true,
+			map[string]interface{}{
+				"test":   "val",
+				"int":    200,
+				"nested": map[string]interface{}{},
+			},
+		},
+		{
+			"Root",
+			testAttributes(),
+			NewAttributeField(),
+			testAttributes(),
+			true,
+			nil,
 		},
 		{
 			"NonexistentKey",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewAttributeField("nonexistent"),
-			"",
+			nil,
 			false,
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 		},
@@ -101,7 +169,7 @@ func TestAttributeFieldDelete(t *testing.T) {
 			"NilMap",
 			nil,
 			NewAttributeField("nonexistent"),
-			"",
+			nil,
 			false,
 			nil,
 		},
@@ -114,6 +182,7 @@ func TestAttributeFieldDelete(t *testing.T) {
 			val, ok := entry.Delete(tc.field)
 			require.Equal(t, tc.expectedOK, ok)
 			require.Equal(t, tc.expected, val)
+			require.Equal(t, tc.expectedAttributes, entry.Attributes)
 		})
 	}
 }
@@ -121,30 +190,30 @@ func TestAttributeFieldDelete(t *testing.T) {
 func TestAttributeFieldSet(t *testing.T) {
 	cases := []struct {
 		name        string
-		attributes  map[string]string
+		attributes  map[string]interface{}
 		field       Field
 		val         interface{}
-		expected    map[string]string
+		expected    map[string]interface{}
 		expectedErr bool
 	}{
 		{
 			"Simple",
-			map[string]string{},
+			map[string]interface{}{},
 			NewAttributeField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
 		},
 		{
 			"Overwrite",
-			map[string]string{
+			map[string]interface{}{
 				"test": "original",
 			},
 			NewAttributeField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
@@ -154,19 +223,90 @@ func TestAttributeFieldSet(t *testing.T) {
 			nil,
 			NewAttributeField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
 		},
 		{
 			"NonString",
-			map[string]string{},
+			map[string]interface{}{},
 			NewAttributeField("test"),
 			123,
-			map[string]string{
+			map[string]interface{}{
+				"test": 123,
+			},
+			false,
+		},
+		{
+			"Nested",
+			map[string]interface{}{
+				"test": "val",
+			},
+			NewAttributeField("nested", "key"),
+			"nestedval",
+			map[string]interface{}{
+				"test": "val",
+				"nested": map[string]interface{}{
+					"key": "nestedval",
+				},
+			},
+			false,
+		},
+		{
+			"OverwriteNonMap",
+			map[string]interface{}{
 				"test": "val",
 			},
+			NewAttributeField("test", "key"),
+			"nestedval",
+			map[string]interface{}{
+				"test": map[string]interface{}{
+					"key": "nestedval",
+				},
+			},
+			false,
+		},
+		{
+			"MergeMap",
+			map[string]interface{}{
+				"nested": map[string]interface{}{
+					"key1": "val1",
+				},
+			},
+			NewAttributeField("nested"),
+			map[string]interface{}{
+				"key2": "val2",
+			},
+			map[string]interface{}{
+				"nested": map[string]interface{}{
+					"key1": "val1",
+					"key2": "val2",
+				},
+			},
+			false,
+		},
+		{
+			"RootMap",
+			map[string]interface{}{
+				"test": "val",
+			},
+			NewAttributeField(),
+			map[string]interface{}{
+				"other": "val",
+			},
+			map[string]interface{}{
+				"test":  "val",
+				"other": "val",
+			},
+			false,
+		},
+		{
+			"RootNonMap",
+			map[string]interface{}{},
+			NewAttributeField(),
+			"val",
+			nil,
 			true,
 		},
 	}
@@ -181,11 +321,29 @@ func TestAttributeFieldSet(t *testing.T) {
 				return
 			}
 
+			require.NoError(t, err)
 			require.Equal(t, tc.expected, entry.Attributes)
 		})
 	}
 }
 
+func TestAttributeFieldParent(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		field := AttributeField{[]string{"child"}}
+		require.Equal(t, AttributeField{[]string{}}, field.Parent())
+	})
+
+	t.Run("Root", func(t *testing.T) {
+		field := AttributeField{[]string{}}
+		require.Equal(t, AttributeField{[]string{}}, field.Parent())
+	})
+}
+
+func TestAttributeFieldChild(t *testing.T) {
+	field := AttributeField{[]string{"parent"}}
+	require.Equal(t, AttributeField{[]string{"parent", "child"}}, field.Child("child"))
+}
+
 func TestAttributeFieldString(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -194,12 +352,27 @@ func TestAttributeFieldString(t *testing.T) {
 	}{
 		{
 			"Simple",
-			AttributeField{"foo"},
+			AttributeField{[]string{"foo"}},
 			"$attributes.foo",
 		},
+		{
+			"Nested",
+			AttributeField{[]string{"foo", "bar"}},
+			"$attributes.foo.bar",
+		},
+		{
+			"NestedWithDots",
+			AttributeField{[]string{"foo", "bar.baz"}},
+			"$attributes['foo']['bar.baz']",
+		},
+		{
+			"Root",
+			AttributeField{[]string{}},
+			"$attributes",
+		},
 		{
 			"Empty",
-			AttributeField{""},
+			AttributeField{[]string{""}},
 			"$attributes.",
 		},
 	}
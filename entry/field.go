@@ -70,15 +70,9 @@ func NewField(s string) (Field, error) {
 
 	switch split[0] {
 	case AttributesPrefix:
-		if len(split) != 2 {
-			return Field{}, fmt.Errorf("attributes cannot be nested")
-		}
-		return Field{AttributeField{split[1]}}, nil
+		return Field{AttributeField{split[1:]}}, nil
 	case ResourcePrefix:
-		if len(split) != 2 {
-			return Field{}, fmt.Errorf("resource fields cannot be nested")
-		}
-		return Field{ResourceField{split[1]}}, nil
+		return Field{ResourceField{split[1:]}}, nil
 	case BodyPrefix, "$":
 		return Field{BodyField{split[1:]}}, nil
 	default:
@@ -283,8 +283,23 @@ func TestFieldFromStringWithResource(t *testing.T) {
 	require.Equal(t, "$resource.test", field.String())
 }
 
-func TestFieldFromStringWithInvalidResource(t *testing.T) {
-	_, err := NewField(`$resource["test"]["key"]`)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "resource fields cannot be nested")
+func TestFieldFromStringWithNestedResource(t *testing.T) {
+	field, err := NewField(`$resource["test"]["key"]`)
+	require.NoError(t, err)
+	require.Equal(t, NewResourceField("test", "key"), field)
+	require.Equal(t, "$resource.test.key", field.String())
+}
+
+func TestFieldFromStringWithNestedAttribute(t *testing.T) {
+	field, err := NewField(`$attributes.http["status.code"]`)
+	require.NoError(t, err)
+	require.Equal(t, NewAttributeField("http", "status.code"), field)
+	require.Equal(t, "$attributes['http']['status.code']", field.String())
+}
+
+func TestFieldFromStringWithAttributesRoot(t *testing.T) {
+	field, err := NewField("$attributes")
+	require.NoError(t, err)
+	require.Equal(t, NewAttributeField(), field)
+	require.Equal(t, "$attributes", field.String())
 }
@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entry
+
+import "strings"
+
+// The functions in this file implement the nesting semantics of BodyField
+// for fields that live in a map of the entry, such as attributes and resource.
+
+// getNested retrieves the value at keys within a map.
+// It will return the value and whether it existed.
+func getNested(m map[string]interface{}, keys []string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	var currentValue interface{} = m
+	for _, key := range keys {
+		currentMap, ok := currentValue.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		currentValue, ok = currentMap[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return currentValue, true
+}
+
+// setNested sets the value at keys within a map, creating the map and any
+// intermediate maps as necessary. Map values are merged into existing maps.
+// Keys must not be empty unless the value is a map. It returns the updated map.
+func setNested(m map[string]interface{}, keys []string, value interface{}) map[string]interface{} {
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+
+	if mapValue, ok := value.(map[string]interface{}); ok {
+		currentMap := m
+		for _, key := range keys {
+			currentMap = getOrCreateMap(currentMap, key)
+		}
+		for key, v := range mapValue {
+			currentMap[key] = v
+		}
+		return m
+	}
+
+	currentMap := m
+	for i, key := range keys {
+		if i == len(keys)-1 {
+			currentMap[key] = value
+			break
+		}
+		currentMap = getOrCreateMap(currentMap, key)
+	}
+	return m
+}
+
+// deleteNested removes the value at keys within a map.
+// It will return the deleted value and whether it existed.
+func deleteNested(m map[string]interface{}, keys []string) (interface{}, bool) {
+	var currentValue interface{} = m
+	for i, key := range keys {
+		currentMap, ok := currentValue.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		currentValue, ok = currentMap[key]
+		if !ok {
+			break
+		}
+
+		if i == len(keys)-1 {
+			delete(currentMap, key)
+			return currentValue, true
+		}
+	}
+
+	return nil, false
+}
+
+// getOrCreateMap will get a nested map assigned to a key.
+// If the map does not exist, it will create and return it.
+func getOrCreateMap(currentMap map[string]interface{}, key string) map[string]interface{} {
+	nextMap, ok := currentMap[key].(map[string]interface{})
+	if !ok {
+		nextMap = map[string]interface{}{}
+		currentMap[key] = nextMap
+	}
+	return nextMap
+}
+
+// nestedString returns the string representation of keys beneath a prefix.
+func nestedString(prefix string, keys []string) string {
+	if len(keys) == 0 {
+		return prefix
+	}
+
+	containsDots := false
+	for _, key := range keys {
+		if strings.Contains(key, ".") {
+			containsDots = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	for _, key := range keys {
+		if containsDots {
+			b.WriteString(`['`)
+			b.WriteString(key)
+			b.WriteString(`']`)
+		} else {
+			b.WriteString(".")
+			b.WriteString(key)
+		}
+	}
+	return b.String()
+}
@@ -14,58 +14,77 @@
 
 package entry
 
-import (
-	"fmt"
-	"strings"
-)
+import "fmt"
 
-// ResourceField is the path to an entry's resource key
+// ResourceField is the path to an entry's resource key.
+// Keys beyond the first refer to values nested in maps.
 type ResourceField struct {
-	key string
+	Keys []string
+}
+
+// Parent returns the parent of the current field.
+// In the case that the field points to the root node, it is a no-op.
+func (r ResourceField) Parent() ResourceField {
+	if r.isRoot() {
+		return r
+	}
+
+	keys := r.Keys[:len(r.Keys)-1]
+	return ResourceField{keys}
+}
+
+// Child returns a child of the current field using the given key.
+func (r ResourceField) Child(key string) ResourceField {
+	child := make([]string, len(r.Keys), len(r.Keys)+1)
+	copy(child, r.Keys)
+	child = append(child, key)
+	return ResourceField{child}
+}
+
+// isRoot returns a boolean indicating if this field refers to the whole map.
+func (r ResourceField) isRoot() bool {
+	return len(r.Keys) == 0
 }
 
 // Get will return the resource value and a boolean indicating if it exists
 func (r ResourceField) Get(entry *Entry) (interface{}, bool) {
-	if entry.Resource == nil {
-		return "", false
+	if r.isRoot() {
+		if entry.Resource == nil {
+			return nil, false
+		}
+		return entry.Resource, true
 	}
-	val, ok := entry.Resource[r.key]
-	return val, ok
+	return getNested(entry.Resource, r.Keys)
 }
 
-// Set will set the resource value on an entry
+// Set will set the resource value on an entry.
+// If a key already exists, it will be overwritten, and map values will be merged.
 func (r ResourceField) Set(entry *Entry, val interface{}) error {
-	if entry.Resource == nil {
-		entry.Resource = make(map[string]string, 1)
-	}
-
-	str, ok := val.(string)
-	if !ok {
-		return fmt.Errorf("cannot set a resource to a non-string value")
+	if _, ok := val.(map[string]interface{}); !ok && r.isRoot() {
+		return fmt.Errorf("cannot set the resource root to a non-map value")
 	}
-	entry.Resource[r.key] = str
+	entry.Resource = setNested(entry.Resource, r.Keys, val)
 	return nil
 }
 
-// Delete will delete a resource key from an entry
+// Delete will delete the resource value from an entry
 func (r ResourceField) Delete(entry *Entry) (interface{}, bool) {
-	if entry.Resource == nil {
-		return "", false
+	if r.isRoot() {
+		old := entry.Resource
+		entry.Resource = nil
+		return old, old != nil
 	}
-
-	val, ok := entry.Resource[r.key]
-	delete(entry.Resource, r.key)
-	return val, ok
+	return deleteNested(entry.Resource, r.Keys)
 }
 
 func (r ResourceField) String() string {
-	if strings.Contains(r.key, ".") {
-		return fmt.Sprintf(`$resource['%s']`, r.key)
-	}
-	return "$resource." + r.key
+	return nestedString(ResourcePrefix, r.Keys)
 }
 
-// NewResourceField will creat a new resource field from a key
-func NewResourceField(key string) Field {
-	return Field{ResourceField{key}}
+// NewResourceField will create a new resource field from a key path
+func NewResourceField(keys ...string) Field {
+	if keys == nil {
+		keys = []string{}
+	}
+	return Field{ResourceField{keys}}
 }
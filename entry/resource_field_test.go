@@ -20,37 +20,85 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func testResource() map[string]interface{} {
+	return map[string]interface{}{
+		"test": "val",
+		"int":  200,
+		"nested": map[string]interface{}{
+			"key": "nestedval",
+		},
+	}
+}
+
 func TestResourceFieldGet(t *testing.T) {
 	cases := []struct {
 		name       string
-		resources  map[string]string
+		resource   map[string]interface{}
 		field      Field
 		expected   interface{}
 		expectedOK bool
 	}{
 		{
 			"Simple",
-			map[string]string{
-				"test": "val",
-			},
+			testResource(),
 			NewResourceField("test"),
 			"val",
 			true,
 		},
+		{
+			"NonString",
+			testResource(),
+			NewResourceField("int"),
+			200,
+			true,
+		},
+		{
+			"Nested",
+			testResource(),
+			NewResourceField("nested", "key"),
+			"nestedval",
+			true,
+		},
+		{
+			"NestedMap",
+			testResource(),
+			NewResourceField("nested"),
+			map[string]interface{}{"key": "nestedval"},
+			true,
+		},
+		{
+			"Root",
+			testResource(),
+			NewResourceField(),
+			testResource(),
+			true,
+		},
 		{
 			"NonexistentKey",
-			map[string]string{
-				"test": "val",
-			},
+			testResource(),
 			NewResourceField("nonexistent"),
-			"",
+			nil,
+			false,
+		},
+		{
+			"NonexistentNestedKey",
+			testResource(),
+			NewResourceField("test", "nonexistent"),
+			nil,
 			false,
 		},
 		{
 			"NilMap",
 			nil,
 			NewResourceField("nonexistent"),
-			"",
+			nil,
+			false,
+		},
+		{
+			"NilMapRoot",
+			nil,
+			NewResourceField(),
+			nil,
 			false,
 		},
 	}
@@ -58,7 +106,7 @@ func TestResourceFieldGet(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			entry := New()
-			entry.Resource = tc.resources
+			entry.Resource = tc.resource
 			val, ok := entry.Get(tc.field)
 			require.Equal(t, tc.expectedOK, ok)
 			require.Equal(t, tc.expected, val)
@@ -68,32 +116,52 @@ func TestResourceFieldGet(t *testing.T) {
 
 func TestResourceFieldDelete(t *testing.T) {
 	cases := []struct {
-		name              string
-		resources         map[string]string
-		field             Field
-		expected          interface{}
-		expectedOK        bool
-		expectedResources map[string]string
+		name             string
+		resource         map[string]interface{}
+		field            Field
+		expected         interface{}
+		expectedOK       bool
+		expectedResource map[string]interface{}
 	}{
 		{
 			"Simple",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewResourceField("test"),
 			"val",
 			true,
-			map[string]string{},
+			map[string]interface{}{},
+		},
+		{
+			"Nested",
+			testResource(),
+			NewResourceField("nested", "key"),
+			"nestedval",
+			true,
+			map[string]interface{}{
+				"test":   "val",
+				"int":    200,
+				"nested": map[string]interface{}{},
+			},
+		},
+		{
+			"Root",
+			testResource(),
+			NewResourceField(),
+			testResource(),
+			true,
+			nil,
 		},
 		{
 			"NonexistentKey",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewResourceField("nonexistent"),
-			"",
+			nil,
 			false,
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 		},
@@ -101,7 +169,7 @@ func TestResourceFieldDelete(t *testing.T) {
 			"NilMap",
 			nil,
 			NewResourceField("nonexistent"),
-			"",
+			nil,
 			false,
 			nil,
 		},
@@ -110,10 +178,11 @@ func TestResourceFieldDelete(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			entry := New()
-			entry.Resource = tc.resources
+			entry.Resource = tc.resource
 			val, ok := entry.Delete(tc.field)
 			require.Equal(t, tc.expectedOK, ok)
 			require.Equal(t, tc.expected, val)
+			require.Equal(t, tc.expectedResource, entry.Resource)
 		})
 	}
 }
@@ -121,30 +190,30 @@ func TestResourceFieldDelete(t *testing.T) {
 func TestResourceFieldSet(t *testing.T) {
 	cases := []struct {
 		name        string
-		resources   map[string]string
+		resource    map[string]interface{}
 		field       Field
 		val         interface{}
-		expected    map[string]string
+		expected    map[string]interface{}
 		expectedErr bool
 	}{
 		{
 			"Simple",
-			map[string]string{},
+			map[string]interface{}{},
 			NewResourceField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
 		},
 		{
 			"Overwrite",
-			map[string]string{
+			map[string]interface{}{
 				"test": "original",
 			},
 			NewResourceField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
@@ -154,19 +223,90 @@ func TestResourceFieldSet(t *testing.T) {
 			nil,
 			NewResourceField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
 		},
 		{
 			"NonString",
-			map[string]string{},
+			map[string]interface{}{},
 			NewResourceField("test"),
 			123,
-			map[string]string{
+			map[string]interface{}{
+				"test": 123,
+			},
+			false,
+		},
+		{
+			"Nested",
+			map[string]interface{}{
+				"test": "val",
+			},
+			NewResourceField("nested", "key"),
+			"nestedval",
+			map[string]interface{}{
+				"test": "val",
+				"nested": map[string]interface{}{
+					"key": "nestedval",
+				},
+			},
+			false,
+		},
+		{
+			"OverwriteNonMap",
+			map[string]interface{}{
 				"test": "val",
 			},
+			NewResourceField("test", "key"),
+			"nestedval",
+			map[string]interface{}{
+				"test": map[string]interface{}{
+					"key": "nestedval",
+				},
+			},
+			false,
+		},
+		{
+			"MergeMap",
+			map[string]interface{}{
+				"nested": map[string]interface{}{
+					"key1": "val1",
+				},
+			},
+			NewResourceField("nested"),
+			map[string]interface{}{
+				"key2": "val2",
+			},
+			map[string]interface{}{
+				"nested": map[string]interface{}{
+					"key1": "val1",
+					"key2": "val2",
+				},
+			},
+			false,
+		},
+		{
+			"RootMap",
+			map[string]interface{}{
+				"test": "val",
+			},
+			NewResourceField(),
+			map[string]interface{}{
+				"other": "val",
+			},
+			map[string]interface{}{
+				"test":  "val",
+				"other": "val",
+			},
+			false,
+		},
+		{
+			"RootNonMap",
+			map[string]interface{}{},
+			NewResourceField(),
+			"val",
+			nil,
 			true,
 		},
 	}
@@ -174,18 +314,36 @@ func TestResourceFieldSet(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			entry := New()
-			entry.Resource = tc.resources
+			entry.Resource = tc.resource
 			err := entry.Set(tc.field, tc.val)
 			if tc.expectedErr {
 				require.Error(t, err)
 				return
 			}
 
+			require.NoError(t, err)
 			require.Equal(t, tc.expected, entry.Resource)
 		})
 	}
 }
 
+func TestResourceFieldParent(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		field := ResourceField{[]string{"child"}}
+		require.Equal(t, ResourceField{[]string{}}, field.Parent())
+	})
+
+	t.Run("Root", func(t *testing.T) {
+		field := ResourceField{[]string{}}
+		require.Equal(t, ResourceField{[]string{}}, field.Parent())
+	})
+}
+
+func TestResourceFieldChild(t *testing.T) {
+	field := ResourceField{[]string{"parent"}}
+	require.Equal(t, ResourceField{[]string{"parent", "child"}}, field.Child("child"))
+}
+
 func TestResourceFieldString(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -194,12 +352,27 @@ func TestResourceFieldString(t *testing.T) {
 	}{
 		{
 			"Simple",
-			ResourceField{"foo"},
+			ResourceField{[]string{"foo"}},
 			"$resource.foo",
 		},
+		{
+			"Nested",
+			ResourceField{[]string{"foo", "bar"}},
+			"$resource.foo.bar",
+		},
+		{
+			"NestedWithDots",
+			ResourceField{[]string{"foo", "bar.baz"}},
+			"$resource['foo']['bar.baz']",
+		},
+		{
+			"Root",
+			ResourceField{[]string{}},
+			"$resource",
+		},
 		{
 			"Empty",
-			ResourceField{""},
+			ResourceField{[]string{""}},
 			"$resource.",
 		},
 	}
@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v3"
+	"go.uber.org/zap"
+)
+
+// maxMoveSuffix is the number of suffixes tried to find a name for a moved file that is not already taken
+const maxMoveSuffix = 1000
+
+// validateMoveAfterRead checks that the directory that files are moved to exists,
+// and that files moved into it would not match the include patterns and be read again.
+func validateMoveAfterRead(dir string, include []string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("`move_after_read` directory: %s", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("`move_after_read` must be a directory")
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("`move_after_read` directory: %s", err)
+	}
+	for _, pattern := range include {
+		absPattern, err := filepath.Abs(pattern)
+		if err != nil {
+			continue
+		}
+
+		// A file in the directory can match the pattern if the directory matches the directory of the pattern,
+		// or if the pattern ends with a directory wildcard that can match the directory
+		matched, _ := doublestar.PathMatch(filepath.Dir(absPattern), absDir)
+		if !matched && strings.Contains(filepath.Base(absPattern), "**") {
+			matched, _ = doublestar.PathMatch(absPattern, filepath.Join(absDir, "file"))
+		}
+		if matched {
+			return fmt.Errorf("`move_after_read` directory '%s' is matched by the include pattern '%s', so moved files would be read again", dir, pattern)
+		}
+	}
+	return nil
+}
+
+// finishReaders deletes or moves the files that have been read to the end and have not changed
+// for the quiet period. Their readers are closed and forgotten, and the remaining readers are returned.
+func (f *InputOperator) finishReaders(readers []*Reader) []*Reader {
+	now := time.Now()
+	remaining := make([]*Reader, 0, len(readers))
+	for _, reader := range readers {
+		if !reader.finished(now, f.quietPeriod) {
+			remaining = append(remaining, reader)
+			continue
+		}
+
+		// The file must be closed before it can be removed or renamed on some platforms
+		reader.Close()
+		if err := f.finishFile(reader.fileAttributes.Path); err != nil {
+			// The file will be opened again on the next poll, which will retry
+			reader.Errorw("Failed to remove file after reading", zap.Error(err))
+			continue
+		}
+		f.forgetFingerprint(reader.Fingerprint)
+	}
+	return remaining
+}
+
+// finishFile deletes a file, or moves it to the configured directory
+func (f *InputOperator) finishFile(path string) error {
+	if f.deleteAfterRead {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		f.Debugw("Deleted file after reading", "path", path)
+		return nil
+	}
+
+	// Files with the same name may be read from different directories, or written again after
+	// being moved, so a numbered suffix is added to the name until it does not replace a file
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	for i := 0; i < maxMoveSuffix; i++ {
+		dest := filepath.Join(f.moveAfterRead, base)
+		if i > 0 {
+			dest = filepath.Join(f.moveAfterRead, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(base, ext), i, ext))
+		}
+
+		err := moveFile(path, dest)
+		if os.IsExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		f.Debugw("Moved file after reading", "path", path, "destination", dest)
+		return nil
+	}
+	return fmt.Errorf("no unused name for %s in %s", base, f.moveAfterRead)
+}
+
+// moveFile moves a file without replacing an existing file at the destination.
+// A hard link is used so that the check and the move are atomic, and if the
+// filesystem does not support hard links, the file is renamed after checking.
+func moveFile(path, dest string) error {
+	err := os.Link(path, dest)
+	if os.IsExist(err) {
+		return err
+	} else if err != nil {
+		if _, statErr := os.Lstat(dest); statErr == nil {
+			return os.ErrExist
+		}
+		return os.Rename(path, dest)
+	}
+
+	if err := os.Remove(path); err != nil {
+		// Leave the file where it was, so that it is moved again on the next poll
+		_ = os.Remove(dest)
+		return err
+	}
+	return nil
+}
+
+// forgetFingerprint removes every known file with a fingerprint, so that its offset is no longer persisted
+func (f *InputOperator) forgetFingerprint(fp *Fingerprint) {
+	knownFiles := f.knownFiles[:0]
+	for _, known := range f.knownFiles {
+		if !fp.StartsWith(known.Fingerprint) {
+			knownFiles = append(knownFiles, known)
+		}
+	}
+	f.knownFiles = knownFiles
+}
+
+// finished returns true if the file has been read to the end, every entry read from it has been
+// delivered, and it has not changed for the quiet period
+func (r *Reader) finished(now time.Time, quietPeriod time.Duration) bool {
+	info, err := r.file.Stat()
+	if err != nil {
+		r.Debugw("Failed to stat file", zap.Error(err))
+		return false
+	}
+
+	// Any change to the file restarts the quiet period
+	if info.Size() != r.idleSize || r.idleSince.IsZero() {
+		r.idleSize = info.Size()
+		r.idleSince = now
+		return false
+	}
+	if now.Sub(r.idleSince) < quietPeriod {
+		return false
+	}
+
+	read := r.Offset >= info.Size()
+	if r.compression != NoCompression {
+		// The offset of a compressed file is in its decompressed content, which has been
+		// fully emitted once the file is no longer read until it grows
+		read = r.readSize == info.Size()
+	}
+	return read && r.checkpoint().Offset == r.Offset
+}
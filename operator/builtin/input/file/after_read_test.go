@@ -0,0 +1,203 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+const testQuietPeriod = 10 * time.Millisecond
+
+// pollAfterQuietPeriod polls once to start the quiet period, then again once it has passed
+func pollAfterQuietPeriod(operator *InputOperator) {
+	operator.poll(context.Background())
+	time.Sleep(2 * testQuietPeriod)
+	operator.poll(context.Background())
+}
+
+func TestDeleteAfterRead(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DeleteAfterRead = true
+		cfg.QuietPeriod = helper.NewDuration(testQuietPeriod)
+	}, nil)
+	persister := testutil.NewMockPersister("test")
+	operator.persister = persister
+	defer operator.Stop()
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\ntestlog2\n")
+
+	operator.poll(context.Background())
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2"})
+	require.FileExists(t, temp.Name())
+
+	pollAfterQuietPeriod(operator)
+	require.NoFileExists(t, temp.Name())
+	require.Empty(t, operator.knownFiles)
+	require.Empty(t, operator.lastPollReaders)
+
+	// The offsets of the deleted file are no longer persisted
+	require.NoError(t, operator.loadLastPollFiles(context.Background()))
+	require.Empty(t, operator.knownFiles)
+}
+
+func TestMoveAfterRead(t *testing.T) {
+	t.Parallel()
+	moveDir := testutil.NewTempDir(t)
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.MoveAfterRead = moveDir
+		cfg.QuietPeriod = helper.NewDuration(testQuietPeriod)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+
+	pollAfterQuietPeriod(operator)
+	require.NoFileExists(t, temp.Name())
+	moved, err := ioutil.ReadFile(filepath.Join(moveDir, filepath.Base(temp.Name())))
+	require.NoError(t, err)
+	require.Equal(t, "testlog1\n", string(moved))
+	require.Empty(t, operator.knownFiles)
+}
+
+func TestMoveAfterReadDoesNotReplace(t *testing.T) {
+	t.Parallel()
+	moveDir := testutil.NewTempDir(t)
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.MoveAfterRead = moveDir
+		cfg.QuietPeriod = helper.NewDuration(testQuietPeriod)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	path := filepath.Join(tempDir, "app.log")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(moveDir, "app.log"), []byte("existing\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(moveDir, "app-1.log"), []byte("existing\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(path, []byte("testlog1\n"), 0600))
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+
+	pollAfterQuietPeriod(operator)
+	require.NoFileExists(t, path)
+
+	for name, expected := range map[string]string{
+		"app.log":   "existing\n",
+		"app-1.log": "existing\n",
+		"app-2.log": "testlog1\n",
+	} {
+		contents, err := ioutil.ReadFile(filepath.Join(moveDir, name))
+		require.NoError(t, err)
+		require.Equal(t, expected, string(contents), name)
+	}
+}
+
+func TestValidateMoveAfterRead(t *testing.T) {
+	dir := testutil.NewTempDir(t)
+	file := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(file, nil, 0600))
+	moveDir := filepath.Join(dir, "done")
+	require.NoError(t, os.Mkdir(moveDir, 0700))
+
+	cases := []struct {
+		name     string
+		dir      string
+		include  string
+		expected bool
+	}{
+		{"Valid", moveDir, filepath.Join(dir, "*.log"), true},
+		{"Missing", filepath.Join(dir, "missing"), filepath.Join(dir, "*.log"), false},
+		{"NotDirectory", file, filepath.Join(dir, "*.log"), false},
+		{"MatchedDirectory", moveDir, filepath.Join(moveDir, "*.log"), false},
+		{"MatchedByWildcard", moveDir, filepath.Join(dir, "*", "*.log"), false},
+		{"MatchedByRecursiveWildcard", moveDir, filepath.Join(dir, "**", "*.log"), false},
+		{"MatchedByTrailingWildcard", moveDir, filepath.Join(dir, "**"), false},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateMoveAfterRead(tc.dir, []string{tc.include})
+			if tc.expected {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestQuietPeriodRestartsOnWrite(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DeleteAfterRead = true
+		cfg.QuietPeriod = helper.NewDuration(time.Hour)
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+
+	// Pretend the quiet period has passed, then write to the file before the next poll
+	operator.lastPollReaders[0].idleSince = time.Now().Add(-2 * time.Hour)
+	writeString(t, temp, "testlog2\n")
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog2")
+	require.FileExists(t, temp.Name())
+
+	operator.poll(context.Background())
+	require.FileExists(t, temp.Name())
+}
+
+func TestDeleteAfterReadWaitsForAck(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DeleteAfterRead = true
+		cfg.QuietPeriod = helper.NewDuration(testQuietPeriod)
+		cfg.WaitForAck = true
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	// The fake output never acknowledges entries, so the file is never deleted
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+
+	pollAfterQuietPeriod(operator)
+	require.FileExists(t, temp.Name())
+	require.NotEmpty(t, operator.knownFiles)
+}
@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// NoCompression reads files as they are.
+	NoCompression = "none"
+	// AutoCompression detects the compression of each file from its extension and magic bytes.
+	AutoCompression = "auto"
+	// GzipCompression reads every file as gzip.
+	GzipCompression = "gzip"
+	// ZstdCompression reads every file as zstd.
+	ZstdCompression = "zstd"
+	// Bzip2Compression reads every file as bzip2.
+	Bzip2Compression = "bzip2"
+)
+
+const defaultMaxDecompressedSize = 1024 * 1024 * 1024
+
+// errDecompressedSizeLimit is returned when reading past the maximum decompressed size of a file
+var errDecompressedSizeLimit = errors.New("decompressed content is larger than max_decompressed_size")
+
+var compressionMagic = map[string][]byte{
+	GzipCompression:  {0x1f, 0x8b},
+	ZstdCompression:  {0x28, 0xb5, 0x2f, 0xfd},
+	Bzip2Compression: []byte("BZh"),
+}
+
+var compressionExtensions = map[string]string{
+	".gz":   GzipCompression,
+	".gzip": GzipCompression,
+	".zst":  ZstdCompression,
+	".zstd": ZstdCompression,
+	".bz2":  Bzip2Compression,
+}
+
+func validateCompression(compression string) error {
+	switch compression {
+	case NoCompression, AutoCompression, GzipCompression, ZstdCompression, Bzip2Compression:
+		return nil
+	default:
+		return fmt.Errorf("invalid compression '%s'", compression)
+	}
+}
+
+// detectCompression returns the compression of a file. In auto mode, a file is only treated as
+// compressed if the extension of its name and the magic bytes at its start name the same compression,
+// so that a plain file which happens to start with magic bytes is not decompressed.
+func (f *InputOperator) detectCompression(file *os.File) string {
+	if f.compression != AutoCompression {
+		return f.compression
+	}
+
+	compression, ok := compressionExtensions[strings.ToLower(filepath.Ext(file.Name()))]
+	if !ok {
+		return NoCompression
+	}
+
+	magic := compressionMagic[compression]
+	buf := make([]byte, len(magic))
+	n, _ := file.ReadAt(buf, 0)
+	// A file that is too short to contain magic bytes may still be being written by the compressor
+	if !bytes.HasPrefix(magic, buf[:n]) {
+		return NoCompression
+	}
+	return compression
+}
+
+// newDecompressor returns a reader of the decompressed content of r
+func newDecompressor(compression string, r io.Reader) (io.ReadCloser, error) {
+	switch compression {
+	case GzipCompression:
+		return gzip.NewReader(r)
+	case ZstdCompression:
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	case Bzip2Compression:
+		return ioutil.NopCloser(bzip2.NewReader(r)), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression '%s'", compression)
+	}
+}
+
+// decompressedReader reads the decompressed content of a file, starting at an offset in the
+// decompressed content. A stream that ends early is treated as the end of the file, because
+// the file may still be being written by the compressor.
+type decompressedReader struct {
+	io.ReadCloser
+	// offset is the number of decompressed bytes read
+	offset int64
+	// limit is the number of decompressed bytes that may be read, after which errDecompressedSizeLimit is returned
+	limit    int64
+	exceeded bool
+}
+
+func newDecompressedReader(compression string, file *os.File, offset, limit int64) (*decompressedReader, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seek: %s", err)
+	}
+	rc, err := newDecompressor(compression, file)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// The header has not been written yet
+		return &decompressedReader{ReadCloser: ioutil.NopCloser(bytes.NewReader(nil)), limit: limit}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("open %s stream: %s", compression, err)
+	}
+
+	d := &decompressedReader{ReadCloser: rc, limit: limit}
+	if _, err := io.CopyN(ioutil.Discard, d, offset); err != nil && err != io.EOF {
+		_ = d.Close()
+		return nil, fmt.Errorf("skip to offset: %s", err)
+	}
+	return d, nil
+}
+
+func (d *decompressedReader) Read(p []byte) (int, error) {
+	if d.offset >= d.limit {
+		d.exceeded = true
+		return 0, errDecompressedSizeLimit
+	}
+	if remaining := d.limit - d.offset; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := d.ReadCloser.Read(p)
+	d.offset += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// decompressedSize returns the size of the decompressed content of a file, up to the limit
+func decompressedSize(compression string, file *os.File, limit int64) (int64, error) {
+	d, err := newDecompressedReader(compression, file, 0, limit)
+	if err != nil {
+		return 0, err
+	}
+	defer d.Close()
+
+	n, err := io.Copy(ioutil.Discard, d)
+	if err == errDecompressedSizeLimit {
+		// Nothing past the limit can be read, so it is the end of the file
+		return n, nil
+	}
+	return n, err
+}
@@ -0,0 +1,251 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func gzipBytes(t testing.TB, s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func zstdBytes(t testing.TB, s string) []byte {
+	var buf bytes.Buffer
+	w, err := zstd.NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// bzip2Bytes returns a bzip2 stream of "testlog1\ntestlog2\n", since the standard library cannot compress bzip2
+func bzip2Bytes(t testing.TB) []byte {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "compressed.log.bz2"))
+	require.NoError(t, err)
+	return b
+}
+
+func writeFile(t testing.TB, path string, b []byte) {
+	require.NoError(t, ioutil.WriteFile(path, b, 0600))
+}
+
+func TestDetectCompression(t *testing.T) {
+	cases := []struct {
+		name        string
+		compression string
+		fileName    string
+		content     func(t testing.TB) []byte
+		expected    string
+	}{
+		{"Gzip", AutoCompression, "log.gz", func(t testing.TB) []byte { return gzipBytes(t, "testlog\n") }, GzipCompression},
+		{"Zstd", AutoCompression, "log.zst", func(t testing.TB) []byte { return zstdBytes(t, "testlog\n") }, ZstdCompression},
+		{"Bzip2", AutoCompression, "log.bz2", bzip2Bytes, Bzip2Compression},
+		{"Plain", AutoCompression, "log.gz", func(t testing.TB) []byte { return []byte("testlog\n") }, NoCompression},
+		{"MagicWithoutExtension", AutoCompression, "log", func(t testing.TB) []byte { return gzipBytes(t, "testlog\n") }, NoCompression},
+		{"PlainStartingWithMagic", AutoCompression, "log", func(t testing.TB) []byte { return []byte("BZh testlog\n") }, NoCompression},
+		{"MismatchedMagic", AutoCompression, "log.gz", func(t testing.TB) []byte { return zstdBytes(t, "testlog\n") }, NoCompression},
+		{"ShortGzipExtension", AutoCompression, "log.gz", func(t testing.TB) []byte { return []byte{0x1f} }, GzipCompression},
+		{"ShortZstdExtension", AutoCompression, "log.ZST", func(t testing.TB) []byte { return []byte{0x28, 0xb5} }, ZstdCompression},
+		{"ShortPlain", AutoCompression, "log", func(t testing.TB) []byte { return []byte("a") }, NoCompression},
+		{"Configured", GzipCompression, "log", func(t testing.TB) []byte { return []byte("testlog\n") }, GzipCompression},
+		{"None", NoCompression, "log.gz", func(t testing.TB) []byte { return gzipBytes(t, "testlog\n") }, NoCompression},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			operator, _, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+				cfg.Compression = tc.compression
+			}, nil)
+
+			path := filepath.Join(tempDir, tc.fileName)
+			writeFile(t, path, tc.content(t))
+			file := openFile(t, path)
+
+			require.Equal(t, tc.expected, operator.detectCompression(file))
+		})
+	}
+}
+
+func TestReadCompressed(t *testing.T) {
+	cases := []struct {
+		name      string
+		extension string
+		content   func(t testing.TB) []byte
+	}{
+		{"Gzip", ".gz", func(t testing.TB) []byte { return gzipBytes(t, "testlog1\ntestlog2\n") }},
+		{"Zstd", ".zst", func(t testing.TB) []byte { return zstdBytes(t, "testlog1\ntestlog2\n") }},
+		{"Bzip2", ".bz2", bzip2Bytes},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+				cfg.Compression = AutoCompression
+			}, nil)
+
+			writeFile(t, filepath.Join(tempDir, "app.log.1"+tc.extension), tc.content(t))
+
+			require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+			defer operator.Stop()
+
+			waitForMessages(t, logReceived, []string{"testlog1", "testlog2"})
+		})
+	}
+}
+
+func TestCompressedFingerprint(t *testing.T) {
+	t.Parallel()
+	operator, _, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = AutoCompression
+	}, nil)
+
+	content := stringWithLength(2*defaultFingerprintSize) + "\n"
+	plainPath := filepath.Join(tempDir, "app.log")
+	writeFile(t, plainPath, []byte(content))
+	compressedPath := filepath.Join(tempDir, "app.log.1.gz")
+	writeFile(t, compressedPath, gzipBytes(t, content))
+
+	plain, err := operator.NewFingerprint(openFile(t, plainPath))
+	require.NoError(t, err)
+	compressed, err := operator.NewFingerprint(openFile(t, compressedPath))
+	require.NoError(t, err)
+
+	require.Len(t, compressed.FirstBytes, defaultFingerprintSize)
+	require.Equal(t, plain, compressed)
+}
+
+// TestRotatedThenCompressed tests that a file that is rotated and compressed is recognized by its
+// fingerprint, so that only the entries written after it was last read are emitted
+func TestRotatedThenCompressed(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = AutoCompression
+	}, nil)
+
+	path := filepath.Join(tempDir, "app.log")
+	file := openFile(t, path)
+	writeString(t, file, "testlog1\ntestlog2\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2"})
+
+	// Write a final entry, then compress the file and remove the original, as logrotate would
+	writeString(t, file, "testlog3\n")
+	writeFile(t, path+".1.gz", gzipBytes(t, "testlog1\ntestlog2\ntestlog3\n"))
+	require.NoError(t, file.Close())
+	require.NoError(t, os.Remove(path))
+
+	waitForMessages(t, logReceived, []string{"testlog3"})
+}
+
+func TestCompressedStartAtEnd(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = AutoCompression
+		cfg.StartAt = "end"
+	}, nil)
+
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	path := filepath.Join(tempDir, "app.log.gz")
+	writeFile(t, path, gzipBytes(t, "testlog1\n"))
+
+	// Expect no entries on the first poll
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	// A gzip file may be made of several streams, so appending a stream appends decompressed content
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = file.Write(gzipBytes(t, "testlog2\n"))
+	require.NoError(t, err)
+
+	// The file is not read while it is growing
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog2")
+	expectNoMessages(t, logReceived)
+}
+
+func TestCompressedPartialStream(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = AutoCompression
+	}, nil)
+
+	// The compressor has not finished writing the file, so only the complete entries are read
+	content := gzipBytes(t, "testlog1\n"+stringWithLength(4096)+"\ntestlog3\n")
+	path := filepath.Join(tempDir, "app.log.gz")
+	writeFile(t, path, content[:len(content)/2])
+
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	// The file is only read once its size is the same on two polls
+	operator.poll(context.Background())
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+	expectNoMessages(t, logReceived)
+
+	writeFile(t, path, content)
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+	operator.poll(context.Background())
+	require.Len(t, waitForN(t, logReceived, 2)[0], 4096)
+}
+
+func TestCompressedMaxDecompressedSize(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Compression = AutoCompression
+		cfg.MaxDecompressedSize = 9
+	}, nil)
+
+	writeFile(t, filepath.Join(tempDir, "app.log.gz"), gzipBytes(t, "testlog1\ntestlog2\n"))
+
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	operator.poll(context.Background())
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+	expectNoMessages(t, logReceived)
+}
@@ -32,6 +32,15 @@ func init() {
 const (
 	defaultMaxLogSize         = 1024 * 1024
 	defaultMaxConcurrentFiles = 1024
+	defaultResyncInterval     = time.Minute
+	defaultQuietPeriod        = time.Minute
+)
+
+const (
+	// PollDiscovery finds files by rescanning the include patterns every poll interval.
+	PollDiscovery = "poll"
+	// NotifyDiscovery finds files when the file system reports changes to them, and rescans every resync interval.
+	NotifyDiscovery = "notify"
 )
 
 // NewInputConfig creates a new input config with default values
@@ -58,6 +67,8 @@ type InputConfig struct {
 	Finder             `mapstructure:",squash" yaml:",inline"`
 
 	PollInterval            helper.Duration       `mapstructure:"poll_interval,omitempty"                  json:"poll_interval,omitempty"                 yaml:"poll_interval,omitempty"`
+	DiscoveryMode           string                `mapstructure:"discovery_mode,omitempty"                 json:"discovery_mode,omitempty"                yaml:"discovery_mode,omitempty"`
+	ResyncInterval          helper.Duration       `mapstructure:"resync_interval,omitempty"                json:"resync_interval,omitempty"               yaml:"resync_interval,omitempty"`
 	IncludeFileName         bool                  `mapstructure:"include_file_name,omitempty"              json:"include_file_name,omitempty"             yaml:"include_file_name,omitempty"`
 	IncludeFilePath         bool                  `mapstructure:"include_file_path,omitempty"              json:"include_file_path,omitempty"             yaml:"include_file_path,omitempty"`
 	IncludeFileNameResolved bool                  `mapstructure:"include_file_name_resolved,omitempty"     json:"include_file_name_resolved,omitempty"    yaml:"include_file_name_resolved,omitempty"`
@@ -68,6 +79,13 @@ type InputConfig struct {
 	MaxConcurrentFiles      int                   `mapstructure:"max_concurrent_files,omitempty"           json:"max_concurrent_files,omitempty"          yaml:"max_concurrent_files,omitempty"`
 	Encoding                helper.EncodingConfig `mapstructure:",squash,omitempty"                        json:",inline,omitempty"                       yaml:",inline,omitempty"`
 	Splitter                helper.SplitterConfig `mapstructure:",squash,omitempty"                        json:",inline,omitempty"                       yaml:",inline,omitempty"`
+	WaitForAck              bool                  `mapstructure:"wait_for_ack,omitempty"                   json:"wait_for_ack,omitempty"                  yaml:"wait_for_ack,omitempty"`
+	Compression             string                `mapstructure:"compression,omitempty"                    json:"compression,omitempty"                   yaml:"compression,omitempty"`
+	MaxDecompressedSize     helper.ByteSize       `mapstructure:"max_decompressed_size,omitempty"          json:"max_decompressed_size,omitempty"         yaml:"max_decompressed_size,omitempty"`
+	DeleteAfterRead         bool                  `mapstructure:"delete_after_read,omitempty"              json:"delete_after_read,omitempty"             yaml:"delete_after_read,omitempty"`
+	MoveAfterRead           string                `mapstructure:"move_after_read,omitempty"                json:"move_after_read,omitempty"               yaml:"move_after_read,omitempty"`
+	QuietPeriod             helper.Duration       `mapstructure:"quiet_period,omitempty"                   json:"quiet_period,omitempty"                  yaml:"quiet_period,omitempty"`
+	Header                  *HeaderConfig         `mapstructure:"header,omitempty"                         json:"header,omitempty"                        yaml:"header,omitempty"`
 }
 
 // Build will build a file input operator from the supplied configuration
@@ -97,6 +115,18 @@ func (c InputConfig) Build(context operator.BuildContext) ([]operator.Operator,
 		}
 	}
 
+	if c.ExcludeOlderThan.Raw() < 0 {
+		return nil, fmt.Errorf("`exclude_older_than` must not be negative")
+	}
+	if c.MaxFileSize < 0 {
+		return nil, fmt.Errorf("`max_file_size` must not be negative")
+	}
+
+	sorter, err := c.OrderingCriteria.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid ordering_criteria: %s", err)
+	}
+
 	if c.MaxLogSize <= 0 {
 		return nil, fmt.Errorf("`max_log_size` must be positive")
 	}
@@ -122,6 +152,32 @@ func (c InputConfig) Build(context operator.BuildContext) ([]operator.Operator,
 		return nil, err
 	}
 
+	switch c.DiscoveryMode {
+	case "":
+		c.DiscoveryMode = PollDiscovery
+	case PollDiscovery, NotifyDiscovery:
+	default:
+		return nil, fmt.Errorf("invalid discovery_mode '%s'", c.DiscoveryMode)
+	}
+
+	if c.ResyncInterval.Raw() == 0 {
+		c.ResyncInterval = helper.NewDuration(defaultResyncInterval)
+	} else if c.ResyncInterval.Raw() < 0 {
+		return nil, fmt.Errorf("`resync_interval` must be positive")
+	}
+
+	if c.Compression == "" {
+		c.Compression = NoCompression
+	} else if err := validateCompression(c.Compression); err != nil {
+		return nil, err
+	}
+
+	if c.MaxDecompressedSize == 0 {
+		c.MaxDecompressedSize = defaultMaxDecompressedSize
+	} else if c.MaxDecompressedSize < 0 {
+		return nil, fmt.Errorf("`max_decompressed_size` must be positive")
+	}
+
 	var startAtBeginning bool
 	switch c.StartAt {
 	case "beginning":
@@ -132,6 +188,35 @@ func (c InputConfig) Build(context operator.BuildContext) ([]operator.Operator,
 		return nil, fmt.Errorf("invalid start_at location '%s'", c.StartAt)
 	}
 
+	if c.DeleteAfterRead && c.MoveAfterRead != "" {
+		return nil, fmt.Errorf("only one of `delete_after_read` and `move_after_read` can be set")
+	}
+	if (c.DeleteAfterRead || c.MoveAfterRead != "") && !startAtBeginning {
+		return nil, fmt.Errorf("`start_at` must be `beginning` when files are deleted or moved after they are read")
+	}
+	if c.MoveAfterRead != "" {
+		if err := validateMoveAfterRead(c.MoveAfterRead, c.Include); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.QuietPeriod.Raw() == 0 {
+		c.QuietPeriod = helper.NewDuration(defaultQuietPeriod)
+	} else if c.QuietPeriod.Raw() < 0 {
+		return nil, fmt.Errorf("`quiet_period` must be positive")
+	}
+
+	var header *headerParser
+	if c.Header != nil {
+		if !startAtBeginning {
+			return nil, fmt.Errorf("`start_at` must be `beginning` when `header` is set")
+		}
+		header, err = c.Header.Build(context.WithSubNamespace(c.ID()))
+		if err != nil {
+			return nil, fmt.Errorf("invalid header: %s", err)
+		}
+	}
+
 	fileNameField := entry.NewNilField()
 	if c.IncludeFileName {
 		fileNameField = entry.NewAttributeField("file.name")
@@ -155,7 +240,10 @@ func (c InputConfig) Build(context operator.BuildContext) ([]operator.Operator,
 	op := &InputOperator{
 		InputOperator:         inputOperator,
 		finder:                c.Finder,
+		sorter:                sorter,
 		PollInterval:          c.PollInterval.Raw(),
+		discoveryMode:         c.DiscoveryMode,
+		resyncInterval:        c.ResyncInterval.Raw(),
 		FilePathField:         filePathField,
 		FileNameField:         fileNameField,
 		FilePathResolvedField: filePathResolvedField,
@@ -171,6 +259,13 @@ func (c InputConfig) Build(context operator.BuildContext) ([]operator.Operator,
 		MaxLogSize:            int(c.MaxLogSize),
 		MaxConcurrentFiles:    c.MaxConcurrentFiles,
 		SeenPaths:             make(map[string]struct{}, 100),
+		waitForAck:            c.WaitForAck,
+		compression:           c.Compression,
+		maxDecompressedSize:   int64(c.MaxDecompressedSize),
+		deleteAfterRead:       c.DeleteAfterRead,
+		moveAfterRead:         c.MoveAfterRead,
+		quietPeriod:           c.QuietPeriod.Raw(),
+		header:                header,
 	}
 
 	return []operator.Operator{op}, nil
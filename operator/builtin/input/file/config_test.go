@@ -23,6 +23,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/parser/regex"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
 	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
@@ -496,6 +497,105 @@ func TestUnmarshal(t *testing.T) {
 				return cfg
 			}(),
 		},
+		{
+			Name:      "wait_for_ack",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.WaitForAck = true
+				return cfg
+			}(),
+		},
+		{
+			Name:      "discovery_mode_notify",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.DiscoveryMode = NotifyDiscovery
+				cfg.ResyncInterval = helper.NewDuration(30 * time.Second)
+				return cfg
+			}(),
+		},
+		{
+			Name:      "compression_auto",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.Compression = AutoCompression
+				return cfg
+			}(),
+		},
+		{
+			Name:      "max_decompressed_size",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.Compression = AutoCompression
+				cfg.MaxDecompressedSize = 10 * 1024 * 1024
+				return cfg
+			}(),
+		},
+		{
+			Name:      "delete_after_read",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.DeleteAfterRead = true
+				cfg.QuietPeriod = helper.NewDuration(30 * time.Second)
+				return cfg
+			}(),
+		},
+		{
+			Name:      "move_after_read",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.MoveAfterRead = "/var/log/done"
+				return cfg
+			}(),
+		},
+		{
+			Name:      "ordering_criteria",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.OrderingCriteria = OrderingCriteria{
+					Regex: `app-(?P<date>\d{8})\.log`,
+					TopN:  2,
+					SortBy: []SortRule{
+						{SortType: SortTimestamp, RegexKey: "date", Layout: "%Y%m%d", Location: "UTC"},
+						{SortType: SortMtime, Ascending: true},
+					},
+				}
+				return cfg
+			}(),
+		},
+		{
+			Name:      "exclude_older_than",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.ExcludeOlderThan = helper.NewDuration(24 * time.Hour)
+				cfg.MaxFileSize = 10 * 1024 * 1024
+				return cfg
+			}(),
+		},
+		{
+			Name:      "header",
+			ExpectErr: false,
+			Expect: func() *InputConfig {
+				cfg := defaultCfg()
+				cfg.StartAt = "beginning"
+				parser := regex.NewRegexParserConfig("")
+				parser.Regex = "^#Fields: (?P<fields>.*)$"
+				parser.ParseTo = entry.NewAttributeField()
+				cfg.Header = &HeaderConfig{
+					Pattern:           "^#",
+					MetadataOperators: []operator.Config{{Builder: parser}},
+				}
+				return cfg
+			}(),
+		},
 		{
 			Name:      "encoding_upper",
 			ExpectErr: false,
@@ -595,6 +695,167 @@ func TestBuild(t *testing.T) {
 			require.NoError,
 			func(t *testing.T, f *InputOperator) {},
 		},
+		{
+			"NotifyDiscovery",
+			func(f *InputConfig) {
+				f.DiscoveryMode = NotifyDiscovery
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.Equal(t, NotifyDiscovery, f.discoveryMode)
+				require.Equal(t, time.Minute, f.resyncInterval)
+			},
+		},
+		{
+			"DefaultCompression",
+			func(f *InputConfig) {},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.Equal(t, NoCompression, f.compression)
+			},
+		},
+		{
+			"GzipCompression",
+			func(f *InputConfig) {
+				f.Compression = GzipCompression
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.Equal(t, GzipCompression, f.compression)
+			},
+		},
+		{
+			"InvalidCompression",
+			func(f *InputConfig) {
+				f.Compression = "lz4"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"InvalidMaxDecompressedSize",
+			func(f *InputConfig) {
+				f.MaxDecompressedSize = -1
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"DeleteAfterRead",
+			func(f *InputConfig) {
+				f.DeleteAfterRead = true
+				f.StartAt = "beginning"
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.True(t, f.deleteAfterRead)
+				require.Equal(t, time.Minute, f.quietPeriod)
+			},
+		},
+		{
+			"DeleteAndMoveAfterRead",
+			func(f *InputConfig) {
+				f.DeleteAfterRead = true
+				f.MoveAfterRead = "/var/log/done"
+				f.StartAt = "beginning"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"MoveAfterReadStartAtEnd",
+			func(f *InputConfig) {
+				f.MoveAfterRead = "/var/log/done"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"NegativeQuietPeriod",
+			func(f *InputConfig) {
+				f.DeleteAfterRead = true
+				f.StartAt = "beginning"
+				f.QuietPeriod = helper.NewDuration(-time.Second)
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"OrderingCriteria",
+			func(f *InputConfig) {
+				f.OrderingCriteria = OrderingCriteria{
+					TopN:   1,
+					SortBy: []SortRule{{SortType: SortMtime}},
+				}
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.NotNil(t, f.sorter)
+				require.Equal(t, 1, f.sorter.topN)
+			},
+		},
+		{
+			"InvalidOrderingCriteria",
+			func(f *InputConfig) {
+				f.OrderingCriteria = OrderingCriteria{
+					SortBy: []SortRule{{SortType: SortNumeric, RegexKey: "num"}},
+				}
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"NegativeExcludeOlderThan",
+			func(f *InputConfig) {
+				f.ExcludeOlderThan = helper.NewDuration(-time.Hour)
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"NegativeMaxFileSize",
+			func(f *InputConfig) {
+				f.MaxFileSize = -1
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"Header",
+			func(f *InputConfig) {
+				f.StartAt = "beginning"
+				f.Header = newW3CHeaderConfig()
+			},
+			require.NoError,
+			func(t *testing.T, f *InputOperator) {
+				require.NotNil(t, f.header)
+			},
+		},
+		{
+			"HeaderStartAtEnd",
+			func(f *InputConfig) {
+				f.Header = newW3CHeaderConfig()
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"InvalidDiscoveryMode",
+			func(f *InputConfig) {
+				f.DiscoveryMode = "invalid"
+			},
+			require.Error,
+			nil,
+		},
+		{
+			"NegativeResyncInterval",
+			func(f *InputConfig) {
+				f.DiscoveryMode = NotifyDiscovery
+				f.ResyncInterval = helper.NewDuration(-time.Second)
+			},
+			require.Error,
+			nil,
+		},
 		{
 			"InvalidEncoding",
 			func(f *InputConfig) {
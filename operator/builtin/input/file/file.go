@@ -35,6 +35,7 @@ type InputOperator struct {
 	helper.InputOperator
 
 	finder                Finder
+	sorter                *fileSorter
 	FilePathField         entry.Field
 	FileNameField         entry.Field
 	FilePathResolvedField entry.Field
@@ -45,6 +46,9 @@ type InputOperator struct {
 	MaxConcurrentFiles    int
 	SeenPaths             map[string]struct{}
 
+	// skippedPaths are the paths skipped because of their age or size, with the reason they were skipped
+	skippedPaths map[string]string
+
 	persister operator.Persister
 
 	knownFiles      []*Reader
@@ -53,14 +57,29 @@ type InputOperator struct {
 	lastPollReaders []*Reader
 
 	startAtBeginning bool
+	waitForAck       bool
+
+	discoveryMode  string
+	resyncInterval time.Duration
+
+	fingerprintSize     int
+	compression         string
+	maxDecompressedSize int64
+
+	deleteAfterRead bool
+	moveAfterRead   string
+	quietPeriod     time.Duration
 
-	fingerprintSize int
+	header *headerParser
 
 	encoding helper.Encoding
 
 	wg         sync.WaitGroup
 	firstCheck bool
 	cancel     context.CancelFunc
+
+	statsMux sync.Mutex
+	stats    pollStats
 }
 
 // Start will start the file monitoring process
@@ -75,6 +94,13 @@ func (f *InputOperator) Start(persister operator.Persister) error {
 		return fmt.Errorf("read known files from database: %s", err)
 	}
 
+	if f.header != nil {
+		if err := f.header.Start(persister); err != nil {
+			cancel()
+			return err
+		}
+	}
+
 	// Start polling goroutine
 	f.startPoller(ctx)
 
@@ -93,30 +119,86 @@ func (f *InputOperator) Stop() error {
 	}
 	f.knownFiles = nil
 	f.cancel = nil
+	if f.header != nil {
+		f.header.Stop()
+	}
 	return nil
 }
 
 // startPoller kicks off a goroutine that will poll the filesystem periodically,
-// checking if there are new files or new logs in the watched files
+// checking if there are new files or new logs in the watched files.
+// In notify mode, the filesystem is also polled whenever it reports a change to the watched files,
+// but polls that scan for files are still at least the poll interval apart.
 func (f *InputOperator) startPoller(ctx context.Context) {
+	interval := f.PollInterval
+	var changes <-chan struct{}
+	if f.discoveryMode == NotifyDiscovery {
+		w, err := newWatcher(f.finder.Include, f.SugaredLogger)
+		if err != nil {
+			f.Warnw("Failed to watch for file changes, falling back to polling", zap.Error(err))
+		} else {
+			interval = f.resyncInterval
+			changes = w.changes
+			f.wg.Add(1)
+			go func() {
+				defer f.wg.Done()
+				w.run(ctx)
+			}()
+		}
+	}
+
 	f.wg.Add(1)
 	go func() {
 		defer f.wg.Done()
-		globTicker := time.NewTicker(f.PollInterval)
+		globTicker := time.NewTicker(interval)
 		defer globTicker.Stop()
 
+		// In notify mode, files that already exist are found without waiting for a change
+		pollNow := changes != nil
+		var lastPoll time.Time
 		for {
-			select {
-			case <-ctx.Done():
+			if !pollNow {
+				select {
+				case <-ctx.Done():
+					return
+				case <-globTicker.C:
+				case <-changes:
+					// Changes that arrive while waiting are coalesced into the next poll
+					if !f.waitForPollInterval(ctx, lastPoll) {
+						return
+					}
+				}
+			} else if ctx.Err() != nil {
 				return
-			case <-globTicker.C:
 			}
 
+			lastPoll = time.Now()
 			f.poll(ctx)
+
+			// Matches that did not fit in the last batch are consumed without waiting for a change
+			pollNow = changes != nil && len(f.queuedMatches) > 0
 		}
 	}()
 }
 
+// waitForPollInterval waits until the poll interval has passed since the last poll.
+// It returns false if the context was cancelled while waiting.
+func (f *InputOperator) waitForPollInterval(ctx context.Context, lastPoll time.Time) bool {
+	wait := f.PollInterval - time.Since(lastPoll)
+	if wait <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 // poll checks all the watched paths for new entries
 func (f *InputOperator) poll(ctx context.Context) {
 	f.maxBatchFiles = f.MaxConcurrentFiles / 2
@@ -133,8 +215,13 @@ func (f *InputOperator) poll(ctx context.Context) {
 				f.knownFiles[i].generation++
 			}
 
-			// Get the list of paths on disk
-			matches = f.finder.FindFiles()
+			// Get the list of paths on disk, in the order they should be read
+			var skipped map[string]string
+			matches, skipped = f.finder.findFiles(time.Now())
+			f.logSkipped(skipped)
+			if f.sorter != nil {
+				matches = f.sorter.Sort(matches)
+			}
 			if f.firstCheck && len(matches) == 0 {
 				f.Warnw("no files match the configured include patterns",
 					"include", f.finder.Include,
@@ -185,12 +272,26 @@ OUTER:
 		reader.Close()
 	}
 
+	if f.deleteAfterRead || f.moveAfterRead != "" {
+		readers = f.finishReaders(readers)
+	}
 	f.lastPollReaders = readers
 
 	f.saveCurrent(readers)
+	f.recordStats(readers)
 	f.syncLastPollFiles(ctx)
 }
 
+// logSkipped logs the paths skipped because of their age or size, once for as long as they are skipped
+func (f *InputOperator) logSkipped(skipped map[string]string) {
+	for path, reason := range skipped {
+		if f.skippedPaths[path] != reason {
+			f.Infow("Skipping file", "path", path, "reason", reason)
+		}
+	}
+	f.skippedPaths = skipped
+}
+
 // makeReaders takes a list of paths, then creates readers from each of those paths,
 // discarding any that have a duplicate fingerprint to other files that have already
 // been read this polling interval
@@ -336,7 +437,7 @@ func (f *InputOperator) syncLastPollFiles(ctx context.Context) {
 
 	// Encode each known file
 	for _, fileReader := range f.knownFiles {
-		if err := enc.Encode(fileReader); err != nil {
+		if err := enc.Encode(fileReader.checkpoint()); err != nil {
 			f.Errorw("Failed to encode known files", zap.Error(err))
 		}
 	}
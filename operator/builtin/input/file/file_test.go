@@ -25,9 +25,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
 )
@@ -667,6 +672,108 @@ func TestOffsetsAfterRestart_BigFilesWrittenWhileOff(t *testing.T) {
 	waitForMessage(t, logReceived, log2)
 }
 
+func TestWaitForAckRereadsUnacknowledged(t *testing.T) {
+	t.Parallel()
+	cfgMod := func(cfg *InputConfig) { cfg.WaitForAck = true }
+	operator, logReceived, tempDir := newTestFileOperator(t, cfgMod, nil)
+	persister := testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	// The fake output never acknowledges entries
+	require.NoError(t, operator.Start(persister))
+	defer operator.Stop()
+	waitForMessage(t, logReceived, "testlog1")
+
+	// Restart the operator and expect the unacknowledged entry again
+	require.NoError(t, operator.Stop())
+	writeString(t, temp, "testlog2\n")
+	require.NoError(t, operator.Start(persister))
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2"})
+}
+
+func TestWaitForAckCommitsAcknowledged(t *testing.T) {
+	t.Parallel()
+	tempDir := testutil.NewTempDir(t)
+	cfg := newDefaultConfig(tempDir)
+	cfg.WaitForAck = true
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*InputOperator)
+
+	logReceived := make(chan *entry.Entry, 10)
+	acking := &testutil.Operator{}
+	acking.On("ID").Return("$.fake")
+	acking.On("CanProcess").Return(true)
+	acking.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		helper.Ack(args.Get(0).(context.Context))
+		logReceived <- args.Get(1).(*entry.Entry)
+	}).Return(nil)
+	require.NoError(t, op.SetOutputs([]operator.Operator{acking}))
+	persister := testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	require.NoError(t, op.Start(persister))
+	defer op.Stop()
+	waitForMessage(t, logReceived, "testlog1")
+
+	// Restart the operator and expect only the new entry
+	require.NoError(t, op.Stop())
+	writeString(t, temp, "testlog2\n")
+	require.NoError(t, op.Start(persister))
+	waitForMessage(t, logReceived, "testlog2")
+	expectNoMessages(t, logReceived)
+}
+
+func TestWaitForAckRedeliversAfterNack(t *testing.T) {
+	t.Parallel()
+	tempDir := testutil.NewTempDir(t)
+	cfg := newDefaultConfig(tempDir)
+	cfg.WaitForAck = true
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*InputOperator)
+
+	// The output fails to deliver the first copy of testlog2, and delivers every other entry
+	logReceived := make(chan *entry.Entry, 10)
+	failed := false
+	acking := &testutil.Operator{}
+	acking.On("ID").Return("$.fake")
+	acking.On("CanProcess").Return(true)
+	acking.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ctx, e := args.Get(0).(context.Context), args.Get(1).(*entry.Entry)
+		if e.Body == "testlog2" && !failed {
+			failed = true
+			helper.Nack(ctx)
+		} else {
+			helper.Ack(ctx)
+		}
+		logReceived <- e
+	}).Return(nil)
+	require.NoError(t, op.SetOutputs([]operator.Operator{acking}))
+	persister := testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\ntestlog2\ntestlog3\n")
+
+	require.NoError(t, op.Start(persister))
+	defer op.Stop()
+
+	// Reading stops at the failed entry, which is read again on the next poll along with the rest
+	waitForMessages(t, logReceived, []string{"testlog1", "testlog2", "testlog2", "testlog3"})
+	expectNoMessages(t, logReceived)
+
+	// Restart the operator and expect only the new entry
+	require.NoError(t, op.Stop())
+	writeString(t, temp, "testlog4\n")
+	require.NoError(t, op.Start(persister))
+	waitForMessage(t, logReceived, "testlog4")
+	expectNoMessages(t, logReceived)
+}
+
 func TestManyLogsDelivered(t *testing.T) {
 	t.Parallel()
 	operator, logReceived, tempDir := newTestFileOperator(t, nil, nil)
@@ -929,3 +1036,71 @@ func TestEncodings(t *testing.T) {
 		})
 	}
 }
+
+func TestFileMetrics(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, nil, nil)
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\ntestlog2\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+
+	waitForMessage(t, logReceived, "testlog1")
+	waitForMessage(t, logReceived, "testlog2")
+
+	fileMetrics := func() map[string]float64 {
+		values := make(map[string]float64)
+		for _, metric := range operator.Metrics() {
+			if metric.Labels["path"] == temp.Name() || metric.Name == MetricTrackedFiles {
+				values[metric.Name] = metric.Value
+			}
+		}
+		return values
+	}
+
+	require.Eventually(t, func() bool {
+		values := fileMetrics()
+		return values[MetricOffset] == 18 && values[MetricLag] == 0 && values[MetricTrackedFiles] == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	require.NoError(t, operator.Stop())
+
+	// The lag is the unread part of the file, and is never negative if the file was truncated
+	operator.stats.files = []fileStats{{path: temp.Name(), offset: 18, size: 25}}
+	require.Equal(t, 7.0, fileMetrics()[MetricLag])
+	operator.stats.files = []fileStats{{path: temp.Name(), offset: 18, size: 10}}
+	require.Equal(t, 0.0, fileMetrics()[MetricLag])
+}
+
+func TestSkippedFilesLoggedOnce(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.MaxFileSize = 16
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	core, logs := observer.New(zapcore.InfoLevel)
+	operator.SugaredLogger = zap.New(core).Sugar()
+	defer operator.Stop()
+
+	small := openTemp(t, tempDir)
+	writeString(t, small, "testlog1\n")
+	large := openTemp(t, tempDir)
+	writeString(t, large, "this entry is too large\n")
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "testlog1")
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+
+	skipped := logs.FilterMessage("Skipping file").All()
+	require.Len(t, skipped, 1)
+	require.Equal(t, large.Name(), skipped[0].ContextMap()["path"])
+
+	// Once the file is no longer skipped, it is logged again if it is skipped later
+	require.NoError(t, large.Truncate(0))
+	operator.poll(context.Background())
+	_, err := large.WriteAt([]byte("this entry is too large again\n"), 0)
+	require.NoError(t, err)
+	operator.poll(context.Background())
+	require.Len(t, logs.FilterMessage("Skipping file").All(), 2)
+}
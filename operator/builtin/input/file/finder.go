@@ -15,16 +15,63 @@
 package file
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/bmatcuk/doublestar/v3"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 )
 
 type Finder struct {
-	Include []string `mapstructure:"include,omitempty" json:"include,omitempty" yaml:"include,omitempty"`
-	Exclude []string `mapstructure:"exclude,omitempty" json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	Include          []string         `mapstructure:"include,omitempty"            json:"include,omitempty"            yaml:"include,omitempty"`
+	Exclude          []string         `mapstructure:"exclude,omitempty"            json:"exclude,omitempty"            yaml:"exclude,omitempty"`
+	OrderingCriteria OrderingCriteria `mapstructure:"ordering_criteria,omitempty"  json:"ordering_criteria,omitempty"  yaml:"ordering_criteria,omitempty"`
+	ExcludeOlderThan helper.Duration  `mapstructure:"exclude_older_than,omitempty" json:"exclude_older_than,omitempty" yaml:"exclude_older_than,omitempty"`
+	MaxFileSize      helper.ByteSize  `mapstructure:"max_file_size,omitempty"      json:"max_file_size,omitempty"      yaml:"max_file_size,omitempty"`
 }
 
-// FindFiles gets a list of paths given an array of glob patterns to include and exclude
+// FindFiles gets a list of paths given an array of glob patterns to include and exclude.
+// Files that are older or larger than the configured limits are not included.
 func (f Finder) FindFiles() []string {
+	matches, _ := f.findFiles(time.Now())
+	return matches
+}
+
+// findFiles gets a list of paths, along with the reason each matching path was skipped
+// because of its age or size
+func (f Finder) findFiles(now time.Time) ([]string, map[string]string) {
+	matches := f.match()
+	if f.ExcludeOlderThan.Raw() <= 0 && f.MaxFileSize <= 0 {
+		return matches, nil
+	}
+
+	kept := make([]string, 0, len(matches))
+	skipped := make(map[string]string)
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			// The file will fail to open as well, which is reported when it is read
+			kept = append(kept, match)
+			continue
+		}
+
+		if f.ExcludeOlderThan.Raw() > 0 && now.Sub(info.ModTime()) > f.ExcludeOlderThan.Raw() {
+			skipped[match] = fmt.Sprintf("last modified more than %s ago", f.ExcludeOlderThan.Raw())
+			continue
+		}
+		if f.MaxFileSize > 0 && info.Size() > int64(f.MaxFileSize) {
+			skipped[match] = fmt.Sprintf("larger than %d bytes", int64(f.MaxFileSize))
+			continue
+		}
+		kept = append(kept, match)
+	}
+	return kept, skipped
+}
+
+// match gets a list of paths given an array of glob patterns to include and exclude
+func (f Finder) match() []string {
 	all := make([]string, 0, len(f.Include))
 	for _, include := range f.Include {
 		matches, _ := doublestar.Glob(include) // compile error checked in build
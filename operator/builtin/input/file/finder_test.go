@@ -19,9 +19,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
 )
 
@@ -140,7 +142,7 @@ func TestFinder(t *testing.T) {
 				ioutil.WriteFile(f, []byte(filepath.Base(f)), 0755)
 			}
 
-			finder := Finder{include, exclude}
+			finder := Finder{Include: include, Exclude: exclude}
 			require.ElementsMatch(t, finder.FindFiles(), expected)
 		})
 	}
@@ -153,3 +155,54 @@ func absPath(tempDir string, files []string) []string {
 	}
 	return absFiles
 }
+
+func TestFinderAgeAndSize(t *testing.T) {
+	t.Parallel()
+	tempDir := testutil.NewTempDir(t)
+	now := time.Now()
+
+	write := func(name string, size int, age time.Duration) string {
+		path := filepath.Join(tempDir, name)
+		require.NoError(t, ioutil.WriteFile(path, make([]byte, size), 0600))
+		mtime := now.Add(-age)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+		return path
+	}
+	recent := write("recent.log", 10, time.Minute)
+	old := write("old.log", 10, 48*time.Hour)
+	large := write("large.log", 2048, time.Minute)
+
+	cases := []struct {
+		name             string
+		excludeOlderThan time.Duration
+		maxFileSize      helper.ByteSize
+		expected         []string
+		skipped          []string
+	}{
+		{"NoLimits", 0, 0, []string{recent, old, large}, nil},
+		{"ExcludeOlderThan", 24 * time.Hour, 0, []string{recent, large}, []string{old}},
+		{"MaxFileSize", 0, 1024, []string{recent, old}, []string{large}},
+		{"Both", 24 * time.Hour, 1024, []string{recent}, []string{old, large}},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			finder := Finder{
+				Include:          []string{filepath.Join(tempDir, "*")},
+				ExcludeOlderThan: helper.NewDuration(tc.excludeOlderThan),
+				MaxFileSize:      tc.maxFileSize,
+			}
+			matches, skipped := finder.findFiles(now)
+			require.ElementsMatch(t, tc.expected, matches)
+			require.ElementsMatch(t, tc.expected, finder.FindFiles())
+
+			skippedPaths := make([]string, 0, len(skipped))
+			for path, reason := range skipped {
+				require.NotEmpty(t, reason)
+				skippedPaths = append(skippedPaths, path)
+			}
+			require.ElementsMatch(t, tc.skipped, skippedPaths)
+		})
+	}
+}
@@ -31,11 +31,28 @@ type Fingerprint struct {
 	FirstBytes []byte
 }
 
-// NewFingerprint creates a new fingerprint from an open file
+// NewFingerprint creates a new fingerprint from an open file.
+// The fingerprint of a compressed file is taken from its decompressed content,
+// so that it matches the fingerprint of the file before it was compressed.
 func (f *InputOperator) NewFingerprint(file *os.File) (*Fingerprint, error) {
 	buf := make([]byte, f.fingerprintSize)
 
-	n, err := file.ReadAt(buf, 0)
+	var n int
+	var err error
+	if compression := f.detectCompression(file); compression != NoCompression {
+		var d *decompressedReader
+		d, err = newDecompressedReader(compression, file, 0, f.maxDecompressedSize)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing fingerprint bytes: %s", err)
+		}
+		defer d.Close()
+		n, err = io.ReadFull(d, buf)
+		if err == io.ErrUnexpectedEOF || err == errDecompressedSizeLimit {
+			err = io.EOF
+		}
+	} else {
+		n, err = file.ReadAt(buf, 0)
+	}
 	if err != nil && err != io.EOF {
 		return nil, fmt.Errorf("reading fingerprint bytes: %s", err)
 	}
@@ -0,0 +1,160 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+// HeaderConfig is the configuration of the header lines at the start of a file
+type HeaderConfig struct {
+	Pattern           string            `mapstructure:"pattern"            json:"pattern"            yaml:"pattern"`
+	MetadataOperators []operator.Config `mapstructure:"metadata_operators" json:"metadata_operators" yaml:"metadata_operators"`
+}
+
+// Build creates a header parser from the configuration
+func (c HeaderConfig) Build(bc operator.BuildContext) (*headerParser, error) {
+	if c.Pattern == "" {
+		return nil, fmt.Errorf("`pattern` is required")
+	}
+	pattern, err := regexp.Compile(c.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("compiling pattern: %s", err)
+	}
+
+	if len(c.MetadataOperators) == 0 {
+		return nil, fmt.Errorf("at least one of `metadata_operators` is required")
+	}
+
+	operators := make([]operator.Operator, 0, len(c.MetadataOperators))
+	for _, cfg := range c.MetadataOperators {
+		ops, err := cfg.Build(bc)
+		if err != nil {
+			return nil, fmt.Errorf("build metadata operator '%s': %s", cfg.ID(), err)
+		}
+		for _, op := range ops {
+			if !op.CanProcess() || !op.CanOutput() {
+				return nil, fmt.Errorf("metadata operator '%s' must process and output entries", op.ID())
+			}
+			operators = append(operators, op)
+		}
+	}
+
+	captureCfg := helper.NewOutputConfig("header_capture", "header_capture")
+	captureOp, err := captureCfg.Build(bc)
+	if err != nil {
+		return nil, err
+	}
+	capture := &headerCapture{OutputOperator: captureOp}
+
+	// Connect the operators in order, ending with the operator that captures the result
+	for i, op := range operators {
+		next := operator.Operator(capture)
+		if i+1 < len(operators) {
+			next = operators[i+1]
+		}
+		op.SetOutputIDs([]string{next.ID()})
+		if err := op.SetOutputs([]operator.Operator{next}); err != nil {
+			return nil, fmt.Errorf("connect metadata operator '%s': %s", op.ID(), err)
+		}
+	}
+
+	return &headerParser{
+		pattern:   pattern,
+		operators: operators,
+		first:     operators[0],
+		capture:   capture,
+	}, nil
+}
+
+// headerParser extracts attributes from the header lines of a file by passing each line
+// through the metadata operators, which are run synchronously
+type headerParser struct {
+	pattern   *regexp.Regexp
+	operators []operator.Operator
+	first     operator.Operator
+	capture   *headerCapture
+
+	// mux ensures that the header lines of one file are captured at a time
+	mux sync.Mutex
+}
+
+// Start starts the metadata operators, each after the operators it outputs to, as a pipeline would.
+// Their queues are not started, so that each line is processed before Parse returns.
+func (h *headerParser) Start(persister operator.Persister) error {
+	for i := len(h.operators) - 1; i >= 0; i-- {
+		op := h.operators[i]
+		if err := op.Start(operator.NewScopedPersister(op.ID(), persister)); err != nil {
+			h.stop(h.operators[i+1:])
+			return fmt.Errorf("start metadata operator '%s': %s", op.ID(), err)
+		}
+	}
+	return nil
+}
+
+// Stop stops the metadata operators, each before the operators it outputs to
+func (h *headerParser) Stop() {
+	h.stop(h.operators)
+}
+
+func (h *headerParser) stop(operators []operator.Operator) {
+	for _, op := range operators {
+		if err := op.Stop(); err != nil {
+			op.Logger().Errorw("Failed to stop metadata operator", "error", err)
+		}
+	}
+}
+
+// Match returns true if a line is part of the header
+func (h *headerParser) Match(line []byte) bool {
+	return h.pattern.Match(line)
+}
+
+// Parse passes a header line through the metadata operators, and returns the attributes of the result.
+// It returns nil attributes if the line was dropped or could not be parsed.
+func (h *headerParser) Parse(line string) (map[string]interface{}, error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.capture.result = nil
+	e := entry.New()
+	e.Body = line
+	if err := h.first.Process(context.Background(), e); err != nil {
+		return nil, err
+	}
+	if h.capture.result == nil {
+		return nil, nil
+	}
+	return h.capture.result.Attributes, nil
+}
+
+// headerCapture is the last of the metadata operators, and keeps the entry it receives
+type headerCapture struct {
+	helper.OutputOperator
+	result *entry.Entry
+}
+
+// Process keeps the entry for the header parser
+func (c *headerCapture) Process(_ context.Context, e *entry.Entry) error {
+	c.result = e
+	return nil
+}
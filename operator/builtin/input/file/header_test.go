@@ -0,0 +1,243 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/parser/regex"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// newW3CHeaderConfig parses the version and field names from the header of a W3C extended log file
+func newW3CHeaderConfig() *HeaderConfig {
+	version := regex.NewRegexParserConfig("version")
+	version.IfExpr = `$body startsWith "#Version"`
+	version.Regex = `^#Version: (?P<version>.*)$`
+	version.ParseTo = entry.NewAttributeField()
+
+	fields := regex.NewRegexParserConfig("fields")
+	fields.IfExpr = `$body startsWith "#Fields"`
+	fields.Regex = `^#Fields: (?P<fields>.*)$`
+	fields.ParseTo = entry.NewAttributeField()
+
+	return &HeaderConfig{
+		Pattern: "^#",
+		MetadataOperators: []operator.Config{
+			{Builder: version},
+			{Builder: fields},
+		},
+	}
+}
+
+func TestHeaderConfigBuild(t *testing.T) {
+	invalidRegex := regex.NewRegexParserConfig("invalid")
+	invalidRegex.Regex = "(?P<a"
+
+	cases := []struct {
+		name      string
+		config    HeaderConfig
+		expectErr bool
+	}{
+		{"Valid", *newW3CHeaderConfig(), false},
+		{"MissingPattern", HeaderConfig{MetadataOperators: newW3CHeaderConfig().MetadataOperators}, true},
+		{"InvalidPattern", HeaderConfig{Pattern: "(", MetadataOperators: newW3CHeaderConfig().MetadataOperators}, true},
+		{"MissingOperators", HeaderConfig{Pattern: "^#"}, true},
+		{"InvalidOperator", HeaderConfig{Pattern: "^#", MetadataOperators: []operator.Config{{Builder: invalidRegex}}}, true},
+		{"InputOperator", HeaderConfig{Pattern: "^#", MetadataOperators: []operator.Config{{Builder: NewInputConfig("nested")}}}, true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.config.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHeaderAttributes(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Header = newW3CHeaderConfig()
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "#Version: 1.0\n#Fields: date time cs-method\n2022-01-01 00:00:00 GET\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	e := waitForOne(t, logReceived)
+	require.Equal(t, "2022-01-01 00:00:00 GET", e.Body)
+	require.Equal(t, "1.0", e.Attributes["version"])
+	require.Equal(t, "date time cs-method", e.Attributes["fields"])
+
+	// Lines after the header that match the pattern are not treated as header lines
+	writeString(t, temp, "#Fields: other\n")
+	e = waitForOne(t, logReceived)
+	require.Equal(t, "#Fields: other", e.Body)
+	require.Equal(t, "date time cs-method", e.Attributes["fields"])
+}
+
+func TestHeaderPerFile(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Header = newW3CHeaderConfig()
+	}, nil)
+
+	withHeader := openTemp(t, tempDir)
+	writeString(t, withHeader, "#Fields: a b\nline1\n")
+	withoutHeader := openTemp(t, tempDir)
+	writeString(t, withoutHeader, "line2\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	for i := 0; i < 2; i++ {
+		e := waitForOne(t, logReceived)
+		switch e.Body {
+		case "line1":
+			require.Equal(t, "a b", e.Attributes["fields"])
+		case "line2":
+			require.NotContains(t, e.Attributes, "fields")
+		default:
+			require.FailNow(t, "unexpected entry", e.Body)
+		}
+	}
+}
+
+func TestHeaderPersisted(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Header = newW3CHeaderConfig()
+	}, nil)
+	persister := testutil.NewMockPersister("test")
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "#Version: 1.0\n#Fields: date time cs-method\n")
+
+	// The header is not finalized until a line after it has been read, but its attributes are persisted
+	operator.persister = persister
+	operator.poll(context.Background())
+	expectNoMessages(t, logReceived)
+	require.NoError(t, operator.Stop())
+
+	writeString(t, temp, "2022-01-01 00:00:00 GET\n")
+	require.NoError(t, operator.Start(persister))
+	e := waitForOne(t, logReceived)
+	require.Equal(t, "1.0", e.Attributes["version"])
+	require.NoError(t, operator.Stop())
+
+	// After a restart, the header is not read again, but its attributes are still added
+	writeString(t, temp, "2022-01-01 00:00:01 POST\n")
+	require.NoError(t, operator.Start(persister))
+	defer operator.Stop()
+	e = waitForOne(t, logReceived)
+	require.Equal(t, "2022-01-01 00:00:01 POST", e.Body)
+	require.Equal(t, "1.0", e.Attributes["version"])
+	require.Equal(t, "date time cs-method", e.Attributes["fields"])
+	expectNoMessages(t, logReceived)
+}
+
+// lifecycleConfig builds a metadata operator that records whether it is running,
+// and adds a nested attribute to each header line
+type lifecycleConfig struct {
+	helper.TransformerConfig
+	running *int32
+}
+
+func (c lifecycleConfig) Build(bc operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(bc)
+	if err != nil {
+		return nil, err
+	}
+	return []operator.Operator{&lifecycleOperator{TransformerOperator: transformer, running: c.running}}, nil
+}
+
+type lifecycleOperator struct {
+	helper.TransformerOperator
+	running *int32
+}
+
+func (o *lifecycleOperator) Start(_ operator.Persister) error {
+	atomic.StoreInt32(o.running, 1)
+	return nil
+}
+
+func (o *lifecycleOperator) Stop() error {
+	atomic.StoreInt32(o.running, 0)
+	return nil
+}
+
+func (o *lifecycleOperator) Process(ctx context.Context, e *entry.Entry) error {
+	e.AddAttribute("nested", map[string]interface{}{"key": "value"})
+	o.Write(ctx, e)
+	return nil
+}
+
+func newLifecycleHeaderConfig(running *int32) *HeaderConfig {
+	return &HeaderConfig{
+		Pattern: "^#",
+		MetadataOperators: []operator.Config{
+			{Builder: &lifecycleConfig{TransformerConfig: helper.NewTransformerConfig("lifecycle", "lifecycle"), running: running}},
+		},
+	}
+}
+
+func TestHeaderOperatorsLifecycle(t *testing.T) {
+	t.Parallel()
+	var running int32
+	operator, _, _ := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Header = newLifecycleHeaderConfig(&running)
+	}, nil)
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	require.Equal(t, int32(1), atomic.LoadInt32(&running))
+	require.NoError(t, operator.Stop())
+	require.Equal(t, int32(0), atomic.LoadInt32(&running))
+}
+
+func TestHeaderAttributesCopiedPerEntry(t *testing.T) {
+	t.Parallel()
+	var running int32
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Header = newLifecycleHeaderConfig(&running)
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "#header\nline1\nline2\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	first := waitForOne(t, logReceived)
+	first.Attributes["nested"].(map[string]interface{})["key"] = "modified"
+
+	second := waitForOne(t, logReceived)
+	require.Equal(t, "line2", second.Body)
+	require.Equal(t, map[string]interface{}{"key": "value"}, second.Attributes["nested"])
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+)
+
+const (
+	// MetricOffset is the offset up to which a file has been read.
+	MetricOffset = "stanza_file_input_offset_bytes"
+	// MetricLag is the number of bytes in a file that have not been read yet.
+	MetricLag = "stanza_file_input_lag_bytes"
+	// MetricTrackedFiles is the number of files whose fingerprints and offsets are being tracked.
+	MetricTrackedFiles = "stanza_file_input_tracked_files"
+)
+
+// pollStats is the state of the files read during the most recent poll
+type pollStats struct {
+	files        []fileStats
+	trackedFiles int
+}
+
+type fileStats struct {
+	path   string
+	offset int64
+	size   int64
+}
+
+// recordStats saves the offset and size of each file read during a poll
+func (f *InputOperator) recordStats(readers []*Reader) {
+	files := make([]fileStats, 0, len(readers))
+	for _, reader := range readers {
+		info, err := reader.file.Stat()
+		if err != nil {
+			f.Debugw("Failed to stat file", "path", reader.fileAttributes.Path, "error", err)
+			continue
+		}
+		size := info.Size()
+		if reader.compression != NoCompression {
+			// The offset of a compressed file is in its decompressed content, which is only
+			// read when the file grows, so it has no lag to compare against the file size
+			size = reader.Offset
+		}
+		files = append(files, fileStats{
+			path:   reader.fileAttributes.Path,
+			offset: reader.Offset,
+			size:   size,
+		})
+	}
+
+	f.statsMux.Lock()
+	defer f.statsMux.Unlock()
+	f.stats = pollStats{
+		files:        files,
+		trackedFiles: len(f.knownFiles),
+	}
+}
+
+// Metrics returns the operator's metrics, along with the offset and lag of each file read during the most recent poll
+func (f *InputOperator) Metrics() []operator.Metric {
+	metrics := f.InputOperator.Metrics()
+
+	f.statsMux.Lock()
+	defer f.statsMux.Unlock()
+
+	labels := func(extra ...string) map[string]string {
+		l := map[string]string{"operator_id": f.ID(), "operator_type": f.Type()}
+		for i := 0; i+1 < len(extra); i += 2 {
+			l[extra[i]] = extra[i+1]
+		}
+		return l
+	}
+
+	for _, file := range f.stats.files {
+		lag := file.size - file.offset
+		if lag < 0 {
+			lag = 0
+		}
+		metrics = append(metrics,
+			operator.Metric{
+				Name:   MetricOffset,
+				Help:   "Offset up to which the file has been read.",
+				Type:   operator.GaugeMetric,
+				Labels: labels("path", file.path),
+				Value:  float64(file.offset),
+			},
+			operator.Metric{
+				Name:   MetricLag,
+				Help:   "Bytes in the file that have not been read yet.",
+				Type:   operator.GaugeMetric,
+				Labels: labels("path", file.path),
+				Value:  float64(lag),
+			},
+		)
+	}
+
+	return append(metrics, operator.Metric{
+		Name:   MetricTrackedFiles,
+		Help:   "Files whose fingerprints and offsets are being tracked.",
+		Type:   operator.GaugeMetric,
+		Labels: labels(),
+		Value:  float64(f.stats.trackedFiles),
+	})
+}
@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	strptime "github.com/observiq/ctimefmt"
+)
+
+const (
+	// SortNumeric sorts files by a regex capture group parsed as an integer.
+	SortNumeric = "numeric"
+	// SortAlphabetical sorts files by a regex capture group compared as a string.
+	SortAlphabetical = "alphabetical"
+	// SortTimestamp sorts files by a regex capture group parsed as a timestamp.
+	SortTimestamp = "timestamp"
+	// SortMtime sorts files by their modification time.
+	SortMtime = "mtime"
+)
+
+// OrderingCriteria is the configuration of the order in which matched files are read
+type OrderingCriteria struct {
+	Regex  string     `mapstructure:"regex,omitempty"   json:"regex,omitempty"   yaml:"regex,omitempty"`
+	TopN   int        `mapstructure:"top_n,omitempty"   json:"top_n,omitempty"   yaml:"top_n,omitempty"`
+	SortBy []SortRule `mapstructure:"sort_by,omitempty" json:"sort_by,omitempty" yaml:"sort_by,omitempty"`
+}
+
+// SortRule is a single key by which matched files are sorted
+type SortRule struct {
+	SortType  string `mapstructure:"sort_type,omitempty" json:"sort_type,omitempty" yaml:"sort_type,omitempty"`
+	RegexKey  string `mapstructure:"regex_key,omitempty" json:"regex_key,omitempty" yaml:"regex_key,omitempty"`
+	Ascending bool   `mapstructure:"ascending,omitempty" json:"ascending,omitempty" yaml:"ascending,omitempty"`
+	Layout    string `mapstructure:"layout,omitempty"    json:"layout,omitempty"    yaml:"layout,omitempty"`
+	Location  string `mapstructure:"location,omitempty"  json:"location,omitempty"  yaml:"location,omitempty"`
+}
+
+// Build creates a fileSorter from the configuration. It returns nil if matched files are read in glob order.
+func (c OrderingCriteria) Build() (*fileSorter, error) {
+	if c.TopN < 0 {
+		return nil, fmt.Errorf("`top_n` must not be negative")
+	}
+	if len(c.SortBy) == 0 {
+		if c.TopN > 0 {
+			return nil, fmt.Errorf("`sort_by` is required when `top_n` is set")
+		}
+		if c.Regex != "" {
+			return nil, fmt.Errorf("`sort_by` is required when `regex` is set")
+		}
+		return nil, nil
+	}
+
+	s := &fileSorter{
+		topN:  c.TopN,
+		rules: make([]sortRule, 0, len(c.SortBy)),
+	}
+	if c.Regex != "" {
+		regex, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("compiling regex: %s", err)
+		}
+		s.regex = regex
+	}
+
+	for _, rule := range c.SortBy {
+		r := sortRule{
+			sortType:  rule.SortType,
+			ascending: rule.Ascending,
+		}
+
+		switch rule.SortType {
+		case SortMtime:
+			s.rules = append(s.rules, r)
+			continue
+		case SortNumeric, SortAlphabetical, SortTimestamp:
+		default:
+			return nil, fmt.Errorf("invalid sort_type '%s'", rule.SortType)
+		}
+
+		if s.regex == nil {
+			return nil, fmt.Errorf("`regex` is required to sort by %s", rule.SortType)
+		}
+		r.group = s.regex.SubexpIndex(rule.RegexKey)
+		if rule.RegexKey == "" || r.group < 0 {
+			return nil, fmt.Errorf("`regex_key` '%s' is not a named capture group of `regex`", rule.RegexKey)
+		}
+
+		if rule.SortType == SortTimestamp {
+			if rule.Layout == "" {
+				return nil, fmt.Errorf("`layout` is required to sort by timestamp")
+			}
+			layout, err := strptime.ToNative(rule.Layout)
+			if err != nil {
+				return nil, fmt.Errorf("parse strptime layout: %s", err)
+			}
+			r.layout = layout
+
+			r.location = time.UTC
+			if rule.Location != "" {
+				r.location, err = time.LoadLocation(rule.Location)
+				if err != nil {
+					return nil, fmt.Errorf("load location %s: %s", rule.Location, err)
+				}
+			}
+		}
+		s.rules = append(s.rules, r)
+	}
+
+	return s, nil
+}
+
+// fileSorter sorts matched files by keys extracted from their names or by their modification times
+type fileSorter struct {
+	regex *regexp.Regexp
+	rules []sortRule
+	topN  int
+}
+
+type sortRule struct {
+	sortType  string
+	group     int
+	ascending bool
+	layout    string
+	location  *time.Location
+}
+
+// sortKeys are the keys of a file, one per rule, holding an int64, a string or a time.Time
+type sortKeys struct {
+	path string
+	keys []interface{}
+}
+
+// Sort orders paths by the configured rules, then keeps the first top N.
+// Files whose keys cannot be extracted are placed last, in the order they were found.
+func (s *fileSorter) Sort(paths []string) []string {
+	sorted := make([]sortKeys, 0, len(paths))
+	unsorted := make([]string, 0)
+	for _, path := range paths {
+		keys, ok := s.keys(path)
+		if !ok {
+			unsorted = append(unsorted, path)
+			continue
+		}
+		sorted = append(sorted, sortKeys{path: path, keys: keys})
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for k, rule := range s.rules {
+			c := compareKeys(sorted[i].keys[k], sorted[j].keys[k])
+			if c == 0 {
+				continue
+			}
+			if rule.ascending {
+				return c < 0
+			}
+			return c > 0
+		}
+		return false
+	})
+
+	result := make([]string, 0, len(paths))
+	for _, file := range sorted {
+		result = append(result, file.path)
+	}
+	result = append(result, unsorted...)
+
+	if s.topN > 0 && len(result) > s.topN {
+		result = result[:s.topN]
+	}
+	return result
+}
+
+// keys extracts the sort keys of a file. It returns false if the file does not match
+// the regex, or a key cannot be parsed.
+func (s *fileSorter) keys(path string) ([]interface{}, bool) {
+	var groups []string
+	if s.regex != nil {
+		groups = s.regex.FindStringSubmatch(filepath.Base(path))
+	}
+
+	keys := make([]interface{}, 0, len(s.rules))
+	for _, rule := range s.rules {
+		if rule.sortType == SortMtime {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, false
+			}
+			keys = append(keys, info.ModTime())
+			continue
+		}
+
+		if groups == nil {
+			return nil, false
+		}
+		value := groups[rule.group]
+
+		switch rule.sortType {
+		case SortNumeric:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, false
+			}
+			keys = append(keys, n)
+		case SortTimestamp:
+			t, err := time.ParseInLocation(rule.layout, value, rule.location)
+			if err != nil {
+				return nil, false
+			}
+			keys = append(keys, t)
+		default:
+			keys = append(keys, value)
+		}
+	}
+	return keys, true
+}
+
+// compareKeys returns -1, 0 or 1 if a is less than, equal to, or greater than b
+func compareKeys(a, b interface{}) int {
+	switch a := a.(type) {
+	case int64:
+		b := b.(int64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case string:
+		b := b.(string)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case time.Time:
+		b := b.(time.Time)
+		switch {
+		case a.Before(b):
+			return -1
+		case a.After(b):
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,240 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestOrderingCriteriaBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		criteria  OrderingCriteria
+		expectErr bool
+	}{
+		{
+			name:     "Empty",
+			criteria: OrderingCriteria{},
+		},
+		{
+			name: "Numeric",
+			criteria: OrderingCriteria{
+				Regex:  `app-(?P<num>\d+)\.log`,
+				SortBy: []SortRule{{SortType: SortNumeric, RegexKey: "num"}},
+			},
+		},
+		{
+			name: "Timestamp",
+			criteria: OrderingCriteria{
+				Regex:  `app-(?P<ts>\d{8})\.log`,
+				SortBy: []SortRule{{SortType: SortTimestamp, RegexKey: "ts", Layout: "%Y%m%d", Location: "America/New_York"}},
+			},
+		},
+		{
+			name: "MtimeWithoutRegex",
+			criteria: OrderingCriteria{
+				TopN:   2,
+				SortBy: []SortRule{{SortType: SortMtime}},
+			},
+		},
+		{
+			name:      "NegativeTopN",
+			criteria:  OrderingCriteria{TopN: -1, SortBy: []SortRule{{SortType: SortMtime}}},
+			expectErr: true,
+		},
+		{
+			name:      "TopNWithoutSortBy",
+			criteria:  OrderingCriteria{TopN: 1},
+			expectErr: true,
+		},
+		{
+			name:      "RegexWithoutSortBy",
+			criteria:  OrderingCriteria{Regex: `(?P<num>\d+)`},
+			expectErr: true,
+		},
+		{
+			name:      "InvalidRegex",
+			criteria:  OrderingCriteria{Regex: `(?P<num>\d+`, SortBy: []SortRule{{SortType: SortNumeric, RegexKey: "num"}}},
+			expectErr: true,
+		},
+		{
+			name:      "InvalidSortType",
+			criteria:  OrderingCriteria{Regex: `(?P<num>\d+)`, SortBy: []SortRule{{SortType: "size", RegexKey: "num"}}},
+			expectErr: true,
+		},
+		{
+			name:      "MissingRegex",
+			criteria:  OrderingCriteria{SortBy: []SortRule{{SortType: SortNumeric, RegexKey: "num"}}},
+			expectErr: true,
+		},
+		{
+			name:      "MissingRegexKey",
+			criteria:  OrderingCriteria{Regex: `(?P<num>\d+)`, SortBy: []SortRule{{SortType: SortNumeric, RegexKey: "other"}}},
+			expectErr: true,
+		},
+		{
+			name:      "MissingLayout",
+			criteria:  OrderingCriteria{Regex: `(?P<ts>\d+)`, SortBy: []SortRule{{SortType: SortTimestamp, RegexKey: "ts"}}},
+			expectErr: true,
+		},
+		{
+			name:      "InvalidLocation",
+			criteria:  OrderingCriteria{Regex: `(?P<ts>\d+)`, SortBy: []SortRule{{SortType: SortTimestamp, RegexKey: "ts", Layout: "%Y", Location: "Mars/Olympus"}}},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.criteria.Build()
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFileSorter(t *testing.T) {
+	cases := []struct {
+		name     string
+		criteria OrderingCriteria
+		files    []string
+		expected []string
+	}{
+		{
+			name: "NumericAscending",
+			criteria: OrderingCriteria{
+				Regex:  `app\.log\.(?P<num>\d+)`,
+				SortBy: []SortRule{{SortType: SortNumeric, RegexKey: "num", Ascending: true}},
+			},
+			files:    []string{"app.log.10", "app.log.2", "app.log.1"},
+			expected: []string{"app.log.1", "app.log.2", "app.log.10"},
+		},
+		{
+			name: "NumericDescending",
+			criteria: OrderingCriteria{
+				Regex:  `app\.log\.(?P<num>\d+)`,
+				SortBy: []SortRule{{SortType: SortNumeric, RegexKey: "num"}},
+			},
+			files:    []string{"app.log.10", "app.log.2", "app.log.1"},
+			expected: []string{"app.log.10", "app.log.2", "app.log.1"},
+		},
+		{
+			name: "Alphabetical",
+			criteria: OrderingCriteria{
+				Regex:  `(?P<name>[a-z]+)\.log`,
+				SortBy: []SortRule{{SortType: SortAlphabetical, RegexKey: "name", Ascending: true}},
+			},
+			files:    []string{"c.log", "a.log", "b.log"},
+			expected: []string{"a.log", "b.log", "c.log"},
+		},
+		{
+			name: "Timestamp",
+			criteria: OrderingCriteria{
+				Regex:  `app-(?P<ts>\d{8}T\d{2})\.log`,
+				SortBy: []SortRule{{SortType: SortTimestamp, RegexKey: "ts", Layout: "%Y%m%dT%H"}},
+			},
+			files:    []string{"app-20210101T10.log", "app-20211231T01.log", "app-20210101T09.log"},
+			expected: []string{"app-20211231T01.log", "app-20210101T10.log", "app-20210101T09.log"},
+		},
+		{
+			name: "MultipleRules",
+			criteria: OrderingCriteria{
+				Regex: `(?P<host>[a-z]+)-(?P<num>\d+)\.log`,
+				SortBy: []SortRule{
+					{SortType: SortAlphabetical, RegexKey: "host", Ascending: true},
+					{SortType: SortNumeric, RegexKey: "num"},
+				},
+			},
+			files:    []string{"a-1.log", "b-3.log", "a-2.log", "b-1.log"},
+			expected: []string{"a-2.log", "a-1.log", "b-3.log", "b-1.log"},
+		},
+		{
+			name: "UnmatchedLast",
+			criteria: OrderingCriteria{
+				Regex:  `app\.log\.(?P<num>\d+)`,
+				SortBy: []SortRule{{SortType: SortNumeric, RegexKey: "num", Ascending: true}},
+			},
+			files:    []string{"other.log", "app.log.2", "app.log", "app.log.1"},
+			expected: []string{"app.log.1", "app.log.2", "other.log", "app.log"},
+		},
+		{
+			name: "TopN",
+			criteria: OrderingCriteria{
+				Regex:  `app\.log\.(?P<num>\d+)`,
+				TopN:   2,
+				SortBy: []SortRule{{SortType: SortNumeric, RegexKey: "num"}},
+			},
+			files:    []string{"app.log.1", "app.log.3", "app.log.2"},
+			expected: []string{"app.log.3", "app.log.2"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sorter, err := tc.criteria.Build()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, sorter.Sort(tc.files))
+		})
+	}
+}
+
+func TestFileSorterMtime(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	now := time.Now()
+	paths := make([]string, 0, 3)
+	for i, name := range []string{"a.log", "b.log", "c.log"} {
+		path := filepath.Join(tempDir, name)
+		writeFile(t, path, []byte(name))
+		mtime := now.Add(-time.Duration(i) * time.Hour)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+		paths = append(paths, path)
+	}
+
+	sorter, err := OrderingCriteria{SortBy: []SortRule{{SortType: SortMtime, Ascending: true}}}.Build()
+	require.NoError(t, err)
+	require.Equal(t, []string{paths[2], paths[1], paths[0]}, sorter.Sort(paths))
+}
+
+func TestOrderingTopNNewest(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.OrderingCriteria = OrderingCriteria{
+			Regex:  `app-(?P<date>\d{8})\.log`,
+			TopN:   1,
+			SortBy: []SortRule{{SortType: SortTimestamp, RegexKey: "date", Layout: "%Y%m%d"}},
+		}
+	}, nil)
+	operator.persister = testutil.NewMockPersister("test")
+	defer operator.Stop()
+
+	writeFile(t, filepath.Join(tempDir, "app-20210101.log"), []byte("old\n"))
+	writeFile(t, filepath.Join(tempDir, "app-20220101.log"), []byte("new\n"))
+	writeFile(t, filepath.Join(tempDir, "app-20211231.log"), []byte("older\n"))
+
+	operator.poll(context.Background())
+	waitForMessage(t, logReceived, "new")
+	expectNoMessages(t, logReceived)
+}
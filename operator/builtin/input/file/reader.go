@@ -18,8 +18,10 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/text/encoding"
@@ -64,19 +66,50 @@ type Reader struct {
 	Fingerprint *Fingerprint
 	Offset      int64
 
+	// HeaderAttributes are the attributes parsed from the header lines of the file
+	HeaderAttributes map[string]interface{} `json:",omitempty"`
+	// HeaderFinalized is true once a line after the header lines has been read
+	HeaderFinalized bool `json:",omitempty"`
+
 	generation     int
 	fileInput      *InputOperator
 	file           *os.File
 	fileAttributes *fileAttributes
 
+	// source is read by the scanner, and is either the file or a decompressor of it
+	source      io.Reader
+	compression string
+	// readSize is the size of a compressed file when it was last read to the end, so that
+	// it is only decompressed again if it has grown
+	readSize int64
+	// polledSize is the size of a compressed file when it was last polled, so that it
+	// is only decompressed once it has stopped growing
+	polledSize int64
+
+	// idleSize and idleSince are the size of the file and the time when it was first seen
+	// with that size, which are used to find files that have stopped changing
+	idleSize  int64
+	idleSince time.Time
+
 	decoder      *encoding.Decoder
 	decodeBuffer []byte
 
 	splitter *helper.Splitter
 
+	// acks tracks the offset up to which entries have been delivered, when waiting for acknowledgements
+	acks *helper.CheckpointTracker
+
 	*zap.SugaredLogger `json:"-"`
 }
 
+// readerCheckpoint is the persisted state of a Reader
+type readerCheckpoint struct {
+	Fingerprint      *Fingerprint
+	Offset           int64
+	HeaderAttributes map[string]interface{} `json:",omitempty"`
+	HeaderFinalized  bool                   `json:",omitempty"`
+}
+
 // NewReader creates a new file reader
 func (f *InputOperator) NewReader(path string, file *os.File, fp *Fingerprint, splitter *helper.Splitter) (*Reader, error) {
 	r := &Reader{
@@ -88,6 +121,10 @@ func (f *InputOperator) NewReader(path string, file *os.File, fp *Fingerprint, s
 		decodeBuffer:   make([]byte, 1<<12),
 		fileAttributes: f.resolveFileAttributes(path),
 		splitter:       splitter,
+		compression:    NoCompression,
+	}
+	if file != nil {
+		r.compression = f.detectCompression(file)
 	}
 	return r, nil
 }
@@ -99,26 +136,91 @@ func (r *Reader) Copy(file *os.File) (*Reader, error) {
 		return nil, err
 	}
 	reader.Offset = r.Offset
+	reader.acks = r.acks
+	reader.readSize = r.readSize
+	reader.polledSize = r.polledSize
+	reader.idleSize = r.idleSize
+	reader.idleSince = r.idleSince
+	reader.HeaderFinalized = r.HeaderFinalized
+	if r.HeaderAttributes != nil {
+		reader.HeaderAttributes = make(map[string]interface{}, len(r.HeaderAttributes))
+		for k, v := range r.HeaderAttributes {
+			reader.HeaderAttributes[k] = v
+		}
+	}
 	return reader, nil
 }
 
+// checkpoint returns the state of the reader to persist. When waiting for acknowledgements,
+// this is the offset up to which entries have been delivered rather than the offset read.
+func (r *Reader) checkpoint() readerCheckpoint {
+	offset := r.Offset
+	if r.acks != nil {
+		offset = r.acks.Committed().(int64)
+	}
+	return readerCheckpoint{
+		Fingerprint:      r.Fingerprint,
+		Offset:           offset,
+		HeaderAttributes: r.HeaderAttributes,
+		HeaderFinalized:  r.HeaderFinalized,
+	}
+}
+
 // InitializeOffset sets the starting offset
 func (r *Reader) InitializeOffset(startAtBeginning bool) error {
-	if !startAtBeginning {
-		info, err := r.file.Stat()
+	if startAtBeginning {
+		return nil
+	}
+
+	if r.compression != NoCompression {
+		size, err := decompressedSize(r.compression, r.file, r.fileInput.maxDecompressedSize)
 		if err != nil {
-			return fmt.Errorf("stat: %s", err)
+			return fmt.Errorf("decompress: %s", err)
 		}
-		r.Offset = info.Size()
+		r.Offset = size
+		return nil
 	}
+
+	info, err := r.file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat: %s", err)
+	}
+	r.Offset = info.Size()
 	return nil
 }
 
 // ReadToEnd will read until the end of the file
 func (r *Reader) ReadToEnd(ctx context.Context) {
-	if _, err := r.file.Seek(r.Offset, 0); err != nil {
-		r.Errorw("Failed to seek", zap.Error(err))
-		return
+	// Once an entry has failed to be delivered, it and the entries after it are read again
+	if r.acks != nil && r.acks.Stalled() {
+		r.Offset = r.acks.Rewind().(int64)
+		r.readSize = 0
+		r.Debugw("Reading again from the last delivered entry", "offset", r.Offset)
+	}
+
+	if r.compression != NoCompression {
+		size, d, ok := r.openDecompressed()
+		if !ok {
+			return
+		}
+		defer func() {
+			_ = d.Close()
+			// A file is only skipped until it grows once all of its content that can be read has been emitted
+			if d.offset == r.Offset || d.exceeded {
+				r.readSize = size
+			}
+		}()
+		r.source = d
+	} else {
+		if _, err := r.file.Seek(r.Offset, 0); err != nil {
+			r.Errorw("Failed to seek", zap.Error(err))
+			return
+		}
+		r.source = r.file
+	}
+
+	if r.fileInput.waitForAck && r.acks == nil {
+		r.acks = helper.NewCheckpointTracker(r.Offset, nil)
 	}
 
 	scanner := NewPositionalScanner(r, r.fileInput.MaxLogSize, r.Offset, r.splitter.SplitFunc)
@@ -131,6 +233,11 @@ func (r *Reader) ReadToEnd(ctx context.Context) {
 		default:
 		}
 
+		// Stop reading once an entry has failed, since the next poll reads it again
+		if r.acks != nil && r.acks.Stalled() {
+			return
+		}
+
 		ok := scanner.Scan()
 		if !ok {
 			if err := getScannerError(scanner); err != nil {
@@ -144,13 +251,46 @@ func (r *Reader) ReadToEnd(ctx context.Context) {
 		// Update information about last flush time
 		r.splitter.Flushed()
 
-		if err := r.emit(ctx, scanner.Bytes()); err != nil {
+		entryCtx := ctx
+		if r.acks != nil {
+			entryCtx = r.acks.Track(ctx, scanner.Pos())
+		}
+
+		if err := r.emit(entryCtx, scanner.Bytes()); err != nil {
 			r.Error("Failed to emit entry", zap.Error(err))
+			// The entry cannot be created, so rereading it would not help
+			helper.Ack(entryCtx)
 		}
 		r.Offset = scanner.Pos()
 	}
 }
 
+// openDecompressed returns a decompressor of the file positioned at the offset of the reader.
+// Offsets of compressed files are in the decompressed content, so the file is decompressed
+// from the start. It returns false if the file has not grown since it was last read, or if
+// it is still growing, since it would otherwise be decompressed again on every poll.
+func (r *Reader) openDecompressed() (int64, *decompressedReader, bool) {
+	info, err := r.file.Stat()
+	if err != nil {
+		r.Errorw("Failed to stat", zap.Error(err))
+		return 0, nil, false
+	}
+	if info.Size() == r.readSize {
+		return 0, nil, false
+	}
+	if info.Size() != r.polledSize {
+		r.polledSize = info.Size()
+		return 0, nil, false
+	}
+
+	d, err := newDecompressedReader(r.compression, r.file, r.Offset, r.fileInput.maxDecompressedSize)
+	if err != nil {
+		r.Errorw("Failed to decompress", zap.Error(err), "compression", r.compression)
+		return 0, nil, false
+	}
+	return info.Size(), d, true
+}
+
 // Close will close the file
 func (r *Reader) Close() {
 	if r.file != nil {
@@ -165,8 +305,15 @@ func (r *Reader) Close() {
 func (r *Reader) emit(ctx context.Context, msgBuf []byte) error {
 	// Skip the entry if it's empty
 	if len(msgBuf) == 0 {
+		helper.Ack(ctx)
+		return nil
+	}
+
+	if r.fileInput.header != nil && !r.HeaderFinalized && r.readHeader(msgBuf) {
+		helper.Ack(ctx)
 		return nil
 	}
+
 	var e *entry.Entry
 	var err error
 	if r.fileInput.encoding.Encoding == encoding.Nop {
@@ -185,6 +332,14 @@ func (r *Reader) emit(ctx context.Context, msgBuf []byte) error {
 		}
 	}
 
+	if len(r.HeaderAttributes) > 0 {
+		// Each entry gets its own copy of the header attributes, since they may be modified downstream
+		header := (&entry.Entry{Attributes: r.HeaderAttributes}).Copy()
+		for k, v := range header.Attributes {
+			e.AddAttribute(k, v)
+		}
+	}
+
 	if err := e.Set(r.fileInput.FilePathField, r.fileAttributes.Path); err != nil {
 		return err
 	}
@@ -204,6 +359,38 @@ func (r *Reader) emit(ctx context.Context, msgBuf []byte) error {
 	return nil
 }
 
+// readHeader parses a line with the header parser if it matches the header pattern, and returns true.
+// The header is finalized when the first line that does not match is read.
+func (r *Reader) readHeader(msgBuf []byte) bool {
+	h := r.fileInput.header
+	if !h.Match(msgBuf) {
+		r.HeaderFinalized = true
+		return false
+	}
+
+	line := string(msgBuf)
+	if r.fileInput.encoding.Encoding != encoding.Nop {
+		var err error
+		if line, err = r.decode(msgBuf); err != nil {
+			r.Errorw("Failed to decode header line", zap.Error(err))
+			return true
+		}
+	}
+
+	attributes, err := h.Parse(line)
+	if err != nil {
+		r.Errorw("Failed to parse header line", zap.Error(err))
+		return true
+	}
+	for k, v := range attributes {
+		if r.HeaderAttributes == nil {
+			r.HeaderAttributes = make(map[string]interface{})
+		}
+		r.HeaderAttributes[k] = v
+	}
+	return true
+}
+
 // decode converts the bytes in msgBuf to utf-8 from the configured encoding
 func (r *Reader) decode(msgBuf []byte) (string, error) {
 	for {
@@ -229,12 +416,12 @@ func getScannerError(scanner *PositionalScanner) error {
 	return nil
 }
 
-// Read from the file and update the fingerprint if necessary
+// Read from the file, or its decompressed content, and update the fingerprint if necessary
 func (r *Reader) Read(dst []byte) (int, error) {
 	if len(r.Fingerprint.FirstBytes) == r.fileInput.fingerprintSize {
-		return r.file.Read(dst)
+		return r.source.Read(dst)
 	}
-	n, err := r.file.Read(dst)
+	n, err := r.source.Read(dst)
 	appendCount := min0(n, r.fileInput.fingerprintSize-int(r.Offset))
 	r.Fingerprint.FirstBytes = append(r.Fingerprint.FirstBytes[:r.Offset], dst[:appendCount]...)
 	return n, err
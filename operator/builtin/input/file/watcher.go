@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// watchRoot is a directory that must be watched to see changes to the files matching an include pattern,
+// along with how many levels of subdirectories below it must also be watched. A negative depth means
+// that every subdirectory must be watched.
+type watchRoot struct {
+	dir   string
+	depth int
+}
+
+// watchRoots returns the directories that must be watched to see changes to the files matching the include patterns.
+// If a directory does not exist yet, its nearest existing parent is watched instead, so that it is seen once created.
+func watchRoots(include []string) []watchRoot {
+	roots := make([]watchRoot, 0, len(include))
+	depths := make(map[string]int)
+	for _, pattern := range include {
+		dir, depth := filepath.Dir(pattern), 0
+		if hasMeta(pattern) {
+			dir, depth = pattern, -1
+			recursive := false
+			for hasMeta(dir) {
+				if strings.Contains(filepath.Base(dir), "**") {
+					recursive = true
+				}
+				dir = filepath.Dir(dir)
+				depth++
+			}
+			if recursive {
+				depth = -1
+			}
+		}
+
+		for {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				break
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+			if depth >= 0 {
+				depth++
+			}
+		}
+
+		existing, ok := depths[dir]
+		switch {
+		case !ok:
+			roots = append(roots, watchRoot{dir: dir, depth: depth})
+		case existing < 0 || (depth >= 0 && existing >= depth):
+			continue
+		default:
+			for i := range roots {
+				if roots[i].dir == dir {
+					roots[i].depth = depth
+				}
+			}
+		}
+		depths[dir] = depth
+	}
+	return roots
+}
+
+// hasMeta reports whether a path contains any of the special characters of a glob pattern
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[{\\")
+}
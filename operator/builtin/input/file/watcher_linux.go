@@ -0,0 +1,219 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// remoteFilesystems are the magic numbers of filesystems that can be changed without inotify seeing it,
+// such as by another host writing to a network share
+var remoteFilesystems = map[uint32]string{
+	0x6969:     "nfs",
+	0x517b:     "smb",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x01021997: "9p",
+	0x65735546: "fuse",
+}
+
+// watcher uses inotify to report changes to the directories that contain the files matching the include patterns
+type watcher struct {
+	*zap.SugaredLogger
+	fsw *fsnotify.Watcher
+
+	// depths are the watched directories, along with how many levels of their subdirectories are also watched
+	depths map[string]int
+
+	// changes receives a value when a watched file or directory changes. Changes are coalesced until received.
+	changes chan struct{}
+}
+
+// newWatcher creates a watcher for the directories of the include patterns, and of the files that symlinks in them point to.
+// It returns an error if any directory cannot be watched, such as when the inotify watch limit has been reached,
+// or if it is on a remote filesystem where inotify would not report every change.
+func newWatcher(include []string, logger *zap.SugaredLogger) (*watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create inotify watcher: %s", err)
+	}
+
+	w := &watcher{
+		SugaredLogger: logger,
+		fsw:           fsw,
+		depths:        make(map[string]int),
+		changes:       make(chan struct{}, 1),
+	}
+	for _, root := range watchRoots(include) {
+		if err := w.watchTree(root.dir, root.depth); err != nil {
+			_ = fsw.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// run reports changes until the context is cancelled, then closes the watcher
+func (w *watcher) run(ctx context.Context) {
+	defer w.fsw.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if event.Op&fsnotify.Create != 0 {
+				w.watchCreated(event.Name)
+			}
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				delete(w.depths, event.Name)
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			w.notify()
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been lost, such as when the event queue overflows, so rescan
+			w.Debugw("File watcher reported an error", zap.Error(err))
+			w.notify()
+		}
+	}
+}
+
+func (w *watcher) notify() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+	}
+}
+
+// watchTree watches a directory, and its subdirectories up to depth levels below it
+func (w *watcher) watchTree(dir string, depth int) error {
+	if existing, ok := w.depths[dir]; ok && (existing < 0 || (depth >= 0 && existing >= depth)) {
+		return nil
+	}
+
+	if err := w.watchDir(dir, depth); err != nil {
+		return err
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		// The directory may have been removed since it was added
+		return nil
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.Mode()&os.ModeSymlink != 0:
+			if err := w.watchTarget(path); err != nil {
+				return err
+			}
+		case entry.IsDir() && depth != 0:
+			if err := w.watchTree(path, subdirectoryDepth(depth)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// watchDir watches a single directory, unless it is on a remote filesystem
+func (w *watcher) watchDir(dir string, depth int) error {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err == nil {
+		if fs, ok := remoteFilesystems[uint32(stat.Type)]; ok {
+			return fmt.Errorf("directory %s is on a %s filesystem, which does not report every change", dir, fs)
+		}
+	}
+
+	if err := w.fsw.Add(dir); err != nil {
+		return fmt.Errorf("watch directory %s: %s", dir, err)
+	}
+	w.depths[dir] = depth
+	return nil
+}
+
+// watchTarget watches the directory of the file that a symlink points to, since writes
+// to the file are only reported in its own directory
+func (w *watcher) watchTarget(link string) error {
+	target, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		// The symlink is dangling, so there is nothing to watch until it is replaced
+		return nil
+	}
+
+	info, err := os.Stat(target)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+
+	dir := filepath.Dir(target)
+	if _, ok := w.depths[dir]; ok {
+		return nil
+	}
+	return w.watchDir(dir, 0)
+}
+
+// watchCreated watches the target of a newly created symlink, or a newly created directory
+// if it is below a watched directory that watches its subdirectories
+func (w *watcher) watchCreated(path string) {
+	parentDepth, ok := w.depths[filepath.Dir(path)]
+	if !ok {
+		return
+	}
+
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := w.watchTarget(path); err != nil {
+			w.Warnw("Failed to watch the target of a new symlink, writes to it will be found on the next resync", "path", path, zap.Error(err))
+		}
+	}
+
+	if parentDepth == 0 {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return
+	}
+
+	if err := w.watchTree(path, subdirectoryDepth(parentDepth)); err != nil {
+		w.Warnw("Failed to watch new directory, its files will be found on the next resync", "path", path, zap.Error(err))
+	}
+}
+
+func subdirectoryDepth(depth int) int {
+	if depth < 0 {
+		return depth
+	}
+	return depth - 1
+}
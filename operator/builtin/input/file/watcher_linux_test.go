@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// The resync interval is too long to be reached during these tests, so files can only be found through inotify
+
+func TestNotifyExistingFiles(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DiscoveryMode = NotifyDiscovery
+		cfg.ResyncInterval = helper.NewDuration(time.Hour)
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	waitForMessage(t, logReceived, "testlog1")
+}
+
+func TestNotifyNewFilesAndWrites(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.Include = []string{filepath.Join(filepath.Dir(cfg.Include[0]), "*", "*", "*.log")}
+		cfg.DiscoveryMode = NotifyDiscovery
+		cfg.ResyncInterval = helper.NewDuration(time.Hour)
+	}, nil)
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	// Directories created after the operator started are watched as well
+	dir := filepath.Join(tempDir, "pod", "container")
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	time.Sleep(100 * time.Millisecond)
+
+	temp := openFile(t, filepath.Join(dir, "0.log"))
+	writeString(t, temp, "testlog1\n")
+	waitForMessage(t, logReceived, "testlog1")
+
+	writeString(t, temp, "testlog2\n")
+	waitForMessage(t, logReceived, "testlog2")
+}
+
+func TestNotifyTruncation(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DiscoveryMode = NotifyDiscovery
+		cfg.ResyncInterval = helper.NewDuration(time.Hour)
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\n")
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+	waitForMessage(t, logReceived, "testlog1")
+
+	require.NoError(t, temp.Truncate(0))
+	_, err := temp.Seek(0, 0)
+	require.NoError(t, err)
+	writeString(t, temp, "testlog2\n")
+	waitForMessage(t, logReceived, "testlog2")
+}
+
+func TestNotifySymlinkTarget(t *testing.T) {
+	t.Parallel()
+	targetDir := testutil.NewTempDir(t)
+	target := openFile(t, filepath.Join(targetDir, "app.log"))
+	writeString(t, target, "testlog1\n")
+
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DiscoveryMode = NotifyDiscovery
+		cfg.ResyncInterval = helper.NewDuration(time.Hour)
+	}, nil)
+	require.NoError(t, os.Symlink(target.Name(), filepath.Join(tempDir, "app.log")))
+
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+	waitForMessage(t, logReceived, "testlog1")
+
+	// Writes to the target are only reported in the target's directory
+	writeString(t, target, "testlog2\n")
+	waitForMessage(t, logReceived, "testlog2")
+}
+
+func TestNotifyPollsAtMostEveryPollInterval(t *testing.T) {
+	t.Parallel()
+	operator, logReceived, tempDir := newTestFileOperator(t, func(cfg *InputConfig) {
+		cfg.DiscoveryMode = NotifyDiscovery
+		cfg.ResyncInterval = helper.NewDuration(time.Hour)
+		cfg.PollInterval = helper.NewDuration(time.Second)
+	}, nil)
+
+	temp := openTemp(t, tempDir)
+	require.NoError(t, operator.Start(testutil.NewMockPersister("test")))
+	defer operator.Stop()
+
+	writeString(t, temp, "testlog1\n")
+	waitForMessage(t, logReceived, "testlog1")
+	received := time.Now()
+
+	writeString(t, temp, "testlog2\n")
+	waitForMessage(t, logReceived, "testlog2")
+	require.Greater(t, int64(time.Since(received)), int64(500*time.Millisecond))
+}
@@ -0,0 +1,36 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+// +build !linux
+
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// watcher is only implemented on linux
+type watcher struct {
+	changes chan struct{}
+}
+
+func newWatcher(_ []string, _ *zap.SugaredLogger) (*watcher, error) {
+	return nil, fmt.Errorf("discovery_mode 'notify' is only supported on linux")
+}
+
+func (w *watcher) run(_ context.Context) {}
@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestWatchRoots(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "pods", "app"), 0700))
+
+	cases := []struct {
+		name     string
+		include  []string
+		expected []watchRoot
+	}{
+		{
+			"Literal",
+			[]string{filepath.Join(tempDir, "test.log")},
+			[]watchRoot{{tempDir, 0}},
+		},
+		{
+			"Glob",
+			[]string{filepath.Join(tempDir, "*.log")},
+			[]watchRoot{{tempDir, 0}},
+		},
+		{
+			"NestedGlob",
+			[]string{filepath.Join(tempDir, "pods", "*", "*", "*.log")},
+			[]watchRoot{{filepath.Join(tempDir, "pods"), 2}},
+		},
+		{
+			"DoubleAsterisk",
+			[]string{filepath.Join(tempDir, "**", "*.log")},
+			[]watchRoot{{tempDir, -1}},
+		},
+		{
+			"MissingDirectory",
+			[]string{filepath.Join(tempDir, "missing", "nested", "*.log")},
+			[]watchRoot{{tempDir, 2}},
+		},
+		{
+			"SameDirectory",
+			[]string{
+				filepath.Join(tempDir, "*.log"),
+				filepath.Join(tempDir, "*", "*.log"),
+				filepath.Join(tempDir, "*.txt"),
+			},
+			[]watchRoot{{tempDir, 1}},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, watchRoots(tc.include))
+		})
+	}
+}
@@ -0,0 +1,410 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// The layout of journal files is described at https://systemd.io/JOURNAL_FILE_FORMAT/
+const (
+	journalSignature = "LPKSHHRH"
+
+	// headerMinSize is the size of the header fields that are read
+	headerMinSize = 208
+
+	incompatibleCompressedXZ   = 1 << 0
+	incompatibleCompressedLZ4  = 1 << 1
+	incompatibleKeyedHash      = 1 << 2
+	incompatibleCompressedZstd = 1 << 3
+	incompatibleCompact        = 1 << 4
+	incompatibleSupported      = incompatibleCompressedLZ4 | incompatibleKeyedHash | incompatibleCompressedZstd |
+		incompatibleCompact
+
+	objectHeaderSize = 16
+	objectData       = 1
+	objectEntry      = 3
+	objectEntryArray = 6
+
+	objectCompressedXZ   = 1 << 0
+	objectCompressedLZ4  = 1 << 1
+	objectCompressedZstd = 1 << 2
+
+	// lz4MaxRatio is the largest ratio of decompressed to compressed size that lz4 can achieve
+	lz4MaxRatio = 255
+
+	entryItemsOffset      = 64
+	entryArrayItemsOffset = 24
+	dataPayloadOffset     = 64
+	compactPayloadOffset  = 72
+
+	// stateArchived is the state of a journal file that is no longer written to
+	stateArchived = 2
+)
+
+// journalHeader holds the fields of a journal file header that are needed to read its entries
+type journalHeader struct {
+	incompatibleFlags uint32
+	state             uint8
+	fileID            [16]byte
+	seqnumID          [16]byte
+	nEntries          uint64
+	tailSeqnum        uint64
+	entryArrayOffset  uint64
+	tailRealtime      uint64
+}
+
+// journalEntry is an entry of a journal file
+type journalEntry struct {
+	seqnumID  [16]byte
+	seqnum    uint64
+	realtime  uint64
+	monotonic uint64
+	bootID    [16]byte
+	xorHash   uint64
+	data      [][]byte
+}
+
+// journalFile reads the entries of a journal file in order, including entries appended
+// after it was opened
+type journalFile struct {
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	header  journalHeader
+	decoder *zstd.Decoder
+
+	// The position of the next entry in the chain of entry arrays
+	array       []byte
+	arrayOffset uint64
+	arrayIndex  uint64
+
+	// after is set to skip the entries up to a cursor
+	after *journalCursor
+	// next is the next entry to be read, if it has already been read ahead
+	next *journalEntry
+}
+
+// openJournalFile opens a journal file and reads its header
+func openJournalFile(path string, decoder *zstd.Decoder) (*journalFile, error) {
+	file, err := os.Open(path) // #nosec - operator must read in journal files specified by user
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	f := &journalFile{path: path, file: file, info: info, decoder: decoder}
+	if err := f.readHeader(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("read journal file %s: %s", path, err)
+	}
+	return f, nil
+}
+
+// Close closes the journal file
+func (f *journalFile) Close() error {
+	return f.file.Close()
+}
+
+// readHeader reads the header of the file again, to find the entries appended since it was last read
+func (f *journalFile) readHeader() error {
+	buf := make([]byte, headerMinSize)
+	if _, err := f.file.ReadAt(buf, 0); err != nil {
+		return fmt.Errorf("read header: %s", err)
+	}
+
+	if string(buf[0:8]) != journalSignature {
+		return fmt.Errorf("not a journal file")
+	}
+
+	h := journalHeader{
+		incompatibleFlags: binary.LittleEndian.Uint32(buf[12:16]),
+		state:             buf[16],
+		nEntries:          binary.LittleEndian.Uint64(buf[152:160]),
+		tailSeqnum:        binary.LittleEndian.Uint64(buf[160:168]),
+		entryArrayOffset:  binary.LittleEndian.Uint64(buf[176:184]),
+		tailRealtime:      binary.LittleEndian.Uint64(buf[192:200]),
+	}
+	copy(h.fileID[:], buf[24:40])
+	copy(h.seqnumID[:], buf[72:88])
+
+	if h.incompatibleFlags&incompatibleCompressedXZ != 0 {
+		return fmt.Errorf("fields are compressed with xz, which is not supported")
+	}
+	if unsupported := h.incompatibleFlags &^ incompatibleSupported; unsupported != 0 {
+		return fmt.Errorf("unsupported incompatible flags %#x", unsupported)
+	}
+
+	f.header = h
+	return nil
+}
+
+func (f *journalFile) compact() bool {
+	return f.header.incompatibleFlags&incompatibleCompact != 0
+}
+
+// readObject reads an object of the expected type
+func (f *journalFile) readObject(offset uint64, objectType uint8) (flags uint8, object []byte, err error) {
+	header := make([]byte, objectHeaderSize)
+	if _, err := f.file.ReadAt(header, int64(offset)); err != nil {
+		return 0, nil, fmt.Errorf("read object at %d: %s", offset, err)
+	}
+
+	if header[0] != objectType {
+		return 0, nil, fmt.Errorf("object at %d has type %d, expected %d", offset, header[0], objectType)
+	}
+
+	size := binary.LittleEndian.Uint64(header[8:16])
+	if size < objectHeaderSize || size > 1<<30 {
+		return 0, nil, fmt.Errorf("object at %d has invalid size %d", offset, size)
+	}
+
+	object = make([]byte, size)
+	if _, err := f.file.ReadAt(object, int64(offset)); err != nil {
+		return 0, nil, fmt.Errorf("read object at %d: %s", offset, err)
+	}
+	return header[1], object, nil
+}
+
+// nextEntryOffset returns the offset of the next entry, or 0 if every entry written so far has been read
+func (f *journalFile) nextEntryOffset() (uint64, error) {
+	itemSize := uint64(8)
+	if f.compact() {
+		itemSize = 4
+	}
+
+	for {
+		if f.array == nil {
+			if f.arrayOffset == 0 {
+				if f.header.entryArrayOffset == 0 {
+					return 0, nil
+				}
+				f.arrayOffset = f.header.entryArrayOffset
+				f.arrayIndex = 0
+			}
+
+			_, array, err := f.readObject(f.arrayOffset, objectEntryArray)
+			if err != nil {
+				return 0, err
+			}
+			if len(array) < entryArrayItemsOffset {
+				return 0, fmt.Errorf("entry array at %d is truncated", f.arrayOffset)
+			}
+			f.array = array
+		}
+
+		capacity := (uint64(len(f.array)) - entryArrayItemsOffset) / itemSize
+		if f.arrayIndex < capacity {
+			item := f.array[entryArrayItemsOffset+f.arrayIndex*itemSize:]
+			var offset uint64
+			if f.compact() {
+				offset = uint64(binary.LittleEndian.Uint32(item))
+			} else {
+				offset = binary.LittleEndian.Uint64(item)
+			}
+
+			// The unused items at the end of the last array are zero until entries are
+			// appended, so the array is read again next time
+			if offset == 0 {
+				f.array = nil
+				return 0, nil
+			}
+			f.arrayIndex++
+			return offset, nil
+		}
+
+		next := binary.LittleEndian.Uint64(f.array[16:24])
+		f.array = nil
+		if next == 0 {
+			return 0, nil
+		}
+		f.arrayOffset = next
+		f.arrayIndex = 0
+	}
+}
+
+// seekEnd skips every entry written so far
+func (f *journalFile) seekEnd() error {
+	for {
+		offset, err := f.nextEntryOffset()
+		if err != nil || offset == 0 {
+			return err
+		}
+	}
+}
+
+// readEntry reads the entry at an offset. The data of the entry is only read if withData is true.
+func (f *journalFile) readEntry(offset uint64, withData bool) (*journalEntry, error) {
+	_, object, err := f.readObject(offset, objectEntry)
+	if err != nil {
+		return nil, err
+	}
+	if len(object) < entryItemsOffset {
+		return nil, fmt.Errorf("entry at %d is truncated", offset)
+	}
+
+	e := &journalEntry{
+		seqnumID:  f.header.seqnumID,
+		seqnum:    binary.LittleEndian.Uint64(object[16:24]),
+		realtime:  binary.LittleEndian.Uint64(object[24:32]),
+		monotonic: binary.LittleEndian.Uint64(object[32:40]),
+		xorHash:   binary.LittleEndian.Uint64(object[56:64]),
+	}
+	copy(e.bootID[:], object[40:56])
+
+	if !withData {
+		return e, nil
+	}
+
+	itemSize := 16
+	if f.compact() {
+		itemSize = 4
+	}
+	items := object[entryItemsOffset:]
+	e.data = make([][]byte, 0, len(items)/itemSize)
+	for i := 0; i+itemSize <= len(items); i += itemSize {
+		var dataOffset uint64
+		if f.compact() {
+			dataOffset = uint64(binary.LittleEndian.Uint32(items[i:]))
+		} else {
+			dataOffset = binary.LittleEndian.Uint64(items[i:])
+		}
+
+		data, err := f.readData(dataOffset)
+		if err != nil {
+			return nil, fmt.Errorf("entry at %d: %s", offset, err)
+		}
+		e.data = append(e.data, data)
+	}
+	return e, nil
+}
+
+// readData reads the payload of a data object, in the form FIELD=value
+func (f *journalFile) readData(offset uint64) ([]byte, error) {
+	flags, object, err := f.readObject(offset, objectData)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadOffset := dataPayloadOffset
+	if f.compact() {
+		payloadOffset = compactPayloadOffset
+	}
+	if len(object) < payloadOffset {
+		return nil, fmt.Errorf("data at %d is truncated", offset)
+	}
+	payload := object[payloadOffset:]
+
+	switch {
+	case flags&objectCompressedZstd != 0:
+		return f.decoder.DecodeAll(payload, nil)
+	case flags&objectCompressedLZ4 != 0:
+		// The payload is prefixed with its decompressed size
+		if len(payload) < 8 {
+			return nil, fmt.Errorf("data at %d is truncated", offset)
+		}
+		// The size is checked against the size of the payload, so that a corrupt size cannot cause a large allocation
+		size := binary.LittleEndian.Uint64(payload[:8])
+		if size > uint64(len(payload)-8)*lz4MaxRatio {
+			return nil, fmt.Errorf("data at %d has invalid size %d", offset, size)
+		}
+		decompressed := make([]byte, size)
+		n, err := lz4.UncompressBlock(payload[8:], decompressed)
+		if err != nil {
+			return nil, fmt.Errorf("decompress data at %d: %s", offset, err)
+		}
+		return decompressed[:n], nil
+	case flags&objectCompressedXZ != 0:
+		// Only files that declare xz compression in their header may contain objects compressed with it
+		return nil, fmt.Errorf("data at %d is compressed with xz, which is not supported", offset)
+	}
+	return payload, nil
+}
+
+// cursor returns the cursor of the entry, in the format used by journalctl
+func (e *journalEntry) cursor() string {
+	return fmt.Sprintf("s=%x;i=%x;b=%x;m=%x;t=%x;x=%x", e.seqnumID, e.seqnum, e.bootID, e.monotonic, e.realtime, e.xorHash)
+}
+
+// body returns the fields of the entry, in the same form as the JSON output of journalctl
+func (e *journalEntry) body() map[string]interface{} {
+	values := make(map[string][]interface{}, len(e.data))
+	for _, data := range e.data {
+		i := bytes.IndexByte(data, '=')
+		if i < 1 {
+			continue
+		}
+		name := string(data[:i])
+		values[name] = append(values[name], fieldValue(data[i+1:]))
+	}
+
+	// Fields with multiple values are arrays
+	body := make(map[string]interface{}, len(values)+3)
+	for name, v := range values {
+		if len(v) == 1 {
+			body[name] = v[0]
+			continue
+		}
+		body[name] = v
+	}
+
+	body["__CURSOR"] = e.cursor()
+	body["__MONOTONIC_TIMESTAMP"] = fmt.Sprintf("%d", e.monotonic)
+	body["_BOOT_ID"] = fmt.Sprintf("%x", e.bootID)
+	return body
+}
+
+// fieldValue returns printable values as strings, and other values as arrays of bytes
+func fieldValue(value []byte) interface{} {
+	if printable(value) {
+		return string(value)
+	}
+
+	values := make([]interface{}, 0, len(value))
+	for _, b := range value {
+		values = append(values, float64(b))
+	}
+	return values
+}
+
+// printable returns true if a value is valid UTF-8 without control characters other than newlines and tabs
+func printable(value []byte) bool {
+	for len(value) > 0 {
+		r, size := utf8.DecodeRune(value)
+		if r == utf8.RuneError && size <= 1 {
+			return false
+		}
+		if (r < ' ' && r != '\n' && r != '\t') || (r >= 0x7f && r < 0xa0) {
+			return false
+		}
+		value = value[size:]
+	}
+	return true
+}
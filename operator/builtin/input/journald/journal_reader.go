@@ -0,0 +1,498 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/zap"
+)
+
+// defaultJournalDirectories are the directories journald writes to, unless other directories or files are configured
+var defaultJournalDirectories = []string{"/run/log/journal", "/var/log/journal"}
+
+// journalReader reads the entries of a set of journal files, merged in the order they were written
+type journalReader struct {
+	logger  *zap.SugaredLogger
+	find    func() ([]string, error)
+	filter  journalFilter
+	startAt string
+	since   uint64
+	cursor  *journalCursor
+	decoder *zstd.Decoder
+
+	files   []*journalFile
+	scanned bool
+
+	// skipped are the files that could not be read, which are not opened again until they change
+	skipped []skippedFile
+}
+
+// skippedFile is a journal file that could not be read
+type skippedFile struct {
+	info os.FileInfo
+	// seek is true if the file existed when the reader was created, so it is read from the start position
+	seek bool
+}
+
+// newJournalReader creates a reader of the journal files returned by find. Entries are read from
+// after the cursor if there is one, from the since time if it is set, and otherwise from the start
+// or end of the journal.
+func newJournalReader(logger *zap.SugaredLogger, find func() ([]string, error), filter journalFilter, startAt string, since time.Time, cursor []byte) (*journalReader, error) {
+	r := &journalReader{
+		logger:  logger,
+		find:    find,
+		filter:  filter,
+		startAt: startAt,
+	}
+	if !since.IsZero() {
+		r.since = uint64(since.UnixNano() / 1000)
+	}
+
+	if cursor != nil {
+		c, err := parseCursor(string(cursor))
+		if err != nil {
+			return nil, err
+		}
+		r.cursor = c
+	}
+
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	r.decoder = decoder
+	return r, nil
+}
+
+// findJournalFiles returns a function that lists the journal files of the configured directory or files.
+// The machine specific subdirectories of a directory are included.
+func findJournalFiles(directories, files []string) func() ([]string, error) {
+	return func() ([]string, error) {
+		paths := append([]string{}, files...)
+		for _, dir := range directories {
+			for _, pattern := range []string{"*.journal", "*/*.journal"} {
+				matches, err := filepath.Glob(filepath.Join(dir, pattern))
+				if err != nil {
+					return nil, err
+				}
+				paths = append(paths, matches...)
+			}
+		}
+		return paths, nil
+	}
+}
+
+// Read passes every entry written since the last read to emit, in order
+func (r *journalReader) Read(ctx context.Context, emit func(*journalEntry)) error {
+	if err := r.scan(); err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		var first *journalFile
+		for _, f := range r.files {
+			if f.next == nil {
+				e, err := r.readNext(f)
+				if err != nil {
+					return fmt.Errorf("read journal file %s: %s", f.path, err)
+				}
+				f.next = e
+			}
+			if f.next != nil && (first == nil || entryBefore(f.next, first.next)) {
+				first = f
+			}
+		}
+
+		if first == nil {
+			return nil
+		}
+		e := first.next
+		first.next = nil
+		emit(e)
+	}
+	return nil
+}
+
+// scan opens the journal files that have been created since the last scan, and closes the ones
+// that have been removed. Files are identified by their inode, so that renamed files are not read again.
+// A file that cannot be read, such as one that is compressed with xz, is skipped until it changes,
+// so that the other files are still read.
+func (r *journalReader) scan() error {
+	paths, err := r.find()
+	if err != nil {
+		return fmt.Errorf("find journal files: %s", err)
+	}
+
+	found := make(map[*journalFile]bool, len(paths))
+	skipped := make([]skippedFile, 0, len(r.skipped))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		var existing *journalFile
+		for _, f := range r.files {
+			if os.SameFile(info, f.info) {
+				existing = f
+				break
+			}
+		}
+		if existing != nil {
+			existing.path = path
+			found[existing] = true
+			continue
+		}
+		seek := !r.scanned
+		if s, ok := r.findSkipped(info); ok {
+			if s.unchanged(info) {
+				skipped = append(skipped, s)
+				continue
+			}
+			seek = s.seek
+		}
+
+		f, err := openJournalFile(path, r.decoder)
+		if err == nil && seek {
+			if err = r.seekStart(f); err != nil {
+				f.Close()
+				err = fmt.Errorf("read journal file %s: %s", path, err)
+			}
+		}
+		if err != nil {
+			r.logger.Warnw("Skipping journal file", "path", path, zap.Error(err))
+			skipped = append(skipped, skippedFile{info: info, seek: seek})
+			continue
+		}
+		r.files = append(r.files, f)
+		found[f] = true
+	}
+
+	files := r.files[:0]
+	for _, f := range r.files {
+		if !found[f] {
+			f.Close()
+			continue
+		}
+
+		// Archived files are not written to again. If the header cannot be read again, the entries
+		// of the previous header are read, and reading the header is retried by the next scan.
+		if f.header.state != stateArchived {
+			if err := f.readHeader(); err != nil {
+				r.logger.Warnw("Failed to read journal file header", "path", f.path, zap.Error(err))
+			}
+		}
+		files = append(files, f)
+	}
+	r.files = files
+	r.skipped = skipped
+	r.scanned = true
+	return nil
+}
+
+// findSkipped returns the skipped file with the same inode as a file, if there is one
+func (r *journalReader) findSkipped(info os.FileInfo) (skippedFile, bool) {
+	for _, s := range r.skipped {
+		if os.SameFile(info, s.info) {
+			return s, true
+		}
+	}
+	return skippedFile{}, false
+}
+
+// unchanged returns true if a skipped file has not been written to since it was skipped
+func (s skippedFile) unchanged(info os.FileInfo) bool {
+	return info.Size() == s.info.Size() && info.ModTime().Equal(s.info.ModTime())
+}
+
+// seekStart moves a file that existed when the reader was created to the first entry to be read
+func (r *journalReader) seekStart(f *journalFile) error {
+	switch {
+	case r.cursor != nil:
+		if f.header.seqnumID == r.cursor.seqnumID && f.header.tailSeqnum <= r.cursor.seqnum {
+			return f.seekEnd()
+		}
+		f.after = r.cursor
+	case r.since != 0:
+		if f.header.tailRealtime < r.since {
+			return f.seekEnd()
+		}
+	case r.startAt == "end":
+		return f.seekEnd()
+	}
+	return nil
+}
+
+// readNext returns the next entry of a file that matches the filter, or nil if there is none
+func (r *journalReader) readNext(f *journalFile) (*journalEntry, error) {
+	for {
+		offset, err := f.nextEntryOffset()
+		if err != nil || offset == 0 {
+			return nil, err
+		}
+
+		if f.after != nil {
+			e, err := f.readEntry(offset, false)
+			if err != nil {
+				return nil, err
+			}
+			if !entryBefore(f.after.entry(), e) {
+				continue
+			}
+			f.after = nil
+		}
+
+		e, err := f.readEntry(offset, true)
+		if err != nil {
+			return nil, err
+		}
+		if e.realtime >= r.since && r.filter.match(e) {
+			return e, nil
+		}
+	}
+}
+
+// Close closes every open journal file
+func (r *journalReader) Close() error {
+	for _, f := range r.files {
+		f.Close()
+	}
+	r.files = nil
+	r.decoder.Close()
+	return nil
+}
+
+// entryBefore returns true if a was written before b. Entries are ordered by sequence number
+// if they were written by the same journal, by monotonic time if they were written in the same boot,
+// and otherwise by wall clock time.
+func entryBefore(a, b *journalEntry) bool {
+	switch {
+	case a.seqnumID == b.seqnumID:
+		return a.seqnum < b.seqnum
+	case a.bootID == b.bootID:
+		return a.monotonic < b.monotonic
+	default:
+		return a.realtime < b.realtime
+	}
+}
+
+// journalCursor is the position of an entry, as returned in the __CURSOR field
+type journalCursor struct {
+	seqnumID  [16]byte
+	seqnum    uint64
+	bootID    [16]byte
+	monotonic uint64
+	realtime  uint64
+}
+
+// parseCursor parses a cursor in the format used by journalctl
+func parseCursor(cursor string) (*journalCursor, error) {
+	c := &journalCursor{}
+	found := 0
+	for _, part := range strings.Split(cursor, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid cursor '%s'", cursor)
+		}
+
+		var err error
+		switch kv[0] {
+		case "s":
+			err = parseID(kv[1], &c.seqnumID)
+		case "i":
+			c.seqnum, err = strconv.ParseUint(kv[1], 16, 64)
+		case "b":
+			err = parseID(kv[1], &c.bootID)
+		case "m":
+			c.monotonic, err = strconv.ParseUint(kv[1], 16, 64)
+		case "t":
+			c.realtime, err = strconv.ParseUint(kv[1], 16, 64)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor '%s': %s", cursor, err)
+		}
+		found++
+	}
+
+	if found != 5 {
+		return nil, fmt.Errorf("invalid cursor '%s'", cursor)
+	}
+	return c, nil
+}
+
+func parseID(s string, id *[16]byte) error {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != len(id) {
+		return fmt.Errorf("invalid id '%s'", s)
+	}
+	copy(id[:], b)
+	return nil
+}
+
+// entry returns an entry at the position of the cursor, to compare with other entries
+func (c *journalCursor) entry() *journalEntry {
+	return &journalEntry{
+		seqnumID:  c.seqnumID,
+		seqnum:    c.seqnum,
+		bootID:    c.bootID,
+		monotonic: c.monotonic,
+		realtime:  c.realtime,
+	}
+}
+
+// journalMatch is a set of fields, each of which an entry must have with the given value
+type journalMatch map[string]string
+
+// journalCondition is satisfied by an entry that satisfies any of its matches
+type journalCondition []journalMatch
+
+// journalFilter is satisfied by an entry that satisfies all of its conditions
+type journalFilter []journalCondition
+
+// match returns true if an entry satisfies the filter
+func (f journalFilter) match(e *journalEntry) bool {
+	if len(f) == 0 {
+		return true
+	}
+
+	data := make(map[string]struct{}, len(e.data))
+	for _, d := range e.data {
+		data[string(d)] = struct{}{}
+	}
+
+	for _, condition := range f {
+		if !condition.match(data) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c journalCondition) match(data map[string]struct{}) bool {
+	for _, m := range c {
+		if m.match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m journalMatch) match(data map[string]struct{}) bool {
+	for field, value := range m {
+		if _, ok := data[field+"="+value]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldCondition matches the entries that have a field with any of the values
+func fieldCondition(field string, values []string) journalCondition {
+	condition := make(journalCondition, 0, len(values))
+	for _, value := range values {
+		condition = append(condition, journalMatch{field: value})
+	}
+	return condition
+}
+
+// unitSuffixes are the types of systemd units
+var unitSuffixes = []string{
+	".service", ".socket", ".target", ".device", ".mount", ".automount",
+	".swap", ".timer", ".path", ".slice", ".scope",
+}
+
+// unitCondition matches the entries of any of the units, including the messages systemd logs about them,
+// like journalctl --unit. Units without a type are assumed to be services.
+func unitCondition(units []string) journalCondition {
+	condition := make(journalCondition, 0, 4*len(units))
+	for _, unit := range units {
+		if !hasUnitSuffix(unit) {
+			unit += ".service"
+		}
+		condition = append(condition,
+			journalMatch{"_SYSTEMD_UNIT": unit},
+			journalMatch{"UNIT": unit, "_PID": "1"},
+			journalMatch{"COREDUMP_UNIT": unit, "_UID": "0"},
+			journalMatch{"OBJECT_SYSTEMD_UNIT": unit, "_UID": "0"},
+		)
+	}
+	return condition
+}
+
+func hasUnitSuffix(unit string) bool {
+	for _, suffix := range unitSuffixes {
+		if strings.HasSuffix(unit, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// priorities are the names of the syslog priorities, by value
+var priorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// priorityCondition matches the entries with a priority in a range, like journalctl --priority.
+// The priority is either a single priority, which matches it and every more important priority,
+// or a range in the form 'FROM..TO'.
+func priorityCondition(priority string) (journalCondition, error) {
+	from, to := "emerg", priority
+	if i := strings.Index(priority, ".."); i >= 0 {
+		from, to = priority[:i], priority[i+2:]
+	}
+
+	min, err := parsePriority(from)
+	if err != nil {
+		return nil, err
+	}
+	max, err := parsePriority(to)
+	if err != nil {
+		return nil, err
+	}
+	if min > max {
+		min, max = max, min
+	}
+
+	condition := make(journalCondition, 0, max-min+1)
+	for p := min; p <= max; p++ {
+		condition = append(condition, journalMatch{"PRIORITY": strconv.Itoa(p)})
+	}
+	return condition, nil
+}
+
+func parsePriority(priority string) (int, error) {
+	for i, name := range priorities {
+		if priority == name || priority == strconv.Itoa(i) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid priority '%s'", priority)
+}
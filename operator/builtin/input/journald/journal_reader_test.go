@@ -0,0 +1,365 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// The journal files in testdata were written by systemd-journald 252, and the JSON files
+// next to them are the output of `journalctl --file <file> --output=json`. The compact file
+// uses the compact format, and the regular file does not. Both include a compressed field.
+var testJournalFiles = []string{"compact", "regular"}
+
+// readJournalctlOutput reads the bodies journalctl returned for a journal file, without the realtime timestamp
+func readJournalctlOutput(t *testing.T, name string) []map[string]interface{} {
+	file, err := os.Open(filepath.Join("testdata", name+".json"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	bodies := []map[string]interface{}{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &body))
+		delete(body, "__REALTIME_TIMESTAMP")
+		bodies = append(bodies, body)
+	}
+	require.NoError(t, scanner.Err())
+	return bodies
+}
+
+// readAll returns the bodies of the entries read from the journal
+func readAll(t *testing.T, r *journalReader) []map[string]interface{} {
+	bodies := []map[string]interface{}{}
+	err := r.Read(context.Background(), func(e *journalEntry) {
+		bodies = append(bodies, e.body())
+	})
+	require.NoError(t, err)
+	return bodies
+}
+
+func cursors(bodies []map[string]interface{}) []string {
+	result := make([]string, 0, len(bodies))
+	for _, body := range bodies {
+		result = append(result, body["__CURSOR"].(string))
+	}
+	return result
+}
+
+func TestJournalReaderMatchesJournalctl(t *testing.T) {
+	for _, name := range testJournalFiles {
+		t.Run(name, func(t *testing.T) {
+			find := findJournalFiles(nil, []string{filepath.Join("testdata", name+".journal")})
+			r, err := newJournalReader(zaptest.NewLogger(t).Sugar(), find, nil, "beginning", time.Time{}, nil)
+			require.NoError(t, err)
+			defer r.Close()
+
+			require.Equal(t, readJournalctlOutput(t, name), readAll(t, r))
+		})
+	}
+}
+
+func TestJournalReaderCursor(t *testing.T) {
+	compact := readJournalctlOutput(t, "compact")
+	regular := readJournalctlOutput(t, "regular")
+
+	cases := []struct {
+		name     string
+		files    []string
+		cursor   string
+		expected []map[string]interface{}
+	}{
+		{
+			name:     "SameFile",
+			files:    []string{"compact"},
+			cursor:   compact[3]["__CURSOR"].(string),
+			expected: compact[4:],
+		},
+		{
+			name:     "LastEntry",
+			files:    []string{"compact"},
+			cursor:   compact[7]["__CURSOR"].(string),
+			expected: []map[string]interface{}{},
+		},
+		{
+			// The files were written by different journals in the same boot, so the cursor
+			// of one is compared with the entries of the other by monotonic time
+			name:     "OtherJournal",
+			files:    []string{"regular"},
+			cursor:   compact[7]["__CURSOR"].(string),
+			expected: regular,
+		},
+		{
+			name:     "Merged",
+			files:    []string{"regular", "compact"},
+			cursor:   compact[5]["__CURSOR"].(string),
+			expected: append(compact[6:], regular...),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			files := []string{}
+			for _, name := range tc.files {
+				files = append(files, filepath.Join("testdata", name+".journal"))
+			}
+			r, err := newJournalReader(zaptest.NewLogger(t).Sugar(), findJournalFiles(nil, files), nil, "end", time.Time{}, []byte(tc.cursor))
+			require.NoError(t, err)
+			defer r.Close()
+
+			require.Equal(t, cursors(tc.expected), cursors(readAll(t, r)))
+		})
+	}
+}
+
+func TestJournalReaderSince(t *testing.T) {
+	compact := readJournalctlOutput(t, "compact")
+	regular := readJournalctlOutput(t, "regular")
+	since := time.Unix(0, 1792155661956625*1000)
+
+	files := []string{filepath.Join("testdata", "compact.journal"), filepath.Join("testdata", "regular.journal")}
+	cases := []struct {
+		name     string
+		since    time.Time
+		cursor   string
+		expected []map[string]interface{}
+	}{
+		{"Since", since.Add(time.Second), "", append(compact[4:], regular...)},
+		{"BeforeJournal", since.Add(-time.Hour), "", append(compact, regular...)},
+		{"AfterJournal", since.Add(time.Hour), "", []map[string]interface{}{}},
+		{"CursorFirst", since, regular[5]["__CURSOR"].(string), regular[6:]},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var cursor []byte
+			if tc.cursor != "" {
+				cursor = []byte(tc.cursor)
+			}
+			r, err := newJournalReader(zaptest.NewLogger(t).Sugar(), findJournalFiles(nil, files), nil, "end", tc.since, cursor)
+			require.NoError(t, err)
+			defer r.Close()
+
+			require.Equal(t, cursors(tc.expected), cursors(readAll(t, r)))
+		})
+	}
+}
+
+func TestJournalReaderInvalidCursor(t *testing.T) {
+	for _, cursor := range []string{"invalid", "s=123;i=1", "s=zz;i=1;b=2eb56a9fc9ee4b14975e64ac1d926ad9;m=1;t=1"} {
+		_, err := newJournalReader(zaptest.NewLogger(t).Sugar(), findJournalFiles(nil, nil), nil, "end", time.Time{}, []byte(cursor))
+		require.Error(t, err, cursor)
+	}
+}
+
+func TestJournalReaderDirectory(t *testing.T) {
+	dir := testutil.NewTempDir(t)
+	copyJournalFile(t, "compact", filepath.Join(dir, "system.journal"))
+
+	r, err := newJournalReader(zaptest.NewLogger(t).Sugar(), findJournalFiles([]string{dir}, nil), nil, "end", time.Time{}, nil)
+	require.NoError(t, err)
+	defer r.Close()
+
+	// Entries that were written before the reader started are skipped
+	require.Empty(t, readAll(t, r))
+
+	// A renamed file is not read again
+	require.NoError(t, os.Rename(filepath.Join(dir, "system.journal"), filepath.Join(dir, "system@archived.journal")))
+	require.Empty(t, readAll(t, r))
+
+	// Files created after the reader started are read from the beginning, including machine subdirectories
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "machine"), 0755))
+	copyJournalFile(t, "regular", filepath.Join(dir, "machine", "system.journal"))
+	require.Equal(t, cursors(readJournalctlOutput(t, "regular")), cursors(readAll(t, r)))
+	require.Empty(t, readAll(t, r))
+}
+
+func TestJournalReaderFollow(t *testing.T) {
+	bodies := readJournalctlOutput(t, "compact")
+	path := filepath.Join(testutil.NewTempDir(t), "system.journal")
+	copyJournalFile(t, "compact", path)
+
+	// Find the position of the last entry in the entry arrays
+	f, err := openJournalFile(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, f.seekEnd())
+	item := int64(f.arrayOffset + entryArrayItemsOffset + (f.arrayIndex-1)*4)
+	require.NoError(t, f.Close())
+
+	// Remove the last entry from the entry array, as if it had not been written yet
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	require.NoError(t, err)
+	defer file.Close()
+	last := make([]byte, 4)
+	_, err = file.ReadAt(last, item)
+	require.NoError(t, err)
+	_, err = file.WriteAt(make([]byte, 4), item)
+	require.NoError(t, err)
+
+	r, err := newJournalReader(zaptest.NewLogger(t).Sugar(), findJournalFiles(nil, []string{path}), nil, "beginning", time.Time{}, nil)
+	require.NoError(t, err)
+	defer r.Close()
+	require.Equal(t, cursors(bodies[:7]), cursors(readAll(t, r)))
+	require.Empty(t, readAll(t, r))
+
+	_, err = file.WriteAt(last, item)
+	require.NoError(t, err)
+	require.Equal(t, cursors(bodies[7:]), cursors(readAll(t, r)))
+}
+
+func copyJournalFile(t *testing.T, name, path string) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name+".journal"))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+}
+
+func TestJournalReaderSkipsUnreadableFiles(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "regular.journal"))
+	require.NoError(t, err)
+	binary.LittleEndian.PutUint32(data[12:16], binary.LittleEndian.Uint32(data[12:16])|incompatibleCompressedXZ)
+
+	dir := testutil.NewTempDir(t)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "xz.journal"), data, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "truncated.journal"), []byte("LPKSHHRH"), 0600))
+	copyJournalFile(t, "compact", filepath.Join(dir, "system.journal"))
+
+	r, err := newJournalReader(zaptest.NewLogger(t).Sugar(), findJournalFiles([]string{dir}, nil), nil, "beginning", time.Time{}, nil)
+	require.NoError(t, err)
+	defer r.Close()
+
+	// The unreadable files are skipped, and are not opened again while they are unchanged
+	require.Equal(t, cursors(readJournalctlOutput(t, "compact")), cursors(readAll(t, r)))
+	require.Len(t, r.skipped, 2)
+	require.Empty(t, readAll(t, r))
+	require.Len(t, r.files, 1)
+}
+
+func TestJournalReaderFilter(t *testing.T) {
+	bodies := readJournalctlOutput(t, "compact")
+	priority, err := priorityCondition("warning")
+	require.NoError(t, err)
+
+	find := findJournalFiles(nil, []string{filepath.Join("testdata", "compact.journal")})
+	r, err := newJournalReader(zaptest.NewLogger(t).Sugar(), find, journalFilter{priority}, "beginning", time.Time{}, nil)
+	require.NoError(t, err)
+	defer r.Close()
+
+	require.Equal(t, []map[string]interface{}{bodies[4]}, readAll(t, r))
+}
+
+func TestJournalFilter(t *testing.T) {
+	priority := func(p string) journalCondition {
+		c, err := priorityCondition(p)
+		require.NoError(t, err)
+		return c
+	}
+
+	cases := []struct {
+		name     string
+		filter   journalFilter
+		data     []string
+		expected bool
+	}{
+		{"Empty", journalFilter{}, []string{"MESSAGE=test"}, true},
+		{"Field", journalFilter{fieldCondition("SYSLOG_IDENTIFIER", []string{"sshd", "cron"})}, []string{"SYSLOG_IDENTIFIER=cron"}, true},
+		{"OtherField", journalFilter{fieldCondition("SYSLOG_IDENTIFIER", []string{"sshd"})}, []string{"_COMM=sshd"}, false},
+		{"MatchAllFields", journalFilter{{{"_COMM": "sshd", "_TRANSPORT": "stdout"}}}, []string{"_COMM=sshd", "_TRANSPORT=stdout"}, true},
+		{"MatchSomeFields", journalFilter{{{"_COMM": "sshd", "_TRANSPORT": "stdout"}}}, []string{"_COMM=sshd", "_TRANSPORT=syslog"}, false},
+		{"AnyMatch", journalFilter{{{"_COMM": "sshd"}, {"_TRANSPORT": "kernel"}}}, []string{"_COMM=cron", "_TRANSPORT=kernel"}, true},
+		{"Unit", journalFilter{unitCondition([]string{"ssh"})}, []string{"_SYSTEMD_UNIT=ssh.service"}, true},
+		{"UnitWithSuffix", journalFilter{unitCondition([]string{"docker.socket"})}, []string{"_SYSTEMD_UNIT=docker.socket"}, true},
+		{"OtherUnit", journalFilter{unitCondition([]string{"ssh", "cron"})}, []string{"_SYSTEMD_UNIT=kubelet.service"}, false},
+		{"UnitFromSystemd", journalFilter{unitCondition([]string{"ssh"})}, []string{"UNIT=ssh.service", "_PID=1"}, true},
+		{"UnitFromOtherProcess", journalFilter{unitCondition([]string{"ssh"})}, []string{"UNIT=ssh.service", "_PID=2"}, false},
+		{"PriorityAtMost", journalFilter{priority("info")}, []string{"PRIORITY=3"}, true},
+		{"PriorityTooLow", journalFilter{priority("info")}, []string{"PRIORITY=7"}, false},
+		{"PriorityMissing", journalFilter{priority("info")}, []string{"MESSAGE=test"}, false},
+		{"PriorityNumber", journalFilter{priority("3")}, []string{"PRIORITY=3"}, true},
+		{"PriorityRange", journalFilter{priority("err..warning")}, []string{"PRIORITY=4"}, true},
+		{"PriorityOutOfRange", journalFilter{priority("err..warning")}, []string{"PRIORITY=2"}, false},
+		{"UnitAndPriority", journalFilter{unitCondition([]string{"ssh"}), priority("info")}, []string{"_SYSTEMD_UNIT=ssh.service", "PRIORITY=6"}, true},
+		{"UnitButNotPriority", journalFilter{unitCondition([]string{"ssh"}), priority("info")}, []string{"_SYSTEMD_UNIT=ssh.service", "PRIORITY=7"}, false},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			e := &journalEntry{}
+			for _, d := range tc.data {
+				e.data = append(e.data, []byte(d))
+			}
+			require.Equal(t, tc.expected, tc.filter.match(e))
+		})
+	}
+}
+
+func TestPriorityConditionInvalid(t *testing.T) {
+	for _, p := range []string{"", "8", "information", "info..", "..info"} {
+		_, err := priorityCondition(p)
+		require.Error(t, err, p)
+	}
+}
+
+func TestJournalFileXZUnsupported(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "regular.journal"))
+	require.NoError(t, err)
+	binary.LittleEndian.PutUint32(data[12:16], binary.LittleEndian.Uint32(data[12:16])|incompatibleCompressedXZ)
+
+	path := filepath.Join(testutil.NewTempDir(t), "xz.journal")
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+
+	_, err = openJournalFile(path, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "xz")
+}
+
+func TestJournalFileLZ4InvalidSize(t *testing.T) {
+	// A data object whose lz4 payload claims a decompressed size far larger than lz4 can produce
+	object := make([]byte, dataPayloadOffset+16)
+	object[0] = objectData
+	object[1] = objectCompressedLZ4
+	binary.LittleEndian.PutUint64(object[8:16], uint64(len(object)))
+	binary.LittleEndian.PutUint64(object[dataPayloadOffset:], 1<<29)
+
+	path := filepath.Join(testutil.NewTempDir(t), "lz4.journal")
+	require.NoError(t, ioutil.WriteFile(path, object, 0600))
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	f := &journalFile{path: path, file: file}
+	_, err = f.readData(0)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid size")
+}
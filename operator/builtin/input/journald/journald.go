@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"regexp"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -36,6 +38,13 @@ import (
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 )
 
+const (
+	// JournalctlMode reads the journal by running journalctl
+	JournalctlMode = "journalctl"
+	// NativeMode reads the journal files directly
+	NativeMode = "native"
+)
+
 func init() {
 	operator.Register("journald_input", func() operator.Builder { return NewJournaldInputConfig("") })
 }
@@ -52,11 +61,19 @@ func NewJournaldInputConfig(operatorID string) *JournaldInputConfig {
 type JournaldInputConfig struct {
 	helper.InputConfig `mapstructure:",squash" yaml:",inline"`
 
-	Directory *string  `mapstructure:"directory,omitempty" json:"directory,omitempty" yaml:"directory,omitempty"`
-	Files     []string `mapstructure:"files,omitempty"     json:"files,omitempty"     yaml:"files,omitempty"`
-	StartAt   string   `mapstructure:"start_at,omitempty"  json:"start_at,omitempty"  yaml:"start_at,omitempty"`
-	Units     []string `mapstructure:"units,omitempty"     json:"units,omitempty"     yaml:"units,omitempty"`
-	Priority  string   `mapstructure:"priority,omitempty"  json:"priority,omitempty"  yaml:"priority,omitempty"`
+	Mode        string              `mapstructure:"mode,omitempty"         json:"mode,omitempty"         yaml:"mode,omitempty"`
+	Directory   *string             `mapstructure:"directory,omitempty"    json:"directory,omitempty"    yaml:"directory,omitempty"`
+	Files       []string            `mapstructure:"files,omitempty"        json:"files,omitempty"        yaml:"files,omitempty"`
+	StartAt     string              `mapstructure:"start_at,omitempty"     json:"start_at,omitempty"     yaml:"start_at,omitempty"`
+	Units       []string            `mapstructure:"units,omitempty"        json:"units,omitempty"        yaml:"units,omitempty"`
+	Identifiers []string            `mapstructure:"identifiers,omitempty"  json:"identifiers,omitempty"  yaml:"identifiers,omitempty"`
+	Matches     []map[string]string `mapstructure:"matches,omitempty"      json:"matches,omitempty"      yaml:"matches,omitempty"`
+	Priority    string              `mapstructure:"priority,omitempty"     json:"priority,omitempty"     yaml:"priority,omitempty"`
+	Boot        string              `mapstructure:"boot,omitempty"         json:"boot,omitempty"         yaml:"boot,omitempty"`
+	Dmesg       bool                `mapstructure:"dmesg,omitempty"        json:"dmesg,omitempty"        yaml:"dmesg,omitempty"`
+	Namespace   string              `mapstructure:"namespace,omitempty"    json:"namespace,omitempty"    yaml:"namespace,omitempty"`
+	Since       string              `mapstructure:"since,omitempty"        json:"since,omitempty"        yaml:"since,omitempty"`
+	WaitForAck  bool                `mapstructure:"wait_for_ack,omitempty" json:"wait_for_ack,omitempty" yaml:"wait_for_ack,omitempty"`
 }
 
 // Build will build a journald input operator from the supplied configuration
@@ -66,6 +83,41 @@ func (c JournaldInputConfig) Build(buildContext operator.BuildContext) ([]operat
 		return nil, err
 	}
 
+	for _, match := range c.Matches {
+		if len(match) == 0 {
+			return nil, fmt.Errorf("invalid value for parameter 'matches': a match must have at least one field")
+		}
+		for field := range match {
+			if !journalFieldName.MatchString(field) {
+				return nil, fmt.Errorf("invalid field '%s' for parameter 'matches'", field)
+			}
+		}
+	}
+
+	if c.Boot != "" && !bootID.MatchString(c.Boot) {
+		return nil, fmt.Errorf("invalid value '%s' for parameter 'boot'", c.Boot)
+	}
+
+	if c.Namespace != "" && !namespaceName.MatchString(c.Namespace) {
+		return nil, fmt.Errorf("invalid value '%s' for parameter 'namespace'", c.Namespace)
+	}
+
+	var since time.Time
+	if c.Since != "" {
+		since, err = time.Parse(time.RFC3339, c.Since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for parameter 'since': %s", c.Since, err)
+		}
+	}
+
+	switch c.Mode {
+	case "", JournalctlMode:
+	case NativeMode:
+		return c.buildNative(inputOperator, since)
+	default:
+		return nil, fmt.Errorf("invalid value '%s' for parameter 'mode'", c.Mode)
+	}
+
 	args := make([]string, 0, 10)
 
 	// Export logs in UTC time
@@ -89,8 +141,28 @@ func (c JournaldInputConfig) Build(buildContext operator.BuildContext) ([]operat
 		args = append(args, "--unit", unit)
 	}
 
+	for _, identifier := range c.Identifiers {
+		args = append(args, "--identifier", identifier)
+	}
+
 	args = append(args, "--priority", c.Priority)
 
+	if c.Boot != "" {
+		args = append(args, "--boot="+c.Boot)
+	}
+
+	if c.Dmesg {
+		args = append(args, "--dmesg")
+	}
+
+	if c.Namespace != "" {
+		args = append(args, "--namespace", c.Namespace)
+	}
+
+	if !since.IsZero() {
+		args = append(args, "--since", since.UTC().Format(journalctlTimeLayout))
+	}
+
 	switch {
 	case c.Directory != nil:
 		args = append(args, "--directory", *c.Directory)
@@ -100,16 +172,95 @@ func (c JournaldInputConfig) Build(buildContext operator.BuildContext) ([]operat
 		}
 	}
 
+	// Matches are passed last. The fields of a match must all match, and matches are separated by '+',
+	// so that an entry must satisfy any of them.
+	for i, match := range c.Matches {
+		if i > 0 {
+			args = append(args, "+")
+		}
+		fields := make([]string, 0, len(match))
+		for field := range match {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			args = append(args, field+"="+match[field])
+		}
+	}
+
 	journaldInput := &JournaldInput{
 		InputOperator: inputOperator,
 		newCmd: func(ctx context.Context, cursor []byte) cmd {
+			// journalctl is started again after a failed entry, so the arguments are copied
+			cmdArgs := append([]string{}, args...)
 			if cursor != nil {
-				args = append(args, "--after-cursor", string(cursor))
+				cmdArgs = append(cmdArgs, "--after-cursor", string(cursor))
 			}
-			return exec.CommandContext(ctx, "journalctl", args...) // #nosec - ...
+			return exec.CommandContext(ctx, "journalctl", cmdArgs...) // #nosec - ...
 			// journalctl is an executable that is required for this operator to function
 		},
-		json: jsoniter.ConfigFastest,
+		json:         jsoniter.ConfigFastest,
+		pollInterval: defaultPollInterval,
+		waitForAck:   c.WaitForAck,
+	}
+	return []operator.Operator{journaldInput}, nil
+}
+
+// buildNative builds a journald input operator that reads the journal files directly
+func (c JournaldInputConfig) buildNative(inputOperator helper.InputOperator, since time.Time) ([]operator.Operator, error) {
+	switch c.StartAt {
+	case "end", "beginning":
+	default:
+		return nil, fmt.Errorf("invalid value '%s' for parameter 'start_at'", c.StartAt)
+	}
+
+	// Selecting boots requires the list of boots, and namespaces are only known to journald
+	if c.Boot != "" {
+		return nil, fmt.Errorf("parameter 'boot' is not supported in native mode")
+	}
+	if c.Namespace != "" {
+		return nil, fmt.Errorf("parameter 'namespace' is not supported in native mode")
+	}
+
+	filter := journalFilter{}
+	if len(c.Units) > 0 {
+		filter = append(filter, unitCondition(c.Units))
+	}
+	if len(c.Identifiers) > 0 {
+		filter = append(filter, fieldCondition("SYSLOG_IDENTIFIER", c.Identifiers))
+	}
+	if c.Dmesg {
+		filter = append(filter, fieldCondition("_TRANSPORT", []string{"kernel"}))
+	}
+	if len(c.Matches) > 0 {
+		matches := make(journalCondition, 0, len(c.Matches))
+		for _, match := range c.Matches {
+			matches = append(matches, journalMatch(match))
+		}
+		filter = append(filter, matches)
+	}
+	priority, err := priorityCondition(c.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value '%s' for parameter 'priority'", c.Priority)
+	}
+	filter = append(filter, priority)
+
+	directories := defaultJournalDirectories
+	switch {
+	case c.Directory != nil:
+		directories = []string{*c.Directory}
+	case len(c.Files) > 0:
+		directories = nil
+	}
+	find := findJournalFiles(directories, c.Files)
+
+	journaldInput := &JournaldInput{
+		InputOperator: inputOperator,
+		newReader: func(cursor []byte) (*journalReader, error) {
+			return newJournalReader(inputOperator.SugaredLogger, find, filter, c.StartAt, since, cursor)
+		},
+		pollInterval: defaultPollInterval,
+		waitForAck:   c.WaitForAck,
 	}
 	return []operator.Operator{journaldInput}, nil
 }
@@ -120,10 +271,17 @@ type JournaldInput struct {
 
 	newCmd func(ctx context.Context, cursor []byte) cmd
 
-	persister operator.Persister
-	json      jsoniter.API
-	cancel    context.CancelFunc
-	wg        sync.WaitGroup
+	// newReader is set instead of newCmd to read the journal files directly
+	newReader func(cursor []byte) (*journalReader, error)
+
+	// pollInterval is how often the journal files are read, and how often failed entries are checked for
+	pollInterval time.Duration
+
+	persister  operator.Persister
+	json       jsoniter.API
+	waitForAck bool
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
 }
 
 type cmd interface {
@@ -133,6 +291,22 @@ type cmd interface {
 
 var lastReadCursorKey = "lastReadCursor"
 
+var (
+	// journalFieldName matches the names of the fields of journal entries
+	journalFieldName = regexp.MustCompile(`^[A-Z_][A-Z0-9_]{0,63}$`)
+	// bootID matches a boot ID, optionally followed by an offset, or an offset from the current boot
+	bootID = regexp.MustCompile(`^([0-9a-f]{32}([+-][0-9]+)?|[+-]?[0-9]+)$`)
+	// namespaceName matches a journal namespace, '+' followed by a namespace to include the default namespace,
+	// or '*' for every namespace
+	namespaceName = regexp.MustCompile(`^(\*|\+?[A-Za-z0-9_.-]+)$`)
+)
+
+// journalctlTimeLayout is a timestamp format accepted by journalctl
+const journalctlTimeLayout = "2006-01-02 15:04:05.000000 UTC"
+
+// defaultPollInterval is how often journal files are checked for new entries when reading them directly
+const defaultPollInterval = 200 * time.Millisecond
+
 // Start will start generating log entries.
 func (operator *JournaldInput) Start(persister operator.Persister) error {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -146,31 +320,65 @@ func (operator *JournaldInput) Start(persister operator.Persister) error {
 
 	operator.persister = persister
 
-	// Start journalctl
-	cmd := operator.newCmd(ctx, cursor)
+	acks := operator.newCheckpointTracker(cursor)
+	if operator.newReader != nil {
+		return operator.startNative(ctx, cursor, acks)
+	}
+	return operator.startJournalctl(ctx, cursor, acks)
+}
+
+// startJournalctl starts journalctl from after the cursor, and writes the entries it prints until it exits.
+// If an entry fails to be delivered, journalctl is stopped and started again from the last delivered entry.
+func (operator *JournaldInput) startJournalctl(ctx context.Context, cursor []byte, acks *helper.CheckpointTracker) error {
+	cmdCtx, cancel := context.WithCancel(ctx)
+	cmd := operator.newCmd(cmdCtx, cursor)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
+		cancel()
 		return fmt.Errorf("failed to get journalctl stdout: %s", err)
 	}
 	err = cmd.Start()
 	if err != nil {
+		cancel()
 		return fmt.Errorf("start journalctl: %s", err)
 	}
 
+	// Stop journalctl as soon as an entry fails, since it may not print anything else for a while
+	if acks != nil {
+		operator.wg.Add(1)
+		go func() {
+			defer operator.wg.Done()
+			ticker := time.NewTicker(operator.pollInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-cmdCtx.Done():
+					return
+				case <-ticker.C:
+					if acks.Stalled() {
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+	}
+
 	// Start the reader goroutine
 	operator.wg.Add(1)
 	go func() {
 		defer operator.wg.Done()
+		defer cancel()
 
 		stdoutBuf := bufio.NewReader(stdout)
-
-		for {
+		for acks == nil || !acks.Stalled() {
 			line, err := stdoutBuf.ReadBytes('\n')
 			if err != nil {
-				if err != io.EOF {
+				if err != io.EOF && cmdCtx.Err() == nil {
 					operator.Errorw("Received error reading from journalctl stdout", zap.Error(err))
 				}
-				return
+				break
 			}
 
 			entry, cursor, err := operator.parseJournalEntry(line)
@@ -178,16 +386,118 @@ func (operator *JournaldInput) Start(persister operator.Persister) error {
 				operator.Warnw("Failed to parse journal entry", zap.Error(err))
 				continue
 			}
-			if err := operator.persister.Set(ctx, lastReadCursorKey, []byte(cursor)); err != nil {
-				operator.Warnw("Failed to set offset", zap.Error(err))
+			operator.write(ctx, acks, entry, cursor)
+		}
+
+		if ctx.Err() != nil || acks == nil || !acks.Stalled() {
+			return
+		}
+		cancel()
+		cursor := checkpointCursor(acks.Rewind())
+		operator.Debugw("Restarting journalctl from the last delivered entry", "cursor", string(cursor))
+		if err := operator.startJournalctl(ctx, cursor, acks); err != nil {
+			operator.Errorw("Failed to restart journalctl", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+// startNative starts reading the journal files directly, checking them for new entries every poll interval.
+// If an entry fails to be delivered, the journal files are opened again from the last delivered entry.
+func (operator *JournaldInput) startNative(ctx context.Context, cursor []byte, acks *helper.CheckpointTracker) error {
+	reader, err := operator.newReader(cursor)
+	if err != nil {
+		return fmt.Errorf("open journal: %s", err)
+	}
+
+	operator.wg.Add(1)
+	go func() {
+		defer operator.wg.Done()
+		defer func() { reader.Close() }()
+
+		ticker := time.NewTicker(operator.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			if acks != nil && acks.Stalled() {
+				cursor := checkpointCursor(acks.Rewind())
+				operator.Debugw("Reading again from the last delivered entry", "cursor", string(cursor))
+				rewound, err := operator.newReader(cursor)
+				if err != nil {
+					operator.Errorw("Failed to open journal", zap.Error(err))
+					return
+				}
+				reader.Close()
+				reader = rewound
+			}
+
+			err := reader.Read(ctx, func(journalEntry *journalEntry) {
+				// The entries after a failed entry are read again with it
+				if acks != nil && acks.Stalled() {
+					return
+				}
+				entry, err := operator.NewEntry(journalEntry.body())
+				if err != nil {
+					operator.Warnw("Failed to create entry", zap.Error(err))
+					return
+				}
+				entry.Timestamp = time.Unix(0, int64(journalEntry.realtime)*1000) // in microseconds
+				operator.write(ctx, acks, entry, journalEntry.cursor())
+			})
+			if err != nil {
+				operator.Warnw("Failed to read journal", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
 			}
-			operator.Write(ctx, entry)
 		}
 	}()
 
 	return nil
 }
 
+// newCheckpointTracker returns a tracker of the acknowledged cursors if waiting for acknowledgements.
+// The cursor is then only saved once the entry at that cursor and every entry before it have been delivered.
+func (operator *JournaldInput) newCheckpointTracker(cursor []byte) *helper.CheckpointTracker {
+	if !operator.waitForAck {
+		return nil
+	}
+	var start interface{}
+	if cursor != nil {
+		start = string(cursor)
+	}
+	return helper.NewCheckpointTracker(start, func(checkpoint interface{}) {
+		// Entries may be acknowledged after the operator has stopped
+		if err := operator.persister.Set(context.Background(), lastReadCursorKey, []byte(checkpoint.(string))); err != nil {
+			operator.Warnw("Failed to set offset", zap.Error(err))
+		}
+	})
+}
+
+// checkpointCursor returns the cursor of a checkpoint, which is nil if no entry has been delivered yet
+func checkpointCursor(checkpoint interface{}) []byte {
+	if checkpoint == nil {
+		return nil
+	}
+	return []byte(checkpoint.(string))
+}
+
+// write saves the cursor of an entry, unless it is tracked until the entry is acknowledged, and writes the entry
+func (operator *JournaldInput) write(ctx context.Context, acks *helper.CheckpointTracker, entry *entry.Entry, cursor string) {
+	if acks != nil {
+		operator.Write(acks.Track(ctx, cursor), entry)
+		return
+	}
+	if err := operator.persister.Set(ctx, lastReadCursorKey, []byte(cursor)); err != nil {
+		operator.Warnw("Failed to set offset", zap.Error(err))
+	}
+	operator.Write(ctx, entry)
+}
+
 func (operator *JournaldInput) parseJournalEntry(line []byte) (*entry.Entry, string, error) {
 	var body map[string]interface{}
 	err := operator.json.Unmarshal(line, &body)
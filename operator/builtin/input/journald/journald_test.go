@@ -22,6 +22,8 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"os/exec"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -136,3 +138,347 @@ func TestJournaldInputConfig(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expect, &actual)
 }
+
+func TestJournaldInputConfigFilters(t *testing.T) {
+	expect := NewJournaldInputConfig("my_journald_input")
+	expect.Identifiers = []string{"sshd"}
+	expect.Matches = []map[string]string{
+		{"_SYSTEMD_UNIT": "ssh.service", "_TRANSPORT": "stdout"},
+		{"_COMM": "cron"},
+	}
+	expect.Boot = "-1"
+	expect.Dmesg = true
+	expect.Namespace = "app"
+	expect.Since = "2022-01-01T00:00:00Z"
+
+	input := map[string]interface{}{
+		"id":          "my_journald_input",
+		"type":        "journald_input",
+		"write_to":    "$body",
+		"priority":    "info",
+		"start_at":    "end",
+		"identifiers": []interface{}{"sshd"},
+		"matches": []interface{}{
+			map[string]interface{}{"_SYSTEMD_UNIT": "ssh.service", "_TRANSPORT": "stdout"},
+			map[string]interface{}{"_COMM": "cron"},
+		},
+		"boot":       "-1",
+		"dmesg":      true,
+		"namespace":  "app",
+		"since":      "2022-01-01T00:00:00Z",
+		"attributes": map[string]interface{}{},
+		"resource":   map[string]interface{}{},
+	}
+
+	var actual JournaldInputConfig
+	err := helper.UnmarshalMapstructure(input, &actual)
+	require.NoError(t, err)
+	require.Equal(t, expect, &actual)
+}
+
+func TestJournaldInputConfigBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(cfg *JournaldInputConfig)
+		expectErr bool
+	}{
+		{"Default", func(cfg *JournaldInputConfig) {}, false},
+		{"Journalctl", func(cfg *JournaldInputConfig) { cfg.Mode = JournalctlMode }, false},
+		{"Native", func(cfg *JournaldInputConfig) { cfg.Mode = NativeMode }, false},
+		{"NativePriorityRange", func(cfg *JournaldInputConfig) { cfg.Mode = NativeMode; cfg.Priority = "err..info" }, false},
+		{"NativeMatches", func(cfg *JournaldInputConfig) {
+			cfg.Mode = NativeMode
+			cfg.Matches = []map[string]string{{"_COMM": "sshd"}}
+		}, false},
+		{"NativeBoot", func(cfg *JournaldInputConfig) { cfg.Mode = NativeMode; cfg.Boot = "0" }, true},
+		{"NativeNamespace", func(cfg *JournaldInputConfig) { cfg.Mode = NativeMode; cfg.Namespace = "*" }, true},
+		{"InvalidMode", func(cfg *JournaldInputConfig) { cfg.Mode = "other" }, true},
+		{"EmptyMatch", func(cfg *JournaldInputConfig) { cfg.Matches = []map[string]string{{}} }, true},
+		{"InvalidMatchField", func(cfg *JournaldInputConfig) { cfg.Matches = []map[string]string{{"_comm": "sshd"}} }, true},
+		{"MatchFieldStartsWithDigit", func(cfg *JournaldInputConfig) { cfg.Matches = []map[string]string{{"1FIELD": "a"}} }, true},
+		{"BootOffset", func(cfg *JournaldInputConfig) { cfg.Boot = "-1" }, false},
+		{"BootID", func(cfg *JournaldInputConfig) { cfg.Boot = "2eb56a9fc9ee4b14975e64ac1d926ad9" }, false},
+		{"BootIDOffset", func(cfg *JournaldInputConfig) { cfg.Boot = "2eb56a9fc9ee4b14975e64ac1d926ad9-1" }, false},
+		{"InvalidBoot", func(cfg *JournaldInputConfig) { cfg.Boot = "last" }, true},
+		{"Namespace", func(cfg *JournaldInputConfig) { cfg.Namespace = "+app" }, false},
+		{"InvalidNamespace", func(cfg *JournaldInputConfig) { cfg.Namespace = "app/other" }, true},
+		{"Since", func(cfg *JournaldInputConfig) { cfg.Since = "2022-01-01T00:00:00Z" }, false},
+		{"InvalidSince", func(cfg *JournaldInputConfig) { cfg.Since = "yesterday" }, true},
+		{"NativeInvalidPriority", func(cfg *JournaldInputConfig) { cfg.Mode = NativeMode; cfg.Priority = "information" }, true},
+		{"NativeInvalidStartAt", func(cfg *JournaldInputConfig) { cfg.Mode = NativeMode; cfg.StartAt = "middle" }, true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewJournaldInputConfig("my_journald_input")
+			cfg.OutputIDs = []string{"output"}
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestJournaldInputArgs(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(cfg *JournaldInputConfig)
+		expected []string
+	}{
+		{
+			"Default",
+			func(cfg *JournaldInputConfig) {},
+			[]string{"--priority", "info"},
+		},
+		{
+			"UnitsAndIdentifiers",
+			func(cfg *JournaldInputConfig) {
+				cfg.Units = []string{"ssh"}
+				cfg.Identifiers = []string{"sshd", "cron"}
+			},
+			[]string{"--unit", "ssh", "--identifier", "sshd", "--identifier", "cron", "--priority", "info"},
+		},
+		{
+			"Matches",
+			func(cfg *JournaldInputConfig) {
+				cfg.Matches = []map[string]string{
+					{"_TRANSPORT": "stdout", "_COMM": "sshd"},
+					{"SYSLOG_IDENTIFIER": "cron"},
+				}
+			},
+			[]string{"--priority", "info", "_COMM=sshd", "_TRANSPORT=stdout", "+", "SYSLOG_IDENTIFIER=cron"},
+		},
+		{
+			"BootDmesgNamespace",
+			func(cfg *JournaldInputConfig) {
+				cfg.Boot = "-1"
+				cfg.Dmesg = true
+				cfg.Namespace = "app"
+			},
+			[]string{"--priority", "info", "--boot=-1", "--dmesg", "--namespace", "app"},
+		},
+		{
+			"Since",
+			func(cfg *JournaldInputConfig) {
+				cfg.Since = "2022-01-02T03:04:05.5+01:00"
+			},
+			[]string{"--priority", "info", "--since", "2022-01-02 02:04:05.500000 UTC"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewJournaldInputConfig("my_journald_input")
+			cfg.OutputIDs = []string{"output"}
+			tc.modify(cfg)
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+
+			cmd := ops[0].(*JournaldInput).newCmd(context.Background(), nil).(*exec.Cmd)
+			expected := append([]string{"journalctl", "--utc", "--output=json", "--follow"}, tc.expected...)
+			require.Equal(t, expected, cmd.Args)
+		})
+	}
+}
+
+func TestInputJournaldNative(t *testing.T) {
+	bodies := readJournalctlOutput(t, "compact")
+
+	cfg := NewJournaldInputConfig("my_journald_input")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Mode = NativeMode
+	cfg.Files = []string{filepath.Join("testdata", "compact.journal")}
+	cfg.StartAt = "beginning"
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*JournaldInput)
+	op.pollInterval = 10 * time.Millisecond
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	persister := testutil.NewMockPersister("test")
+	require.NoError(t, op.Start(persister))
+
+	// The entry with the debug priority is filtered by the default priority
+	for _, i := range []int{0, 1, 2, 3, 4, 5, 7} {
+		select {
+		case e := <-fake.Received:
+			require.Equal(t, bodies[i], e.Body)
+			if i == 0 {
+				require.Equal(t, time.Unix(0, 1792155661956625*1000), e.Timestamp)
+			}
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for entry to be read")
+		}
+	}
+	require.NoError(t, op.Stop())
+
+	cursor, err := persister.Get(context.Background(), lastReadCursorKey)
+	require.NoError(t, err)
+	require.Equal(t, bodies[7]["__CURSOR"], string(cursor))
+
+	// After a restart, reading continues from the saved cursor
+	require.NoError(t, op.Start(persister))
+	defer op.Stop()
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
+
+func TestInputJournaldWaitForAck(t *testing.T) {
+	cfg := NewJournaldInputConfig("my_journald_input")
+	cfg.OutputIDs = []string{"output"}
+	cfg.WaitForAck = true
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	mockOutput := testutil.NewMockOperator("$.output")
+	received := make(chan context.Context)
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		received <- args.Get(0).(context.Context)
+	}).Return(nil)
+
+	err = op.SetOutputs([]operator.Operator{mockOutput})
+	require.NoError(t, err)
+
+	op.(*JournaldInput).newCmd = func(ctx context.Context, cursor []byte) cmd {
+		return &fakeJournaldCmd{}
+	}
+
+	persister := testutil.NewMockPersister("test")
+	err = op.Start(persister)
+	require.NoError(t, err)
+	defer op.Stop()
+
+	var ctx context.Context
+	select {
+	case ctx = <-received:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry to be read")
+	}
+
+	// The cursor must not be saved until the entry is acknowledged
+	cursor, err := persister.Get(context.Background(), lastReadCursorKey)
+	require.NoError(t, err)
+	require.Nil(t, cursor)
+
+	helper.Ack(ctx)
+	cursor, err = persister.Get(context.Background(), lastReadCursorKey)
+	require.NoError(t, err)
+	require.Equal(t, "s=b1e713b587ae4001a9ca482c4b12c005;i=1eed30;b=c4fa36de06824d21835c05ff80c54468;m=9f9d630205;t=5a369604ee333;x=16c2d4fd4fdb7c36", string(cursor))
+}
+
+func TestInputJournaldNackRestartsJournalctl(t *testing.T) {
+	cfg := NewJournaldInputConfig("my_journald_input")
+	cfg.OutputIDs = []string{"output"}
+	cfg.WaitForAck = true
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*JournaldInput)
+	op.pollInterval = 10 * time.Millisecond
+
+	// The output fails to deliver the first entry, and delivers it when journalctl prints it again
+	mockOutput := testutil.NewMockOperator("$.output")
+	received := make(chan struct{}, 10)
+	failed := false
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if !failed {
+			failed = true
+			helper.Nack(args.Get(0).(context.Context))
+		} else {
+			helper.Ack(args.Get(0).(context.Context))
+		}
+		received <- struct{}{}
+	}).Return(nil)
+	require.NoError(t, op.SetOutputs([]operator.Operator{mockOutput}))
+
+	cursors := make(chan []byte, 10)
+	op.newCmd = func(ctx context.Context, cursor []byte) cmd {
+		cursors <- cursor
+		return &fakeJournaldCmd{}
+	}
+
+	persister := testutil.NewMockPersister("test")
+	require.NoError(t, op.Start(persister))
+	defer op.Stop()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case cursor := <-cursors:
+			require.Nil(t, cursor)
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for journalctl to be started")
+		}
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for entry to be read")
+		}
+	}
+
+	cursor, err := persister.Get(context.Background(), lastReadCursorKey)
+	require.NoError(t, err)
+	require.Equal(t, "s=b1e713b587ae4001a9ca482c4b12c005;i=1eed30;b=c4fa36de06824d21835c05ff80c54468;m=9f9d630205;t=5a369604ee333;x=16c2d4fd4fdb7c36", string(cursor))
+}
+
+func TestInputJournaldNativeNack(t *testing.T) {
+	bodies := readJournalctlOutput(t, "compact")
+
+	cfg := NewJournaldInputConfig("my_journald_input")
+	cfg.OutputIDs = []string{"output"}
+	cfg.Mode = NativeMode
+	cfg.Files = []string{filepath.Join("testdata", "compact.journal")}
+	cfg.StartAt = "beginning"
+	cfg.WaitForAck = true
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*JournaldInput)
+	op.pollInterval = 10 * time.Millisecond
+
+	// The output fails to deliver the first copy of the third entry, and delivers every other entry
+	mockOutput := testutil.NewMockOperator("$.output")
+	received := make(chan *entry.Entry, 20)
+	failed := false
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		e := args.Get(1).(*entry.Entry)
+		if !failed && e.Body.(map[string]interface{})["__CURSOR"] == bodies[2]["__CURSOR"] {
+			failed = true
+			helper.Nack(args.Get(0).(context.Context))
+		} else {
+			helper.Ack(args.Get(0).(context.Context))
+		}
+		received <- e
+	}).Return(nil)
+	require.NoError(t, op.SetOutputs([]operator.Operator{mockOutput}))
+
+	persister := testutil.NewMockPersister("test")
+	require.NoError(t, op.Start(persister))
+	defer op.Stop()
+
+	// Reading stops at the failed entry, which is read again with the entries after it
+	for _, i := range []int{0, 1, 2, 2, 3, 4, 5, 7} {
+		select {
+		case e := <-received:
+			require.Equal(t, bodies[i], e.Body)
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for entry to be read")
+		}
+	}
+	require.NoError(t, op.Stop())
+
+	cursor, err := persister.Get(context.Background(), lastReadCursorKey)
+	require.NoError(t, err)
+	require.Equal(t, bodies[7]["__CURSOR"], string(cursor))
+}
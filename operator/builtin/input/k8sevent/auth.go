@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sevent
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/errors"
+)
+
+const (
+	// AuthTypeInCluster authenticates with the service account of the pod the agent is running in
+	AuthTypeInCluster = "in_cluster"
+	// AuthTypeKubeConfig authenticates with the credentials of a kubeconfig file
+	AuthTypeKubeConfig = "kubeconfig"
+	// AuthTypeServiceAccount authenticates to an API server with a service account token file
+	AuthTypeServiceAccount = "service_account"
+
+	defaultTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// AuthConfig is the configuration of the connection to the kubernetes API
+type AuthConfig struct {
+	AuthType           string `json:"auth_type"                      yaml:"auth_type"`
+	KubeConfigPath     string `json:"kubeconfig_path,omitempty"      yaml:"kubeconfig_path,omitempty"`
+	Context            string `json:"context,omitempty"              yaml:"context,omitempty"`
+	APIServer          string `json:"api_server,omitempty"           yaml:"api_server,omitempty"`
+	TokenFile          string `json:"token_file,omitempty"           yaml:"token_file,omitempty"`
+	CAFile             string `json:"ca_file,omitempty"              yaml:"ca_file,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty"`
+}
+
+// validate checks that the options of the auth type are set, and that no other options are set
+func (c AuthConfig) validate() error {
+	switch c.AuthType {
+	case AuthTypeInCluster:
+		if c.KubeConfigPath != "" || c.Context != "" || c.APIServer != "" || c.TokenFile != "" || c.CAFile != "" || c.InsecureSkipVerify {
+			return fmt.Errorf("connection options cannot be set when `auth_type` is %s", AuthTypeInCluster)
+		}
+	case AuthTypeKubeConfig:
+		if c.APIServer != "" || c.TokenFile != "" || c.CAFile != "" || c.InsecureSkipVerify {
+			return fmt.Errorf("only `kubeconfig_path` and `context` can be set when `auth_type` is %s", AuthTypeKubeConfig)
+		}
+	case AuthTypeServiceAccount:
+		if c.APIServer == "" {
+			return fmt.Errorf("`api_server` is required when `auth_type` is %s", AuthTypeServiceAccount)
+		}
+		if c.KubeConfigPath != "" || c.Context != "" {
+			return fmt.Errorf("`kubeconfig_path` and `context` cannot be set when `auth_type` is %s", AuthTypeServiceAccount)
+		}
+		if c.CAFile != "" && c.InsecureSkipVerify {
+			return fmt.Errorf("`ca_file` cannot be set when `insecure_skip_verify` is enabled")
+		}
+	default:
+		return fmt.Errorf("invalid `auth_type` '%s'", c.AuthType)
+	}
+	return nil
+}
+
+// restConfig creates the client configuration for the auth type
+func (c AuthConfig) restConfig() (*rest.Config, error) {
+	switch c.AuthType {
+	case AuthTypeKubeConfig:
+		rules := clientcmd.NewDefaultClientConfigLoadingRules()
+		rules.ExplicitPath = c.KubeConfigPath
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: c.Context}
+		config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "load kubeconfig")
+		}
+		return config, nil
+	case AuthTypeServiceAccount:
+		tokenFile := c.TokenFile
+		if tokenFile == "" {
+			tokenFile = defaultTokenFile
+		}
+		if _, err := os.Stat(tokenFile); err != nil {
+			return nil, errors.Wrap(err, "read token file")
+		}
+		return &rest.Config{
+			Host:            c.APIServer,
+			BearerTokenFile: tokenFile,
+			TLSClientConfig: rest.TLSClientConfig{
+				CAFile:   c.CAFile,
+				Insecure: c.InsecureSkipVerify,
+			},
+		}, nil
+	default:
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, errors.NewError(
+				"agent not in kubernetes cluster",
+				"the in_cluster auth_type only supports running in a pod inside a kubernetes cluster, use the kubeconfig or service_account auth_type instead",
+			)
+		}
+		return config, nil
+	}
+}
@@ -22,11 +22,14 @@ import (
 
 	"go.uber.org/zap"
 	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
-	"k8s.io/client-go/rest"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/errors"
@@ -42,6 +45,7 @@ func init() {
 func NewK8sEventsConfig(operatorID string) *K8sEventsConfig {
 	return &K8sEventsConfig{
 		InputConfig:        helper.NewInputConfig(operatorID, "k8s_event_input"),
+		AuthConfig:         AuthConfig{AuthType: AuthTypeInCluster},
 		Namespaces:         []string{},
 		DiscoverNamespaces: true,
 		DiscoveryInterval:  helper.Duration{Duration: time.Minute * 1},
@@ -51,9 +55,13 @@ func NewK8sEventsConfig(operatorID string) *K8sEventsConfig {
 // K8sEventsConfig is the configuration of K8sEvents operator
 type K8sEventsConfig struct {
 	helper.InputConfig `yaml:",inline"`
+	AuthConfig         `yaml:",inline"`
 	Namespaces         []string        `json:"namespaces" yaml:"namespaces"`
 	DiscoverNamespaces bool            `json:"discover_namespaces" yaml:"discover_namespaces"`
 	DiscoveryInterval  helper.Duration `json:"discovery_interval" yaml:"discovery_interval"`
+	FieldSelector      string          `json:"field_selector,omitempty" yaml:"field_selector,omitempty"`
+	LabelSelector      string          `json:"label_selector,omitempty" yaml:"label_selector,omitempty"`
+	WaitForAck         bool            `json:"wait_for_ack,omitempty"   yaml:"wait_for_ack,omitempty"`
 }
 
 // Build will build a k8s_event_input operator from the supplied configuration
@@ -67,11 +75,28 @@ func (c K8sEventsConfig) Build(context operator.BuildContext) ([]operator.Operat
 		return nil, fmt.Errorf("`namespaces` must be specified or `discover_namespaces` enabled")
 	}
 
+	if err := c.AuthConfig.validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := fields.ParseSelector(c.FieldSelector); err != nil {
+		return nil, fmt.Errorf("invalid `field_selector`: %s", err)
+	}
+
+	if _, err := labels.Parse(c.LabelSelector); err != nil {
+		return nil, fmt.Errorf("invalid `label_selector`: %s", err)
+	}
+
 	op := &K8sEvents{
 		InputOperator:      input,
+		auth:               c.AuthConfig,
 		namespaces:         c.Namespaces,
 		discoverNamespaces: c.DiscoverNamespaces,
 		discoveryInterval:  c.DiscoveryInterval,
+		fieldSelector:      c.FieldSelector,
+		labelSelector:      c.LabelSelector,
+		waitForAck:         c.WaitForAck,
+		saveInterval:       defaultSaveInterval,
 	}
 
 	return []operator.Operator{op}, nil
@@ -80,30 +105,36 @@ func (c K8sEventsConfig) Build(context operator.BuildContext) ([]operator.Operat
 // K8sEvents is an operator for generating logs from k8s events
 type K8sEvents struct {
 	helper.InputOperator
+	auth               AuthConfig
 	client             corev1.CoreV1Interface
+	persister          operator.Persister
 	discoverNamespaces bool
 	discoveryInterval  helper.Duration
 	namespaces         []string
+	fieldSelector      string
+	labelSelector      string
+	waitForAck         bool
+
+	// saveInterval is how often the resource versions are saved, and how often failed events are checked for
+	saveInterval time.Duration
 
 	cancel       func()
 	wg           sync.WaitGroup
 	namespaceMux sync.Mutex
 }
 
+// defaultSaveInterval is how often the resource version of a namespace is saved, if it has changed
+const defaultSaveInterval = time.Second
+
 // Start implements the operator.Operator interface
-func (k *K8sEvents) Start(_ operator.Persister) error {
+func (k *K8sEvents) Start(persister operator.Persister) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	k.cancel = cancel
+	k.persister = persister
 
-	// Currently, we only support running in the cluster. In contrast to the
-	// k8s_metadata_decorator, it may make sense to relax this restriction
-	// by exposing client config options.
-	config, err := rest.InClusterConfig()
+	config, err := k.auth.restConfig()
 	if err != nil {
-		return errors.NewError(
-			"agent not in kubernetes cluster",
-			"the k8s_event_input operator only supports running in a pod inside a kubernetes cluster",
-		)
+		return err
 	}
 
 	k.client, err = corev1.NewForConfig(config)
@@ -126,7 +157,7 @@ func (k *K8sEvents) Start(_ operator.Persister) error {
 
 	// Test connection
 	if len(k.namespaces) > 0 {
-		testWatcher, err := k.client.Events(k.namespaces[0]).Watch(ctx, metav1.ListOptions{})
+		testWatcher, err := k.client.Events(k.namespaces[0]).Watch(ctx, k.listOptions(""))
 		if err != nil {
 			return fmt.Errorf("test connection failed: list events for namespace '%s': %s", k.namespaces[0], err)
 		}
@@ -198,11 +229,21 @@ func (k *K8sEvents) startFindingNamespaces(ctx context.Context, client corev1.Co
 }
 
 // startWatchingNamespace creates a goroutine that watches the events for a
-// specific namespace
+// specific namespace, starting from the last resource version that was seen
 func (k *K8sEvents) startWatchingNamespace(ctx context.Context, ns string) {
 	k.wg.Add(1)
 	go func() {
 		defer k.wg.Done()
+
+		resourceVersion, err := k.loadResourceVersion(ctx, ns)
+		if err != nil {
+			k.Warnw("Failed to load resource version", zap.String("namespace", ns), zap.Error(err))
+		}
+
+		checkpoint := k.newResourceVersionCheckpoint(ns, resourceVersion)
+		// The context is canceled when stopping, so the last resource version is saved without it
+		defer k.saveResourceVersion(context.Background(), checkpoint)
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -210,18 +251,143 @@ func (k *K8sEvents) startWatchingNamespace(ctx context.Context, ns string) {
 			default:
 			}
 
-			watcher, err := k.client.Events(ns).Watch(ctx, metav1.ListOptions{})
+			watcher, err := k.client.Events(ns).Watch(ctx, k.listOptions(resourceVersion))
+			if k8serrors.IsResourceExpired(err) || k8serrors.IsGone(err) {
+				resourceVersion = k.resetResourceVersion(ctx, checkpoint, resourceVersion)
+				continue
+			}
 			if err != nil {
 				k.Errorw("Failed to start watcher", zap.Error(err))
 				k.removeNamespace(ns)
 				return
 			}
 
-			k.consumeWatchEvents(ctx, watcher.ResultChan())
+			resourceVersion = k.consumeWatchEvents(ctx, checkpoint, resourceVersion, watcher.ResultChan())
+			watcher.Stop()
+			k.saveResourceVersion(ctx, checkpoint)
 		}
 	}()
 }
 
+// listOptions creates the options to list or watch events from a resource version
+func (k *K8sEvents) listOptions(resourceVersion string) metav1.ListOptions {
+	return metav1.ListOptions{
+		FieldSelector:       k.fieldSelector,
+		LabelSelector:       k.labelSelector,
+		ResourceVersion:     resourceVersion,
+		AllowWatchBookmarks: true,
+	}
+}
+
+// resourceVersionKey is the key the last resource version seen in a namespace is persisted at
+func resourceVersionKey(ns string) string {
+	return "resourceVersion." + ns
+}
+
+// loadResourceVersion returns the persisted resource version of a namespace,
+// or an empty string if the namespace has not been watched before
+func (k *K8sEvents) loadResourceVersion(ctx context.Context, ns string) (string, error) {
+	resourceVersion, err := k.persister.Get(ctx, resourceVersionKey(ns))
+	if err != nil {
+		return "", err
+	}
+	return string(resourceVersion), nil
+}
+
+// resourceVersionCheckpoint tracks the resource version that the events of a namespace have been
+// delivered up to. It is saved on bookmarks and periodically, rather than after every event.
+// It is only used by the goroutine watching the namespace.
+type resourceVersionCheckpoint struct {
+	ns string
+	// acks tracks the resource versions of the events until they are acknowledged, if waiting for acknowledgements
+	acks *helper.CheckpointTracker
+
+	delivered string
+	saved     string
+}
+
+// newResourceVersionCheckpoint creates a checkpoint of a namespace, starting from its persisted resource version
+func (k *K8sEvents) newResourceVersionCheckpoint(ns, resourceVersion string) *resourceVersionCheckpoint {
+	c := &resourceVersionCheckpoint{ns: ns, delivered: resourceVersion, saved: resourceVersion}
+	if k.waitForAck {
+		c.acks = helper.NewCheckpointTracker(resourceVersion, nil)
+	}
+	return c
+}
+
+// track returns the context to write an event with. The resource version is delivered once the event
+// and every event before it have been acknowledged, or immediately if not waiting for acknowledgements.
+func (c *resourceVersionCheckpoint) track(ctx context.Context, resourceVersion string) context.Context {
+	if c.acks != nil {
+		return c.acks.Track(ctx, resourceVersion)
+	}
+	c.delivered = resourceVersion
+	return ctx
+}
+
+// stalled returns true if an event failed to be delivered
+func (c *resourceVersionCheckpoint) stalled() bool {
+	return c.acks != nil && c.acks.Stalled()
+}
+
+// rewind forgets the events that have not been delivered, and returns the resource version to watch from again
+func (c *resourceVersionCheckpoint) rewind() string {
+	return c.acks.Rewind().(string)
+}
+
+// reset starts again from a resource version, forgetting the events before it
+func (c *resourceVersionCheckpoint) reset(resourceVersion string) {
+	if c.acks != nil {
+		c.acks = helper.NewCheckpointTracker(resourceVersion, nil)
+	}
+	c.delivered = resourceVersion
+}
+
+// unsaved returns the resource version that events have been delivered up to, and false if it has already been saved
+func (c *resourceVersionCheckpoint) unsaved() (string, bool) {
+	if c.acks != nil {
+		c.delivered = c.acks.Committed().(string)
+	}
+	return c.delivered, c.delivered != c.saved
+}
+
+// saveResourceVersion persists the resource version that the events of a namespace have been delivered up to,
+// if it has changed since it was last saved
+func (k *K8sEvents) saveResourceVersion(ctx context.Context, checkpoint *resourceVersionCheckpoint) {
+	resourceVersion, ok := checkpoint.unsaved()
+	if !ok {
+		return
+	}
+	if err := k.persister.Set(ctx, resourceVersionKey(checkpoint.ns), []byte(resourceVersion)); err != nil {
+		k.Warnw("Failed to save resource version", zap.String("namespace", checkpoint.ns), zap.Error(err))
+		return
+	}
+	checkpoint.saved = resourceVersion
+}
+
+// resetResourceVersion returns the current resource version of the events of a namespace,
+// to watch from when the last seen resource version is too old. The events between the two
+// resource versions are missed, since the API server no longer has them.
+func (k *K8sEvents) resetResourceVersion(ctx context.Context, checkpoint *resourceVersionCheckpoint, expired string) string {
+	ns := checkpoint.ns
+	k.Warnw("Resource version expired, watching from the current resource version",
+		zap.String("namespace", ns), zap.String("resource_version", expired))
+
+	options := k.listOptions("")
+	options.AllowWatchBookmarks = false
+	options.Limit = 1
+	list, err := k.client.Events(ns).List(ctx, options)
+	if err != nil {
+		// Watching without a resource version starts again from the events that currently exist
+		k.Errorw("Failed to get current resource version", zap.Error(err))
+		return ""
+	}
+
+	checkpoint.reset(list.ResourceVersion)
+	k.saveResourceVersion(ctx, checkpoint)
+	return list.ResourceVersion
+}
+
 // addNamespace will add a namespace.
 func (k *K8sEvents) addNamespace(namespace string) {
 	k.namespaceMux.Lock()
@@ -256,18 +422,56 @@ func (k *K8sEvents) removeNamespace(namespace string) {
 	}
 }
 
-// consumeWatchEvents will read events from the watcher channel until the channel is closed
-// or the context is canceled
-func (k *K8sEvents) consumeWatchEvents(ctx context.Context, events <-chan watch.Event) {
+// consumeWatchEvents will read events from the watcher channel until the channel is closed,
+// the context is canceled, the watch fails or an event fails to be delivered. It returns the
+// resource version to watch from next.
+func (k *K8sEvents) consumeWatchEvents(ctx context.Context, checkpoint *resourceVersionCheckpoint, resourceVersion string, events <-chan watch.Event) string {
+	ticker := time.NewTicker(k.saveInterval)
+	defer ticker.Stop()
+
 	for {
+		// The failed event and the events after it are watched again
+		if checkpoint.stalled() {
+			resourceVersion = checkpoint.rewind()
+			k.Debugw("Watching again from the last delivered event",
+				zap.String("namespace", checkpoint.ns), zap.String("resource_version", resourceVersion))
+			return resourceVersion
+		}
+
 		select {
+		case <-ticker.C:
+			k.saveResourceVersion(ctx, checkpoint)
 		case event, ok := <-events:
 			if !ok {
 				k.Error("Watcher channel closed")
-				return
+				return resourceVersion
+			}
+
+			switch event.Type {
+			case watch.Error:
+				err := k8serrors.FromObject(event.Object)
+				if !k8serrors.IsResourceExpired(err) && !k8serrors.IsGone(err) {
+					k.Errorw("Watch failed", zap.Error(err))
+					return resourceVersion
+				}
+
+				return k.resetResourceVersion(ctx, checkpoint, resourceVersion)
+			case watch.Bookmark:
+				// A bookmark has no entry, so it is delivered once the events before it are
+				if object, err := meta.Accessor(event.Object); err == nil {
+					resourceVersion = object.GetResourceVersion()
+					helper.Ack(checkpoint.track(ctx, resourceVersion))
+					k.saveResourceVersion(ctx, checkpoint)
+				}
+				continue
+			}
+
+			typedEvent, ok := event.Object.(*apiv1.Event)
+			if !ok {
+				k.Errorf("Unexpected object type %T", event.Object)
+				continue
 			}
 
-			typedEvent := event.Object.(*apiv1.Event)
 			body, err := runtime.DefaultUnstructuredConverter.ToUnstructured(event.Object)
 			if err != nil {
 				k.Error("Failed to convert event to map", zap.Error(err))
@@ -292,9 +496,11 @@ func (k *K8sEvents) consumeWatchEvents(ctx context.Context, events <-chan watch.
 
 			entry.AddAttribute("event_type", string(event.Type))
 			k.populateResource(typedEvent, entry)
-			k.Write(ctx, entry)
+
+			resourceVersion = typedEvent.ResourceVersion
+			k.Write(checkpoint.track(ctx, resourceVersion), entry)
 		case <-ctx.Done():
-			return
+			return resourceVersion
 		}
 	}
 }
@@ -16,11 +16,15 @@ package k8sevent
 
 import (
 	"context"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -28,6 +32,7 @@ import (
 	fakev1 "k8s.io/client-go/kubernetes/typed/core/v1/fake"
 	fakeTest "k8s.io/client-go/testing"
 
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
@@ -75,12 +80,14 @@ func TestWatchNamespace(t *testing.T) {
 		client: &fakev1.FakeCoreV1{
 			Fake: fakeAPI,
 		},
-		namespaces: []string{"test_namespace"},
-		cancel:     cancel,
+		namespaces:   []string{"test_namespace"},
+		saveInterval: defaultSaveInterval,
+		cancel:       cancel,
 	}
 
 	fake := testutil.NewFakeOutput(t)
 	op.OutputOperators = []operator.Operator{fake}
+	op.persister = testutil.NewMockPersister("test")
 
 	op.startWatchingNamespace(ctx, "test_namespace")
 	defer op.Stop()
@@ -123,3 +130,308 @@ func TestListNamespaces(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []string{"test1", "test2"}, namespaces)
 }
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		mod       func(*K8sEventsConfig)
+		expectErr bool
+	}{
+		{"Default", nil, false},
+		{"KubeConfig", func(cfg *K8sEventsConfig) {
+			cfg.AuthType = AuthTypeKubeConfig
+			cfg.KubeConfigPath = "/etc/kube/config"
+			cfg.Context = "prod"
+		}, false},
+		{"ServiceAccount", func(cfg *K8sEventsConfig) {
+			cfg.AuthType = AuthTypeServiceAccount
+			cfg.APIServer = "https://kubernetes.example.com:6443"
+			cfg.CAFile = "/etc/kube/ca.crt"
+		}, false},
+		{"Selectors", func(cfg *K8sEventsConfig) {
+			cfg.FieldSelector = "involvedObject.kind=Pod,type!=Normal"
+			cfg.LabelSelector = "app in (api, web)"
+		}, false},
+		{"InvalidAuthType", func(cfg *K8sEventsConfig) { cfg.AuthType = "password" }, true},
+		{"InClusterWithKubeConfig", func(cfg *K8sEventsConfig) { cfg.KubeConfigPath = "/etc/kube/config" }, true},
+		{"KubeConfigWithAPIServer", func(cfg *K8sEventsConfig) {
+			cfg.AuthType = AuthTypeKubeConfig
+			cfg.APIServer = "https://kubernetes.example.com:6443"
+		}, true},
+		{"ServiceAccountWithoutAPIServer", func(cfg *K8sEventsConfig) { cfg.AuthType = AuthTypeServiceAccount }, true},
+		{"ServiceAccountWithContext", func(cfg *K8sEventsConfig) {
+			cfg.AuthType = AuthTypeServiceAccount
+			cfg.APIServer = "https://kubernetes.example.com:6443"
+			cfg.Context = "prod"
+		}, true},
+		{"ServiceAccountCAFileAndInsecure", func(cfg *K8sEventsConfig) {
+			cfg.AuthType = AuthTypeServiceAccount
+			cfg.APIServer = "https://kubernetes.example.com:6443"
+			cfg.CAFile = "/etc/kube/ca.crt"
+			cfg.InsecureSkipVerify = true
+		}, true},
+		{"InvalidFieldSelector", func(cfg *K8sEventsConfig) { cfg.FieldSelector = "type" }, true},
+		{"InvalidLabelSelector", func(cfg *K8sEventsConfig) { cfg.LabelSelector = "app in (" }, true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewK8sEventsConfig("test")
+			if tc.mod != nil {
+				tc.mod(cfg)
+			}
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com:6443
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+current-context: dev
+`
+
+func TestAuthRestConfig(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	kubeConfig := filepath.Join(tempDir, "config")
+	require.NoError(t, ioutil.WriteFile(kubeConfig, []byte(testKubeConfig), 0600))
+	tokenFile := filepath.Join(tempDir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("token"), 0600))
+
+	t.Run("KubeConfigCurrentContext", func(t *testing.T) {
+		config, err := AuthConfig{AuthType: AuthTypeKubeConfig, KubeConfigPath: kubeConfig}.restConfig()
+		require.NoError(t, err)
+		require.Equal(t, "https://dev.example.com:6443", config.Host)
+		require.Equal(t, "secret", config.BearerToken)
+	})
+
+	t.Run("KubeConfigContext", func(t *testing.T) {
+		config, err := AuthConfig{AuthType: AuthTypeKubeConfig, KubeConfigPath: kubeConfig, Context: "prod"}.restConfig()
+		require.NoError(t, err)
+		require.Equal(t, "https://prod.example.com:6443", config.Host)
+	})
+
+	t.Run("KubeConfigMissingContext", func(t *testing.T) {
+		_, err := AuthConfig{AuthType: AuthTypeKubeConfig, KubeConfigPath: kubeConfig, Context: "staging"}.restConfig()
+		require.Error(t, err)
+	})
+
+	t.Run("ServiceAccount", func(t *testing.T) {
+		config, err := AuthConfig{
+			AuthType:           AuthTypeServiceAccount,
+			APIServer:          "https://prod.example.com:6443",
+			TokenFile:          tokenFile,
+			InsecureSkipVerify: true,
+		}.restConfig()
+		require.NoError(t, err)
+		require.Equal(t, "https://prod.example.com:6443", config.Host)
+		require.Equal(t, tokenFile, config.BearerTokenFile)
+		require.True(t, config.Insecure)
+	})
+
+	t.Run("ServiceAccountMissingTokenFile", func(t *testing.T) {
+		_, err := AuthConfig{
+			AuthType:  AuthTypeServiceAccount,
+			APIServer: "https://prod.example.com:6443",
+			TokenFile: filepath.Join(tempDir, "missing"),
+		}.restConfig()
+		require.Error(t, err)
+	})
+}
+
+func newTestEvent(name, resourceVersion string) *apiv1.Event {
+	return &apiv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			ResourceVersion: resourceVersion,
+		},
+		InvolvedObject: apiv1.ObjectReference{Kind: "Pod", Name: "pod", Namespace: "default"},
+		LastTimestamp:  metav1.Time{Time: fakeTime},
+	}
+}
+
+// newWatchingOperator creates an operator whose first watch returns the given events then closes,
+// and whose later watches never return any. The resource version of each watch is sent on the returned channel.
+func newWatchingOperator(t *testing.T, persister operator.Persister, fakeAPI *fakeTest.Fake, events ...watch.Event) (*K8sEvents, *testutil.FakeOutput, chan string) {
+	inputOp, err := helper.NewInputConfig("test_id", "k8s_event_input").Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	resourceVersions := make(chan string, 10)
+	first := true
+	fakeAPI.AddWatchReactor("*", func(action fakeTest.Action) (handled bool, ret watch.Interface, err error) {
+		resourceVersions <- action.(fakeTest.WatchAction).GetWatchRestrictions().ResourceVersion
+		watcher := watch.NewFakeWithChanSize(len(events), false)
+		if first {
+			first = false
+			for _, event := range events {
+				watcher.Action(event.Type, event.Object)
+			}
+			watcher.Stop()
+		}
+		return true, watcher, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	op := &K8sEvents{
+		InputOperator: inputOp,
+		client:        &fakev1.FakeCoreV1{Fake: fakeAPI},
+		persister:     persister,
+		saveInterval:  defaultSaveInterval,
+		cancel:        cancel,
+	}
+	fake := testutil.NewFakeOutput(t)
+	op.OutputOperators = []operator.Operator{fake}
+
+	op.startWatchingNamespace(ctx, "default")
+	t.Cleanup(func() { require.NoError(t, op.Stop()) })
+	return op, fake, resourceVersions
+}
+
+func TestResourceVersionPersisted(t *testing.T) {
+	persister := testutil.NewMockPersister("test")
+	require.NoError(t, persister.Set(context.Background(), resourceVersionKey("default"), []byte("100")))
+
+	_, fake, resourceVersions := newWatchingOperator(t, persister, &fakeTest.Fake{},
+		watch.Event{Type: watch.Added, Object: newTestEvent("first", "101")},
+		watch.Event{Type: watch.Bookmark, Object: newTestEvent("", "105")},
+		watch.Event{Type: watch.Modified, Object: newTestEvent("second", "110")},
+	)
+
+	// The watch starts from the persisted resource version
+	require.Equal(t, "100", <-resourceVersions)
+
+	for _, name := range []string{"first", "second"} {
+		select {
+		case e := <-fake.Received:
+			require.Equal(t, name, e.Body.(map[string]interface{})["metadata"].(map[string]interface{})["name"])
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for entry")
+		}
+	}
+
+	// Once the first watch closes, the next watch starts from the last resource version seen
+	require.Equal(t, "110", <-resourceVersions)
+	resourceVersion, err := persister.Get(context.Background(), resourceVersionKey("default"))
+	require.NoError(t, err)
+	require.Equal(t, "110", string(resourceVersion))
+}
+
+func TestResourceVersionExpired(t *testing.T) {
+	persister := testutil.NewMockPersister("test")
+	require.NoError(t, persister.Set(context.Background(), resourceVersionKey("default"), []byte("100")))
+
+	fakeAPI := &fakeTest.Fake{}
+	fakeAPI.AddReactor("list", "events", func(action fakeTest.Action) (bool, runtime.Object, error) {
+		return true, &apiv1.EventList{ListMeta: metav1.ListMeta{ResourceVersion: "500"}}, nil
+	})
+	expired := k8serrors.NewResourceExpired("too old resource version: 100 (400)")
+	_, fake, resourceVersions := newWatchingOperator(t, persister, fakeAPI,
+		watch.Event{Type: watch.Error, Object: &expired.ErrStatus},
+	)
+
+	require.Equal(t, "100", <-resourceVersions)
+	require.Equal(t, "500", <-resourceVersions)
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+
+	resourceVersion, err := persister.Get(context.Background(), resourceVersionKey("default"))
+	require.NoError(t, err)
+	require.Equal(t, "500", string(resourceVersion))
+}
+
+func TestResourceVersionWaitForAck(t *testing.T) {
+	inputOp, err := helper.NewInputConfig("test_id", "k8s_event_input").Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	persister := testutil.NewMockPersister("test")
+	require.NoError(t, persister.Set(context.Background(), resourceVersionKey("default"), []byte("100")))
+
+	// Each watch returns the events after its resource version, like the API server, and stays open
+	events := []watch.Event{
+		{Type: watch.Added, Object: newTestEvent("first", "101")},
+		{Type: watch.Bookmark, Object: newTestEvent("", "105")},
+		{Type: watch.Modified, Object: newTestEvent("second", "110")},
+	}
+	resourceVersions := make(chan string, 10)
+	fakeAPI := &fakeTest.Fake{}
+	fakeAPI.AddWatchReactor("*", func(action fakeTest.Action) (handled bool, ret watch.Interface, err error) {
+		resourceVersion := action.(fakeTest.WatchAction).GetWatchRestrictions().ResourceVersion
+		resourceVersions <- resourceVersion
+		watcher := watch.NewFakeWithChanSize(len(events), false)
+		for _, event := range events {
+			if event.Object.(*apiv1.Event).ResourceVersion > resourceVersion {
+				watcher.Action(event.Type, event.Object)
+			}
+		}
+		return true, watcher, nil
+	})
+
+	// The output fails to deliver the first copy of the second event, and delivers every other event
+	received := make(chan string, 10)
+	failed := false
+	output := testutil.NewMockOperator("$.output")
+	output.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		name := args.Get(1).(*entry.Entry).Body.(map[string]interface{})["metadata"].(map[string]interface{})["name"].(string)
+		if name == "second" && !failed {
+			failed = true
+			helper.Nack(args.Get(0).(context.Context))
+		} else {
+			helper.Ack(args.Get(0).(context.Context))
+		}
+		received <- name
+	}).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	op := &K8sEvents{
+		InputOperator: inputOp,
+		client:        &fakev1.FakeCoreV1{Fake: fakeAPI},
+		persister:     persister,
+		waitForAck:    true,
+		saveInterval:  10 * time.Millisecond,
+		cancel:        cancel,
+	}
+	op.OutputOperators = []operator.Operator{output}
+	op.startWatchingNamespace(ctx, "default")
+	defer op.Stop()
+
+	// After the failed event, the watch starts again from the bookmark, which was delivered with the first event
+	require.Equal(t, "100", <-resourceVersions)
+	for _, name := range []string{"first", "second", "second"} {
+		select {
+		case received := <-received:
+			require.Equal(t, name, received)
+		case <-time.After(time.Second):
+			require.FailNow(t, "Timed out waiting for entry")
+		}
+	}
+	require.Equal(t, "105", <-resourceVersions)
+
+	require.Eventually(t, func() bool {
+		resourceVersion, err := persister.Get(context.Background(), resourceVersionKey("default"))
+		return err == nil && string(resourceVersion) == "110"
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/collector/config/configtls"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestUnmarshal(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name: "grpc",
+			Expect: func() *OTLPInputConfig {
+				cfg := defaultCfg()
+				cfg.GRPC = &ServerConfig{ListenAddress: "0.0.0.0:4317"}
+				return cfg
+			}(),
+		},
+		{
+			Name: "http",
+			Expect: func() *OTLPInputConfig {
+				cfg := defaultCfg()
+				cfg.HTTP = &ServerConfig{ListenAddress: "0.0.0.0:4318"}
+				return cfg
+			}(),
+		},
+		{
+			Name: "both",
+			Expect: func() *OTLPInputConfig {
+				cfg := defaultCfg()
+				cfg.GRPC = &ServerConfig{ListenAddress: "0.0.0.0:4317"}
+				cfg.HTTP = &ServerConfig{ListenAddress: "0.0.0.0:4318"}
+				return cfg
+			}(),
+		},
+		{
+			Name: "max_request_size",
+			Expect: func() *OTLPInputConfig {
+				cfg := defaultCfg()
+				cfg.HTTP = &ServerConfig{ListenAddress: "0.0.0.0:4318", MaxRequestSize: 1024 * 1024}
+				return cfg
+			}(),
+		},
+		{
+			Name: "tls",
+			Expect: func() *OTLPInputConfig {
+				cfg := defaultCfg()
+				cfg.GRPC = &ServerConfig{
+					ListenAddress: "0.0.0.0:4317",
+					TLS: helper.NewTLSServerConfig(&configtls.TLSServerSetting{
+						TLSSetting: configtls.TLSSetting{
+							CertFile: "/tmp/cert.crt",
+							KeyFile:  "/tmp/cert.key",
+						},
+					}),
+				}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *OTLPInputConfig {
+	return NewOTLPInputConfig("otlp_input")
+}
@@ -0,0 +1,242 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/convert"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("otlp_input", func() operator.Builder { return NewOTLPInputConfig("") })
+}
+
+const (
+	shutdownTimeout       = 5 * time.Second
+	defaultMaxRequestSize = 4 * 1024 * 1024
+)
+
+// NewOTLPInputConfig creates a new otlp input config with default values
+func NewOTLPInputConfig(operatorID string) *OTLPInputConfig {
+	return &OTLPInputConfig{
+		InputConfig: helper.NewInputConfig(operatorID, "otlp_input"),
+	}
+}
+
+// OTLPInputConfig is the configuration of an otlp input operator
+type OTLPInputConfig struct {
+	helper.InputConfig `mapstructure:",squash" yaml:",inline"`
+
+	GRPC *ServerConfig `mapstructure:"grpc" json:"grpc,omitempty" yaml:"grpc,omitempty"`
+	HTTP *ServerConfig `mapstructure:"http" json:"http,omitempty" yaml:"http,omitempty"`
+}
+
+// ServerConfig is the configuration of a server that receives OTLP requests
+type ServerConfig struct {
+	ListenAddress  string                  `mapstructure:"listen_address"   json:"listen_address"             yaml:"listen_address"`
+	TLS            *helper.TLSServerConfig `mapstructure:"tls"              json:"tls,omitempty"              yaml:"tls,omitempty"`
+	MaxRequestSize helper.ByteSize         `mapstructure:"max_request_size" json:"max_request_size,omitempty" yaml:"max_request_size,omitempty"`
+}
+
+// build validates the server config and loads its tls config
+func (c *ServerConfig) build(name string) (*server, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	if c.ListenAddress == "" {
+		return nil, fmt.Errorf("missing required parameter '%s.listen_address'", name)
+	}
+
+	if _, err := net.ResolveTCPAddr("tcp", c.ListenAddress); err != nil {
+		return nil, fmt.Errorf("failed to resolve %s.listen_address: %s", name, err)
+	}
+
+	if c.MaxRequestSize < 0 {
+		return nil, fmt.Errorf("'%s.max_request_size' must not be negative", name)
+	}
+
+	s := &server{address: c.ListenAddress, maxRequestSize: int64(c.MaxRequestSize)}
+	if s.maxRequestSize == 0 {
+		s.maxRequestSize = defaultMaxRequestSize
+	}
+	if c.TLS != nil {
+		var err error
+		s.tls, err = c.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s.tls: %s", name, err)
+		}
+	}
+	return s, nil
+}
+
+// Build will build an otlp input operator
+func (c OTLPInputConfig) Build(bc operator.BuildContext) ([]operator.Operator, error) {
+	inputOperator, err := c.InputConfig.Build(bc)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.GRPC == nil && c.HTTP == nil {
+		return nil, fmt.Errorf("at least one of 'grpc' or 'http' must be configured")
+	}
+
+	grpcServer, err := c.GRPC.build("grpc")
+	if err != nil {
+		return nil, err
+	}
+
+	httpServer, err := c.HTTP.build("http")
+	if err != nil {
+		return nil, err
+	}
+
+	otlpInput := &OTLPInput{
+		InputOperator: inputOperator,
+		grpc:          grpcServer,
+		http:          httpServer,
+	}
+
+	return []operator.Operator{otlpInput}, nil
+}
+
+// server is the address, tls config and request size limit of a server, and its listener once started
+type server struct {
+	address        string
+	tls            *tls.Config
+	maxRequestSize int64
+	listener       net.Listener
+}
+
+// OTLPInput is an operator that receives logs sent using OTLP
+type OTLPInput struct {
+	helper.InputOperator
+
+	grpc *server
+	http *server
+
+	grpcServer *grpc.Server
+	httpServer *http.Server
+	wg         sync.WaitGroup
+}
+
+// Start will start listening for OTLP requests
+func (o *OTLPInput) Start(_ operator.Persister) error {
+	if o.grpc != nil {
+		listener, err := net.Listen("tcp", o.grpc.address)
+		if err != nil {
+			return fmt.Errorf("failed to listen for grpc: %w", err)
+		}
+		o.grpc.listener = listener
+		grpcServer := newGRPCServer(o, o.grpc.tls, o.grpc.maxRequestSize)
+		o.grpcServer = grpcServer
+
+		o.wg.Add(1)
+		go func() {
+			defer o.wg.Done()
+			if err := grpcServer.Serve(listener); err != nil {
+				o.Errorw("grpc server failed", zap.Error(err))
+			}
+		}()
+	}
+
+	if o.http != nil {
+		listener, err := net.Listen("tcp", o.http.address)
+		if err != nil {
+			o.stopGRPC()
+			return fmt.Errorf("failed to listen for http: %w", err)
+		}
+		if o.http.tls != nil {
+			listener = tls.NewListener(listener, o.http.tls)
+		}
+		o.http.listener = listener
+		httpServer := newHTTPServer(o, o.http.maxRequestSize)
+		o.httpServer = httpServer
+
+		o.wg.Add(1)
+		go func() {
+			defer o.wg.Done()
+			if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+				o.Errorw("http server failed", zap.Error(err))
+			}
+		}()
+	}
+
+	return nil
+}
+
+// Stop will stop listening, after waiting for requests in progress to complete
+func (o *OTLPInput) Stop() error {
+	o.stopGRPC()
+
+	if o.httpServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := o.httpServer.Shutdown(ctx); err != nil {
+			o.Errorw("Failed to shut down http server", zap.Error(err))
+			_ = o.httpServer.Close()
+		}
+		o.httpServer = nil
+	}
+
+	o.wg.Wait()
+	return nil
+}
+
+func (o *OTLPInput) stopGRPC() {
+	if o.grpcServer != nil {
+		o.grpcServer.GracefulStop()
+		o.grpcServer = nil
+	}
+}
+
+// consume writes an entry for each log record in logs. No entries are written unless
+// every record can be converted, so a failed request can be retried without duplicates.
+// Entries are written with a new context, since they may outlive the request that they were received in.
+func (o *OTLPInput) consume(logs pdata.Logs) error {
+	entries := convert.ConvertFrom(logs)
+	for _, e := range entries {
+		if e.Timestamp.IsZero() {
+			e.Timestamp = time.Now()
+		}
+
+		if err := o.Attribute(e); err != nil {
+			return fmt.Errorf("add attributes to entry: %s", err)
+		}
+
+		if err := o.Identify(e); err != nil {
+			return fmt.Errorf("add resource keys to entry: %s", err)
+		}
+	}
+
+	ctx := context.Background()
+	for _, e := range entries {
+		o.Write(ctx, e)
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestInput(t *testing.T, cfg *OTLPInputConfig) (*OTLPInput, *testutil.FakeOutput) {
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*OTLPInput)
+
+	fake := testutil.NewFakeOutput(t)
+	op.OutputOperators = []operator.Operator{fake}
+
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, op.Stop()) })
+	return op, fake
+}
+
+func newTestRequest() otlpgrpc.LogsRequest {
+	logs := pdata.NewLogs()
+	rls := logs.ResourceLogs().AppendEmpty()
+	rls.Resource().Attributes().InsertString("host", "example")
+
+	record := rls.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+	record.SetTimestamp(pdata.NewTimestampFromTime(time.Date(2022, time.January, 2, 3, 4, 5, 0, time.UTC)))
+	record.Body().SetStringVal("hello")
+	record.Attributes().InsertInt("count", 1)
+	record.SetSeverityNumber(pdata.SeverityNumberWARN)
+	record.SetSeverityText("WARN")
+	record.SetTraceID(pdata.NewTraceID([16]byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff}))
+	record.SetSpanID(pdata.NewSpanID([8]byte{0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff}))
+	record.SetFlags(1)
+
+	request := otlpgrpc.NewLogsRequest()
+	request.SetLogs(logs)
+	return request
+}
+
+func expectedEntry() *entry.Entry {
+	return &entry.Entry{
+		Timestamp:    time.Date(2022, time.January, 2, 3, 4, 5, 0, time.UTC),
+		Body:         "hello",
+		Attributes:   map[string]interface{}{"count": int64(1)},
+		Resource:     map[string]interface{}{"host": "example"},
+		Severity:     entry.Warn,
+		SeverityText: "WARN",
+		TraceId:      []byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+		SpanId:       []byte{0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+		TraceFlags:   []byte{0x01},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(*OTLPInputConfig)
+	}{
+		{"NoServers", func(cfg *OTLPInputConfig) {}},
+		{"MissingGRPCAddress", func(cfg *OTLPInputConfig) { cfg.GRPC = &ServerConfig{} }},
+		{"InvalidHTTPAddress", func(cfg *OTLPInputConfig) { cfg.HTTP = &ServerConfig{ListenAddress: "localhost:notaport"} }},
+		{"NegativeMaxRequestSize", func(cfg *OTLPInputConfig) {
+			cfg.HTTP = &ServerConfig{ListenAddress: "localhost:0", MaxRequestSize: -1}
+		}},
+		{"MissingCertFile", func(cfg *OTLPInputConfig) {
+			cfg.GRPC = &ServerConfig{ListenAddress: "localhost:0"}
+			cfg.GRPC.TLS = helper.NewTLSServerConfig(&configtls.TLSServerSetting{
+				TLSSetting: configtls.TLSSetting{
+					CertFile: "/does/not/exist.crt",
+					KeyFile:  "/does/not/exist.key",
+				},
+			})
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewOTLPInputConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	cfg := NewOTLPInputConfig("test")
+	cfg.GRPC = &ServerConfig{ListenAddress: "127.0.0.1:0"}
+	op, fake := newTestInput(t, cfg)
+
+	conn, err := grpc.Dial(op.grpc.listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = otlpgrpc.NewLogsClient(conn).Export(ctx, newTestRequest())
+	require.NoError(t, err)
+
+	fake.ExpectEntry(t, expectedEntry())
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
+
+func TestGRPCAddsConfiguredFields(t *testing.T) {
+	cfg := NewOTLPInputConfig("test")
+	cfg.GRPC = &ServerConfig{ListenAddress: "127.0.0.1:0"}
+	cfg.Attributes = map[string]helper.ExprStringConfig{"source": "otlp"}
+	op, fake := newTestInput(t, cfg)
+
+	conn, err := grpc.Dial(op.grpc.listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	request := otlpgrpc.NewLogsRequest()
+	logs := pdata.NewLogs()
+	logs.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty().Body().SetStringVal("untimed")
+	request.SetLogs(logs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = otlpgrpc.NewLogsClient(conn).Export(ctx, request)
+	require.NoError(t, err)
+
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, "untimed", e.Body)
+		require.Equal(t, map[string]interface{}{"source": "otlp"}, e.Attributes)
+		require.False(t, e.Timestamp.IsZero())
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
+
+func TestGRPCRejectsLargeRequests(t *testing.T) {
+	cfg := NewOTLPInputConfig("test")
+	cfg.GRPC = &ServerConfig{ListenAddress: "127.0.0.1:0", MaxRequestSize: 100}
+	op, fake := newTestInput(t, cfg)
+
+	conn, err := grpc.Dial(op.grpc.listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	request := newTestRequest()
+	request.Logs().ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Body().SetStringVal(strings.Repeat("a", 200))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = otlpgrpc.NewLogsClient(conn).Export(ctx, request)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
+
+func TestConsumeWritesNothingOnError(t *testing.T) {
+	cfg := NewOTLPInputConfig("test")
+	cfg.GRPC = &ServerConfig{ListenAddress: "127.0.0.1:0"}
+	cfg.Attributes = map[string]helper.ExprStringConfig{"check": `EXPR($body == "bad" ? 1 : "ok")`}
+	op, fake := newTestInput(t, cfg)
+
+	logs := pdata.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty().Logs()
+	records.AppendEmpty().Body().SetStringVal("good")
+	records.AppendEmpty().Body().SetStringVal("bad")
+
+	require.Error(t, op.consume(logs))
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
+
+func newHTTPInput(t *testing.T) (string, *testutil.FakeOutput) {
+	return newHTTPInputWithLimit(t, 0)
+}
+
+func newHTTPInputWithLimit(t *testing.T, maxRequestSize helper.ByteSize) (string, *testutil.FakeOutput) {
+	cfg := NewOTLPInputConfig("test")
+	cfg.HTTP = &ServerConfig{ListenAddress: "127.0.0.1:0", MaxRequestSize: maxRequestSize}
+	op, fake := newTestInput(t, cfg)
+	return "http://" + op.http.listener.Addr().String() + "/v1/logs", fake
+}
+
+func TestHTTPProtobuf(t *testing.T) {
+	url, fake := newHTTPInput(t)
+
+	body, err := newTestRequest().Marshal()
+	require.NoError(t, err)
+
+	resp, err := http.Post(url, "application/x-protobuf", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/x-protobuf", resp.Header.Get("Content-Type"))
+
+	fake.ExpectEntry(t, expectedEntry())
+}
+
+func TestHTTPGzip(t *testing.T) {
+	url, fake := newHTTPInput(t)
+
+	raw, err := newTestRequest().Marshal()
+	require.NoError(t, err)
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, err = gz.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	req, err := http.NewRequest(http.MethodPost, url, &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	fake.ExpectEntry(t, expectedEntry())
+}
+
+func TestHTTPJSON(t *testing.T) {
+	url, fake := newHTTPInput(t)
+
+	body, err := newTestRequest().MarshalJSON()
+	require.NoError(t, err)
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	fake.ExpectEntry(t, expectedEntry())
+}
+
+func TestHTTPRejectsInvalidRequests(t *testing.T) {
+	url, fake := newHTTPInput(t)
+
+	cases := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"Method", http.MethodGet, "application/x-protobuf", "", http.StatusMethodNotAllowed},
+		{"ContentType", http.MethodPost, "text/plain", "hello", http.StatusUnsupportedMediaType},
+		{"Body", http.MethodPost, "application/json", "{not json", http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, url, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tc.contentType)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tc.expected, resp.StatusCode)
+		})
+	}
+
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
+
+func TestHTTPRejectsLargeRequests(t *testing.T) {
+	url, fake := newHTTPInputWithLimit(t, 1000)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write(bytes.Repeat([]byte{'a'}, 100000))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	cases := []struct {
+		name     string
+		body     []byte
+		encoding string
+		chunked  bool
+	}{
+		{"ContentLength", bytes.Repeat([]byte{'a'}, 2000), "", false},
+		{"Chunked", bytes.Repeat([]byte{'a'}, 2000), "", true},
+		{"Decompressed", compressed.Bytes(), "gzip", false},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// A reader without a length is sent with chunked encoding
+			var body io.Reader = bytes.NewReader(tc.body)
+			if tc.chunked {
+				body = io.MultiReader(body)
+			}
+			req, err := http.NewRequest(http.MethodPost, url, body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-protobuf")
+			req.Header.Set("Content-Encoding", tc.encoding)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		})
+	}
+
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // register the gzip decompressor
+	"google.golang.org/grpc/status"
+)
+
+// errTooLarge is returned when a request body is larger than the limit once decompressed
+var errTooLarge = errors.New("request body too large")
+
+const (
+	logsPath = "/v1/logs"
+
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// grpcLogsServer receives logs sent with OTLP over gRPC
+type grpcLogsServer struct {
+	input *OTLPInput
+}
+
+func newGRPCServer(input *OTLPInput, tlsConfig *tls.Config, maxRequestSize int64) *grpc.Server {
+	// The limit applies to messages after they are decompressed
+	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(int(maxRequestSize))}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s := grpc.NewServer(opts...)
+	otlpgrpc.RegisterLogsServer(s, &grpcLogsServer{input: input})
+	return s
+}
+
+// Export will write the logs in a request as entries
+func (s *grpcLogsServer) Export(_ context.Context, request otlpgrpc.LogsRequest) (otlpgrpc.LogsResponse, error) {
+	if err := s.input.consume(request.Logs()); err != nil {
+		return otlpgrpc.NewLogsResponse(), status.Error(codes.Internal, err.Error())
+	}
+	return otlpgrpc.NewLogsResponse(), nil
+}
+
+// httpLogsHandler receives logs sent with OTLP over HTTP, encoded as protobuf or JSON
+type httpLogsHandler struct {
+	input *OTLPInput
+
+	// maxRequestSize limits the size of a request body, both before and after it is decompressed
+	maxRequestSize int64
+}
+
+func newHTTPServer(input *OTLPInput, maxRequestSize int64) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(logsPath, &httpLogsHandler{input: input, maxRequestSize: maxRequestSize})
+	return &http.Server{Handler: mux}
+}
+
+func (h *httpLogsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	contentType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || (contentType != contentTypeProtobuf && contentType != contentTypeJSON) {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if req.ContentLength > h.maxRequestSize {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	raw, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, h.maxRequestSize))
+	if int64(len(raw)) >= h.maxRequestSize && err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		raw, err = h.decompress(raw)
+		if err == errTooLarge {
+			http.Error(w, "decompressed request body too large", http.StatusRequestEntityTooLarge)
+			return
+		} else if err != nil {
+			http.Error(w, "invalid gzip body", http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var request otlpgrpc.LogsRequest
+	if contentType == contentTypeJSON {
+		request, err = otlpgrpc.UnmarshalJSONLogsRequest(raw)
+	} else {
+		request, err = otlpgrpc.UnmarshalLogsRequest(raw)
+	}
+	if err != nil {
+		http.Error(w, "failed to decode request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.input.consume(request.Logs()); err != nil {
+		h.input.Errorw("Failed to consume logs", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := otlpgrpc.NewLogsResponse()
+	var encoded []byte
+	if contentType == contentTypeJSON {
+		encoded, err = response.MarshalJSON()
+	} else {
+		encoded, err = response.Marshal()
+	}
+	if err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(encoded)
+}
+
+// decompress decompresses a gzip request body, up to the maximum size of a request
+func (h *httpLogsHandler) decompress(compressed []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	raw, err := ioutil.ReadAll(io.LimitReader(gz, h.maxRequestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(raw)) > h.maxRequestSize {
+		return nil, errTooLarge
+	}
+	return raw, nil
+}
@@ -119,7 +119,7 @@ func udpInputAttributesTest(input []byte, expected []string) func(t *testing.T)
 		for _, expectedBody := range expected {
 			select {
 			case entry := <-entryChan:
-				expectedAttributes := map[string]string{
+				expectedAttributes := map[string]interface{}{
 					"net.transport": "IP.UDP",
 				}
 				// LocalAddr for udpInput.connection is a server address
@@ -37,6 +37,7 @@ type EventLogConfig struct {
 	MaxReads           int             `mapstructure:"max_reads,omitempty" json:"max_reads,omitempty" yaml:"max_reads,omitempty"`
 	StartAt            string          `mapstructure:"start_at,omitempty" json:"start_at,omitempty" yaml:"start_at,omitempty"`
 	PollInterval       helper.Duration `mapstructure:"poll_interval,omitempty" json:"poll_interval,omitempty" yaml:"poll_interval,omitempty"`
+	WaitForAck         bool            `mapstructure:"wait_for_ack,omitempty" json:"wait_for_ack,omitempty" yaml:"wait_for_ack,omitempty"`
 }
 
 // Build will build a windows event log operator.
@@ -65,6 +66,7 @@ func (c *EventLogConfig) Build(context operator.BuildContext) ([]operator.Operat
 		maxReads:      c.MaxReads,
 		startAt:       c.StartAt,
 		pollInterval:  c.PollInterval,
+		waitForAck:    c.WaitForAck,
 	}
 	return []operator.Operator{eventLogInput}, nil
 }
@@ -91,6 +93,8 @@ type EventLogInput struct {
 	maxReads     int
 	startAt      string
 	pollInterval helper.Duration
+	waitForAck   bool
+	acks         *helper.CheckpointTracker
 	persister    operator.Persister
 	cancel       context.CancelFunc
 	wg           sync.WaitGroup
@@ -116,6 +120,17 @@ func (e *EventLogInput) Start(persister operator.Persister) error {
 		}
 	}
 
+	// When waiting for acknowledgements, the bookmark is only saved once the event
+	// it points to and every event before it have been delivered
+	if e.waitForAck {
+		e.acks = helper.NewCheckpointTracker(offsetXML, func(checkpoint interface{}) {
+			// Events may be acknowledged after the operator has stopped
+			if err := e.persister.Set(context.Background(), e.channel, []byte(checkpoint.(string))); err != nil {
+				e.Errorf("failed to set offsets: %s", err)
+			}
+		})
+	}
+
 	e.subscription = NewSubscription()
 	if err := e.subscription.Open(e.channel, e.startAt, e.bookmark); err != nil {
 		return fmt.Errorf("failed to open subscription: %s", err)
@@ -161,6 +176,13 @@ func (e *EventLogInput) readOnInterval(ctx context.Context) {
 
 // readToEnd will read events from the subscription until it reaches the end of the channel.
 func (e *EventLogInput) readToEnd(ctx context.Context) {
+	if e.acks != nil && e.acks.Stalled() {
+		if err := e.rewind(); err != nil {
+			e.Errorf("Failed to read again from the last delivered event: %s", err)
+			return
+		}
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -169,6 +191,10 @@ func (e *EventLogInput) readToEnd(ctx context.Context) {
 			if count := e.read(ctx); count == 0 {
 				return
 			}
+			// The failed event is read again on the next interval
+			if e.acks != nil && e.acks.Stalled() {
+				return
+			}
 		}
 	}
 }
@@ -182,9 +208,16 @@ func (e *EventLogInput) read(ctx context.Context) int {
 	}
 
 	for i, event := range events {
-		e.processEvent(ctx, event)
-		if len(events) == i+1 {
-			e.updateBookmarkOffset(ctx, event)
+		if e.acks != nil {
+			// The events after a failed event are read again with it
+			if !e.acks.Stalled() {
+				e.processTrackedEvent(ctx, event)
+			}
+		} else {
+			e.processEvent(ctx, event)
+			if len(events) == i+1 {
+				e.updateBookmarkOffset(ctx, event)
+			}
 		}
 		event.Close()
 	}
@@ -192,11 +225,49 @@ func (e *EventLogInput) read(ctx context.Context) int {
 	return len(events)
 }
 
+// rewind will reopen the subscription from the bookmark of the last delivered event,
+// so that a failed event and the events after it are read again.
+func (e *EventLogInput) rewind() error {
+	offsetXML := e.acks.Rewind().(string)
+
+	if err := e.subscription.Close(); err != nil {
+		return fmt.Errorf("failed to close subscription: %s", err)
+	}
+	if err := e.bookmark.Close(); err != nil {
+		return fmt.Errorf("failed to close bookmark: %s", err)
+	}
+
+	e.bookmark = NewBookmark()
+	if offsetXML != "" {
+		if err := e.bookmark.Open(offsetXML); err != nil {
+			return fmt.Errorf("failed to open bookmark: %s", err)
+		}
+	}
+
+	e.subscription = NewSubscription()
+	if err := e.subscription.Open(e.channel, e.startAt, e.bookmark); err != nil {
+		return fmt.Errorf("failed to open subscription: %s", err)
+	}
+	return nil
+}
+
+// processTrackedEvent will process an event with a context that saves
+// the bookmark of the event once it has been delivered.
+func (e *EventLogInput) processTrackedEvent(ctx context.Context, event Event) {
+	bookmarkXML, err := e.renderBookmark(event)
+	if err != nil {
+		e.processEvent(ctx, event)
+		return
+	}
+	e.processEvent(e.acks.Track(ctx, bookmarkXML), event)
+}
+
 // processEvent will process and send an event retrieved from windows event log.
 func (e *EventLogInput) processEvent(ctx context.Context, event Event) {
 	simpleEvent, err := event.RenderSimple(e.buffer)
 	if err != nil {
 		e.Errorf("Failed to render simple event: %s", err)
+		helper.Ack(ctx)
 		return
 	}
 
@@ -224,6 +295,7 @@ func (e *EventLogInput) sendEvent(ctx context.Context, eventXML EventXML) {
 	entry, err := e.NewEntry(body)
 	if err != nil {
 		e.Errorf("Failed to create entry: %s", err)
+		helper.Ack(ctx)
 		return
 	}
 
@@ -240,14 +312,8 @@ func (e *EventLogInput) getBookmarkOffset(ctx context.Context) (string, error) {
 
 // updateBookmark will update the bookmark xml and save it in the offsets database.
 func (e *EventLogInput) updateBookmarkOffset(ctx context.Context, event Event) {
-	if err := e.bookmark.Update(event); err != nil {
-		e.Errorf("Failed to update bookmark from event: %s", err)
-		return
-	}
-
-	bookmarkXML, err := e.bookmark.Render(e.buffer)
+	bookmarkXML, err := e.renderBookmark(event)
 	if err != nil {
-		e.Errorf("Failed to render bookmark xml: %s", err)
 		return
 	}
 
@@ -256,3 +322,18 @@ func (e *EventLogInput) updateBookmarkOffset(ctx context.Context, event Event) {
 		return
 	}
 }
+
+// renderBookmark will update the bookmark to point to the event and render it as xml.
+func (e *EventLogInput) renderBookmark(event Event) (string, error) {
+	if err := e.bookmark.Update(event); err != nil {
+		e.Errorf("Failed to update bookmark from event: %s", err)
+		return "", err
+	}
+
+	bookmarkXML, err := e.bookmark.Render(e.buffer)
+	if err != nil {
+		e.Errorf("Failed to render bookmark xml: %s", err)
+		return "", err
+	}
+	return bookmarkXML, nil
+}
@@ -59,5 +59,8 @@ type DropOutput struct {
 
 // Process will drop the incoming entry.
 func (p *DropOutput) Process(ctx context.Context, entry *entry.Entry) error {
+	p.Telemetry.Received()
+	p.Telemetry.Dropped()
+	helper.Ack(ctx)
 	return nil
 }
@@ -21,6 +21,7 @@ import (
 	"html/template"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
@@ -111,20 +112,23 @@ func (fo *FileOutput) Stop() error {
 
 // Process will write an entry to the output file.
 func (fo *FileOutput) Process(ctx context.Context, entry *entry.Entry) error {
+	start := time.Now()
+	fo.Telemetry.Received()
 	fo.mux.Lock()
 	defer fo.mux.Unlock()
 
+	var err error
 	if fo.tmpl != nil {
-		err := fo.tmpl.Execute(fo.file, entry)
-		if err != nil {
-			return err
-		}
+		err = fo.tmpl.Execute(fo.file, entry)
 	} else {
-		err := fo.encoder.Encode(entry)
-		if err != nil {
-			return err
-		}
+		err = fo.encoder.Encode(entry)
 	}
 
-	return nil
+	if err != nil {
+		fo.Telemetry.Failed()
+	} else {
+		fo.Telemetry.ObserveSince(start)
+	}
+	helper.AckResult(ctx, err)
+	return err
 }
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configtls"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestUnmarshal(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "http",
+			Expect: func() *OTLPOutputConfig {
+				cfg := defaultCfg()
+				cfg.Endpoint = "https://collector:4318/v1/logs"
+				cfg.Protocol = ProtocolHTTP
+				cfg.Compression = CompressionNone
+				cfg.Headers = map[string]string{"x-api-key": "secret"}
+				return cfg
+			}(),
+		},
+		{
+			Name: "batch",
+			Expect: func() *OTLPOutputConfig {
+				cfg := defaultCfg()
+				cfg.MaxBatchSize = 500
+				cfg.FlushInterval = helper.NewDuration(5 * time.Second)
+				cfg.Timeout = helper.NewDuration(30 * time.Second)
+				return cfg
+			}(),
+		},
+		{
+			Name: "retry",
+			Expect: func() *OTLPOutputConfig {
+				cfg := defaultCfg()
+				cfg.Retry.MaxRetries = 10
+				cfg.Retry.InitialInterval = helper.NewDuration(time.Second)
+				cfg.Retry.MaxInterval = helper.NewDuration(time.Minute)
+				return cfg
+			}(),
+		},
+		{
+			Name: "tls",
+			Expect: func() *OTLPOutputConfig {
+				cfg := defaultCfg()
+				cfg.TLS = helper.NewTLSClientConfig(&configtls.TLSClientSetting{
+					TLSSetting: configtls.TLSSetting{
+						CAFile: "/tmp/ca.crt",
+					},
+					InsecureSkipVerify: true,
+				})
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *OTLPOutputConfig {
+	cfg := NewOTLPOutputConfig("otlp_output")
+	cfg.Endpoint = "localhost:4317"
+	return cfg
+}
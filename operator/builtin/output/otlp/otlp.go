@@ -0,0 +1,344 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jpillora/backoff"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/convert"
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("otlp_output", func() operator.Builder { return NewOTLPOutputConfig("") })
+}
+
+const (
+	// ProtocolGRPC sends logs using OTLP over gRPC
+	ProtocolGRPC = "grpc"
+	// ProtocolHTTP sends logs using OTLP over HTTP with protobuf encoding
+	ProtocolHTTP = "http"
+
+	// CompressionGzip compresses requests with gzip
+	CompressionGzip = "gzip"
+	// CompressionNone sends requests uncompressed
+	CompressionNone = "none"
+
+	defaultMaxBatchSize    = 100
+	defaultFlushInterval   = time.Second
+	defaultTimeout         = 10 * time.Second
+	defaultMaxRetries      = 5
+	defaultInitialInterval = 500 * time.Millisecond
+	defaultMaxInterval     = 10 * time.Second
+)
+
+// NewOTLPOutputConfig creates a new otlp output config with default values
+func NewOTLPOutputConfig(operatorID string) *OTLPOutputConfig {
+	return &OTLPOutputConfig{
+		OutputConfig:  helper.NewOutputConfig(operatorID, "otlp_output"),
+		Protocol:      ProtocolGRPC,
+		Compression:   CompressionGzip,
+		Timeout:       helper.NewDuration(defaultTimeout),
+		MaxBatchSize:  defaultMaxBatchSize,
+		FlushInterval: helper.NewDuration(defaultFlushInterval),
+		Retry:         NewRetryConfig(),
+	}
+}
+
+// OTLPOutputConfig is the configuration of an otlp output operator
+type OTLPOutputConfig struct {
+	helper.OutputConfig `mapstructure:",squash" yaml:",inline"`
+
+	Endpoint      string                  `mapstructure:"endpoint"       json:"endpoint"                 yaml:"endpoint"`
+	Protocol      string                  `mapstructure:"protocol"       json:"protocol,omitempty"       yaml:"protocol,omitempty"`
+	Compression   string                  `mapstructure:"compression"    json:"compression,omitempty"    yaml:"compression,omitempty"`
+	Headers       map[string]string       `mapstructure:"headers"        json:"headers,omitempty"        yaml:"headers,omitempty"`
+	Timeout       helper.Duration         `mapstructure:"timeout"        json:"timeout,omitempty"        yaml:"timeout,omitempty"`
+	TLS           *helper.TLSClientConfig `mapstructure:"tls"            json:"tls,omitempty"            yaml:"tls,omitempty"`
+	MaxBatchSize  int                     `mapstructure:"max_batch_size" json:"max_batch_size,omitempty" yaml:"max_batch_size,omitempty"`
+	FlushInterval helper.Duration         `mapstructure:"flush_interval" json:"flush_interval,omitempty" yaml:"flush_interval,omitempty"`
+	Retry         RetryConfig             `mapstructure:"retry"          json:"retry,omitempty"          yaml:"retry,omitempty"`
+}
+
+// NewRetryConfig creates a new retry config with default values
+func NewRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:      defaultMaxRetries,
+		InitialInterval: helper.NewDuration(defaultInitialInterval),
+		MaxInterval:     helper.NewDuration(defaultMaxInterval),
+	}
+}
+
+// RetryConfig is the configuration of how failed requests are retried
+type RetryConfig struct {
+	MaxRetries      int             `mapstructure:"max_retries"      json:"max_retries"                yaml:"max_retries"`
+	InitialInterval helper.Duration `mapstructure:"initial_interval" json:"initial_interval,omitempty" yaml:"initial_interval,omitempty"`
+	MaxInterval     helper.Duration `mapstructure:"max_interval"     json:"max_interval,omitempty"     yaml:"max_interval,omitempty"`
+}
+
+// Build will build an otlp output operator
+func (c OTLPOutputConfig) Build(bc operator.BuildContext) ([]operator.Operator, error) {
+	outputOperator, err := c.OutputConfig.Build(bc)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Endpoint == "" {
+		return nil, fmt.Errorf("missing required parameter 'endpoint'")
+	}
+
+	switch c.Protocol {
+	case ProtocolGRPC, ProtocolHTTP:
+	default:
+		return nil, fmt.Errorf("invalid `protocol` '%s', must be '%s' or '%s'", c.Protocol, ProtocolGRPC, ProtocolHTTP)
+	}
+
+	switch c.Compression {
+	case CompressionGzip, CompressionNone:
+	default:
+		return nil, fmt.Errorf("invalid `compression` '%s', must be '%s' or '%s'", c.Compression, CompressionGzip, CompressionNone)
+	}
+
+	if c.MaxBatchSize <= 0 {
+		return nil, fmt.Errorf("`max_batch_size` must be positive")
+	}
+
+	if c.FlushInterval.Raw() <= 0 {
+		return nil, fmt.Errorf("`flush_interval` must be positive")
+	}
+
+	if c.Timeout.Raw() <= 0 {
+		return nil, fmt.Errorf("`timeout` must be positive")
+	}
+
+	if c.Retry.MaxRetries < 0 {
+		return nil, fmt.Errorf("`retry.max_retries` must not be negative")
+	}
+
+	var tlsConfig *tls.Config
+	if c.TLS != nil && c.TLS.TLSClientSetting != nil {
+		tlsConfig, err = c.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls config: %s", err)
+		}
+	}
+
+	otlpOutput := &OTLPOutput{
+		OutputOperator: outputOperator,
+		endpoint:       c.Endpoint,
+		protocol:       c.Protocol,
+		compression:    c.Compression,
+		headers:        c.Headers,
+		timeout:        c.Timeout.Raw(),
+		tls:            tlsConfig,
+		maxBatchSize:   c.MaxBatchSize,
+		flushInterval:  c.FlushInterval.Raw(),
+		maxRetries:     c.Retry.MaxRetries,
+		backoff: backoff.Backoff{
+			Min:    c.Retry.InitialInterval.Raw(),
+			Max:    c.Retry.MaxInterval.Raw(),
+			Factor: 2,
+			Jitter: true,
+		},
+		entries: make(chan queuedEntry, c.MaxBatchSize),
+	}
+
+	return []operator.Operator{otlpOutput}, nil
+}
+
+// queuedEntry is an entry waiting to be sent, along with the context it was written with
+type queuedEntry struct {
+	ctx   context.Context
+	entry *entry.Entry
+}
+
+// OTLPOutput is an operator that sends entries to an OTLP endpoint
+type OTLPOutput struct {
+	helper.OutputOperator
+
+	endpoint      string
+	protocol      string
+	compression   string
+	headers       map[string]string
+	timeout       time.Duration
+	tls           *tls.Config
+	maxBatchSize  int
+	flushInterval time.Duration
+	maxRetries    int
+	backoff       backoff.Backoff
+
+	sender  sender
+	entries chan queuedEntry
+	stopped bool
+	stop    chan struct{}
+	done    chan struct{}
+	mux     sync.RWMutex
+	wg      sync.WaitGroup
+}
+
+// Start will connect to the endpoint and begin sending batches of entries
+func (o *OTLPOutput) Start(_ operator.Persister) error {
+	var err error
+	switch o.protocol {
+	case ProtocolHTTP:
+		o.sender, err = newHTTPSender(o.endpoint, o.compression, o.headers, o.timeout, o.tls)
+	default:
+		o.sender, err = newGRPCSender(o.endpoint, o.compression, o.headers, o.tls)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %s client: %s", o.protocol, err)
+	}
+
+	o.stopped = false
+	o.stop = make(chan struct{})
+	o.done = make(chan struct{})
+	o.wg.Add(1)
+	go o.run()
+	return nil
+}
+
+// Stop will send any remaining entries and close the connection to the endpoint.
+// Requests that are being retried when the operator stops are abandoned.
+func (o *OTLPOutput) Stop() error {
+	if o.stop == nil {
+		return nil
+	}
+	select {
+	case <-o.stop:
+		return nil
+	default:
+	}
+	// Unblock any writers, and wait for them to finish before draining the remaining entries
+	close(o.stop)
+	o.mux.Lock()
+	o.stopped = true
+	o.mux.Unlock()
+	close(o.done)
+	o.wg.Wait()
+
+	if err := o.sender.close(); err != nil {
+		o.Errorw("Failed to close client", zap.Error(err))
+	}
+	return nil
+}
+
+// Process will add an entry to the current batch
+func (o *OTLPOutput) Process(ctx context.Context, entry *entry.Entry) error {
+	o.Telemetry.Received()
+	o.mux.RLock()
+	defer o.mux.RUnlock()
+	if o.stopped {
+		helper.Nack(ctx)
+		return fmt.Errorf("otlp output is stopped")
+	}
+
+	select {
+	case o.entries <- queuedEntry{ctx: ctx, entry: entry}:
+		return nil
+	case <-o.stop:
+		helper.Nack(ctx)
+		return fmt.Errorf("otlp output is stopped")
+	}
+}
+
+// run collects entries into batches, sending each batch once it is full or the flush interval elapses
+func (o *OTLPOutput) run() {
+	defer o.wg.Done()
+
+	ticker := time.NewTicker(o.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]queuedEntry, 0, o.maxBatchSize)
+	for {
+		select {
+		case item := <-o.entries:
+			batch = append(batch, item)
+			if len(batch) < o.maxBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-o.done:
+			for len(o.entries) > 0 {
+				batch = append(batch, <-o.entries)
+			}
+			o.flush(batch)
+			return
+		}
+
+		o.flush(batch)
+		batch = make([]queuedEntry, 0, o.maxBatchSize)
+	}
+}
+
+// flush sends a batch of entries, retrying retryable failures, and acknowledges each entry with the result
+func (o *OTLPOutput) flush(batch []queuedEntry) {
+	if len(batch) == 0 {
+		return
+	}
+
+	entries := make([]*entry.Entry, 0, len(batch))
+	for _, item := range batch {
+		entries = append(entries, item.entry)
+	}
+	logs := convert.ConvertBatch(entries)
+
+	err := o.send(logs)
+	if err != nil {
+		o.Errorw("Failed to send logs", zap.Error(err), "endpoint", o.endpoint, "count", len(batch))
+		o.Telemetry.Failed()
+	}
+
+	for _, item := range batch {
+		helper.AckResult(item.ctx, err)
+	}
+}
+
+func (o *OTLPOutput) send(logs pdata.Logs) error {
+	o.backoff.Reset()
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+		err := o.sender.send(ctx, logs)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		if permanent, ok := err.(*permanentError); ok {
+			return permanent.err
+		}
+
+		if attempt >= o.maxRetries {
+			return err
+		}
+
+		wait := o.backoff.Duration()
+		o.Warnw("Failed to send logs, retrying", zap.Error(err), "attempt", attempt+1, "backoff", wait)
+		select {
+		case <-o.stop:
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
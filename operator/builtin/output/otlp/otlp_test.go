@@ -0,0 +1,352 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// receiver is an in-process OTLP receiver that records the logs it is sent
+type receiver struct {
+	mux      sync.Mutex
+	logs     []pdata.Logs
+	headers  []string
+	failures []error
+}
+
+func (r *receiver) receive(logs pdata.Logs, header string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if len(r.failures) > 0 {
+		err := r.failures[0]
+		r.failures = r.failures[1:]
+		return err
+	}
+	r.logs = append(r.logs, logs)
+	r.headers = append(r.headers, header)
+	return nil
+}
+
+func (r *receiver) Export(ctx context.Context, request otlpgrpc.LogsRequest) (otlpgrpc.LogsResponse, error) {
+	header := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("x-api-key")) > 0 {
+		header = md.Get("x-api-key")[0]
+	}
+	return otlpgrpc.NewLogsResponse(), r.receive(request.Logs(), header)
+}
+
+func (r *receiver) bodies() []string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	var bodies []string
+	for _, logs := range r.logs {
+		bodies = append(bodies, logsToBodies(logs)...)
+	}
+	return bodies
+}
+
+func (r *receiver) requestCount() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return len(r.logs)
+}
+
+func logsToBodies(logs pdata.Logs) []string {
+	var bodies []string
+	rls := logs.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			records := ills.At(j).Logs()
+			for k := 0; k < records.Len(); k++ {
+				bodies = append(bodies, records.At(k).Body().StringVal())
+			}
+		}
+	}
+	return bodies
+}
+
+func newGRPCReceiver(t *testing.T) (*receiver, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	r := &receiver{}
+	server := grpc.NewServer()
+	otlpgrpc.RegisterLogsServer(server, r)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return r, listener.Addr().String()
+}
+
+func newHTTPHandler(t *testing.T, r *receiver) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "/v1/logs", req.URL.Path)
+		require.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+
+		var body io.Reader = req.Body
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(req.Body)
+			require.NoError(t, err)
+			body = gz
+		}
+		raw, err := ioutil.ReadAll(body)
+		require.NoError(t, err)
+
+		request, err := otlpgrpc.UnmarshalLogsRequest(raw)
+		require.NoError(t, err)
+
+		if err := r.receive(request.Logs(), req.Header.Get("x-api-key")); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func newTestOutput(t *testing.T, cfg *OTLPOutputConfig) *OTLPOutput {
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*OTLPOutput)
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, op.Stop()) })
+	return op
+}
+
+func newTestConfig(endpoint string) *OTLPOutputConfig {
+	cfg := NewOTLPOutputConfig("test")
+	cfg.Endpoint = endpoint
+	cfg.FlushInterval = helper.NewDuration(10 * time.Millisecond)
+	cfg.Retry.InitialInterval = helper.NewDuration(time.Millisecond)
+	cfg.Retry.MaxInterval = helper.NewDuration(10 * time.Millisecond)
+	return cfg
+}
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(*OTLPOutputConfig)
+	}{
+		{"MissingEndpoint", func(cfg *OTLPOutputConfig) { cfg.Endpoint = "" }},
+		{"InvalidProtocol", func(cfg *OTLPOutputConfig) { cfg.Protocol = "udp" }},
+		{"InvalidCompression", func(cfg *OTLPOutputConfig) { cfg.Compression = "zstd" }},
+		{"ZeroBatchSize", func(cfg *OTLPOutputConfig) { cfg.MaxBatchSize = 0 }},
+		{"ZeroFlushInterval", func(cfg *OTLPOutputConfig) { cfg.FlushInterval = helper.NewDuration(0) }},
+		{"ZeroTimeout", func(cfg *OTLPOutputConfig) { cfg.Timeout = helper.NewDuration(0) }},
+		{"NegativeRetries", func(cfg *OTLPOutputConfig) { cfg.Retry.MaxRetries = -1 }},
+		{"MissingCAFile", func(cfg *OTLPOutputConfig) {
+			cfg.TLS = helper.NewTLSClientConfig(&configtls.TLSClientSetting{
+				TLSSetting: configtls.TLSSetting{CAFile: "/does/not/exist"},
+			})
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := newTestConfig("localhost:4317")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLogsURL(t *testing.T) {
+	cases := []struct {
+		endpoint string
+		secure   bool
+		expected string
+	}{
+		{"localhost:4318", false, "http://localhost:4318/v1/logs"},
+		{"localhost:4318", true, "https://localhost:4318/v1/logs"},
+		{"http://localhost:4318/", false, "http://localhost:4318/v1/logs"},
+		{"https://collector/custom/logs", false, "https://collector/custom/logs"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.endpoint, func(t *testing.T) {
+			url, err := logsURL(tc.endpoint, tc.secure)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, url)
+		})
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	r, endpoint := newGRPCReceiver(t)
+	cfg := newTestConfig(endpoint)
+	cfg.Headers = map[string]string{"x-api-key": "secret"}
+	op := newTestOutput(t, cfg)
+
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "one"}))
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "two"}))
+
+	require.Eventually(t, func() bool { return len(r.bodies()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"one", "two"}, r.bodies())
+	require.Equal(t, "secret", r.headers[0])
+}
+
+func TestGRPCUncompressed(t *testing.T) {
+	r, endpoint := newGRPCReceiver(t)
+	cfg := newTestConfig(endpoint)
+	cfg.Compression = CompressionNone
+	op := newTestOutput(t, cfg)
+
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "one"}))
+	require.Eventually(t, func() bool { return len(r.bodies()) == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestGRPCRetry(t *testing.T) {
+	r, endpoint := newGRPCReceiver(t)
+	r.failures = []error{
+		status.Error(codes.Unavailable, "unavailable"),
+		status.Error(codes.ResourceExhausted, "slow down"),
+	}
+	op := newTestOutput(t, newTestConfig(endpoint))
+
+	delivered := make(chan bool, 1)
+	ctx := helper.WithAcknowledgement(context.Background(), func(ok bool) { delivered <- ok })
+	require.NoError(t, op.Process(ctx, &entry.Entry{Body: "retried"}))
+
+	select {
+	case ok := <-delivered:
+		require.True(t, ok)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Timed out waiting for acknowledgement")
+	}
+	require.Equal(t, []string{"retried"}, r.bodies())
+}
+
+func TestGRPCPermanentError(t *testing.T) {
+	r, endpoint := newGRPCReceiver(t)
+	r.failures = []error{status.Error(codes.InvalidArgument, "bad request")}
+	op := newTestOutput(t, newTestConfig(endpoint))
+
+	delivered := make(chan bool, 1)
+	ctx := helper.WithAcknowledgement(context.Background(), func(ok bool) { delivered <- ok })
+	require.NoError(t, op.Process(ctx, &entry.Entry{Body: "rejected"}))
+
+	select {
+	case ok := <-delivered:
+		require.False(t, ok)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Timed out waiting for acknowledgement")
+	}
+	require.Empty(t, r.bodies())
+}
+
+func TestBatching(t *testing.T) {
+	r, endpoint := newGRPCReceiver(t)
+	cfg := newTestConfig(endpoint)
+	cfg.MaxBatchSize = 5
+	cfg.FlushInterval = helper.NewDuration(time.Hour)
+	op := newTestOutput(t, cfg)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "entry"}))
+	}
+
+	require.Eventually(t, func() bool { return r.requestCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for _, logs := range r.logs {
+		require.Equal(t, 5, logs.LogRecordCount())
+	}
+}
+
+func TestStopFlushes(t *testing.T) {
+	r, endpoint := newGRPCReceiver(t)
+	cfg := newTestConfig(endpoint)
+	cfg.FlushInterval = helper.NewDuration(time.Hour)
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*OTLPOutput)
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "pending"}))
+	require.NoError(t, op.Stop())
+	require.Equal(t, []string{"pending"}, r.bodies())
+
+	require.Error(t, op.Process(context.Background(), &entry.Entry{Body: "late"}))
+}
+
+func TestHTTP(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(newHTTPHandler(t, r))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Protocol = ProtocolHTTP
+	cfg.Headers = map[string]string{"x-api-key": "secret"}
+	op := newTestOutput(t, cfg)
+
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "one"}))
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "two"}))
+
+	require.Eventually(t, func() bool { return len(r.bodies()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"one", "two"}, r.bodies())
+	require.Equal(t, "secret", r.headers[0])
+}
+
+func TestHTTPRetry(t *testing.T) {
+	r := &receiver{failures: []error{status.Error(codes.Unavailable, "unavailable")}}
+	server := httptest.NewServer(newHTTPHandler(t, r))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Protocol = ProtocolHTTP
+	cfg.Compression = CompressionNone
+	op := newTestOutput(t, cfg)
+
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "retried"}))
+	require.Eventually(t, func() bool { return len(r.bodies()) == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestHTTPTLS(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewTLSServer(newHTTPHandler(t, r))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Protocol = ProtocolHTTP
+	cfg.TLS = helper.NewTLSClientConfig(&configtls.TLSClientSetting{InsecureSkipVerify: true})
+	op := newTestOutput(t, cfg)
+
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "secure"}))
+	require.Eventually(t, func() bool { return len(r.bodies()) == 1 }, 5*time.Second, 10*time.Millisecond)
+}
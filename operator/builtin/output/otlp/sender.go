@@ -0,0 +1,223 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpcgzip "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const logsPath = "/v1/logs"
+
+// sender sends logs to an OTLP endpoint
+type sender interface {
+	send(ctx context.Context, logs pdata.Logs) error
+	close() error
+}
+
+// permanentError is an error that will not be resolved by retrying the request
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+type grpcSender struct {
+	conn     *grpc.ClientConn
+	client   otlpgrpc.LogsClient
+	metadata metadata.MD
+	callOpts []grpc.CallOption
+}
+
+func newGRPCSender(endpoint, compression string, headers map[string]string, tlsConfig *tls.Config) (*grpcSender, error) {
+	dialOpts := []grpc.DialOption{}
+	if tlsConfig != nil {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	}
+
+	callOpts := []grpc.CallOption{}
+	if compression == CompressionGzip {
+		callOpts = append(callOpts, grpc.UseCompressor(grpcgzip.Name))
+	}
+
+	conn, err := grpc.Dial(endpoint, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcSender{
+		conn:     conn,
+		client:   otlpgrpc.NewLogsClient(conn),
+		metadata: metadata.New(headers),
+		callOpts: callOpts,
+	}, nil
+}
+
+func (s *grpcSender) send(ctx context.Context, logs pdata.Logs) error {
+	request := otlpgrpc.NewLogsRequest()
+	request.SetLogs(logs)
+
+	ctx = metadata.NewOutgoingContext(ctx, s.metadata)
+	_, err := s.client.Export(ctx, request, s.callOpts...)
+	if err == nil {
+		return nil
+	}
+
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted,
+		codes.Aborted, codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+		return err
+	default:
+		return &permanentError{err: err}
+	}
+}
+
+func (s *grpcSender) close() error {
+	return s.conn.Close()
+}
+
+type httpSender struct {
+	client      *http.Client
+	url         string
+	compression string
+	headers     map[string]string
+}
+
+func newHTTPSender(endpoint, compression string, headers map[string]string, timeout time.Duration, tlsConfig *tls.Config) (*httpSender, error) {
+	logsURL, err := logsURL(endpoint, tlsConfig != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &httpSender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+		url:         logsURL,
+		compression: compression,
+		headers:     headers,
+	}, nil
+}
+
+// logsURL returns the url that logs are sent to. A scheme is added if the endpoint
+// does not have one, and the default logs path is used if the endpoint has no path.
+func logsURL(endpoint string, secure bool) (string, error) {
+	if !strings.Contains(endpoint, "://") {
+		if secure {
+			endpoint = "https://" + endpoint
+		} else {
+			endpoint = "http://" + endpoint
+		}
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint: %s", err)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("invalid endpoint '%s': missing host", endpoint)
+	}
+
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = logsPath
+	}
+	return parsed.String(), nil
+}
+
+func (s *httpSender) send(ctx context.Context, logs pdata.Logs) error {
+	request := otlpgrpc.NewLogsRequest()
+	request.SetLogs(logs)
+	body, err := request.Marshal()
+	if err != nil {
+		return &permanentError{err: fmt.Errorf("marshal request: %s", err)}
+	}
+
+	if s.compression == CompressionGzip {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(body); err != nil {
+			return &permanentError{err: fmt.Errorf("compress request: %s", err)}
+		}
+		if err := writer.Close(); err != nil {
+			return &permanentError{err: fmt.Errorf("compress request: %s", err)}
+		}
+		body = buf.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	if s.compression == CompressionGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("request failed with status %s", resp.Status)
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return err
+	default:
+		return &permanentError{err: err}
+	}
+}
+
+func (s *httpSender) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
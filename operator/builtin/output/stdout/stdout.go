@@ -20,6 +20,7 @@ import (
 	"io"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
@@ -68,13 +69,19 @@ type StdoutOperator struct {
 
 // Process will log entries received.
 func (o *StdoutOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	start := time.Now()
+	o.Telemetry.Received()
 	o.mux.Lock()
 	err := o.encoder.Encode(entry)
 	if err != nil {
 		o.mux.Unlock()
+		o.Telemetry.Failed()
 		o.Errorf("Failed to process entry: %s, $s", err, entry.Body)
+		helper.Nack(ctx)
 		return err
 	}
 	o.mux.Unlock()
+	o.Telemetry.ObserveSince(start)
+	helper.Ack(ctx)
 	return nil
 }
@@ -0,0 +1,278 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cef
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const cefPrefix = "CEF:"
+
+// headerFields are the names of the fields of a CEF header, in order
+var headerFields = [...]string{
+	"version",
+	"device_vendor",
+	"device_product",
+	"device_version",
+	"device_event_class_id",
+	"name",
+	"severity",
+}
+
+func init() {
+	operator.Register("cef_parser", func() operator.Builder { return NewCEFParserConfig("") })
+}
+
+// NewCEFParserConfig creates a new CEF parser config with default values
+func NewCEFParserConfig(operatorID string) *CEFParserConfig {
+	return &CEFParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "cef_parser"),
+	}
+}
+
+// CEFParserConfig is the configuration of a CEF parser operator.
+type CEFParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+}
+
+// Build will build a CEF parser operator.
+func (c CEFParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	cefParser := &CEFParser{
+		ParserOperator: parserOperator,
+	}
+
+	return []operator.Operator{cefParser}, nil
+}
+
+// CEFParser is an operator that parses ArcSight Common Event Format messages in an entry.
+type CEFParser struct {
+	helper.ParserOperator
+}
+
+// Process will parse an entry for a CEF message.
+func (c *CEFParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return c.ParserOperator.ProcessWithCallback(ctx, entry, c.parse, c.promoteSeverity)
+}
+
+// parse will parse a value as a CEF message, in the format
+// `CEF:Version|Device Vendor|Device Product|Device Version|Device Event Class ID|Name|Severity|Extension`
+func (c *CEFParser) parse(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("type %T cannot be parsed as CEF", value)
+	}
+
+	if !strings.HasPrefix(s, cefPrefix) {
+		return nil, fmt.Errorf("parse CEF: missing '%s' prefix", cefPrefix)
+	}
+
+	header, extension, err := splitHeader(s[len(cefPrefix):])
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := make(map[string]interface{}, len(headerFields)+1)
+	for i, field := range headerFields {
+		parsed[field] = header[i]
+	}
+	if _, _, ok := severityLevel(header[6]); !ok {
+		return nil, fmt.Errorf("parse CEF: invalid severity '%s'", header[6])
+	}
+
+	extensions, err := parseExtension(extension)
+	if err != nil {
+		return nil, err
+	}
+	parsed["extensions"] = extensions
+
+	return parsed, nil
+}
+
+// splitHeader splits a CEF message on the unescaped pipes that separate the header fields,
+// and unescapes the fields. The extension is returned as it is written.
+func splitHeader(s string) ([]string, string, error) {
+	header := make([]string, 0, len(headerFields))
+	var field strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && (s[i+1] == '|' || s[i+1] == '\\'):
+			i++
+			field.WriteByte(s[i])
+		case s[i] == '|':
+			header = append(header, field.String())
+			field.Reset()
+			if len(header) == len(headerFields) {
+				return header, s[i+1:], nil
+			}
+		default:
+			field.WriteByte(s[i])
+		}
+	}
+
+	// The extension may be left out along with the pipe that precedes it
+	header = append(header, field.String())
+	if len(header) < len(headerFields) {
+		return nil, "", fmt.Errorf("parse CEF: expected %d header fields, found %d", len(headerFields), len(header))
+	}
+	return header, "", nil
+}
+
+// parseExtension parses the space separated key=value pairs of a CEF extension. Values may
+// contain spaces, so a value ends where the next key starts.
+func parseExtension(s string) (map[string]interface{}, error) {
+	type keyPosition struct {
+		start  int
+		equals int
+	}
+
+	keys := make([]keyPosition, 0)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '=':
+			start := i
+			for start > 0 && isKeyChar(s[start-1]) {
+				start--
+			}
+			if start == i || (start > 0 && s[start-1] != ' ') {
+				continue
+			}
+			keys = append(keys, keyPosition{start: start, equals: i})
+		}
+	}
+
+	extensions := make(map[string]interface{}, len(keys))
+	if len(keys) == 0 || strings.TrimSpace(s[:keys[0].start]) != "" {
+		if strings.TrimSpace(s) != "" {
+			return nil, fmt.Errorf("parse CEF: invalid extension '%s'", s)
+		}
+		return extensions, nil
+	}
+
+	for i, key := range keys {
+		end := len(s)
+		if i+1 < len(keys) {
+			end = keys[i+1].start
+		}
+		value := strings.TrimRight(s[key.equals+1:end], " ")
+		extensions[s[key.start:key.equals]] = unescapeValue(value)
+	}
+	return extensions, nil
+}
+
+// isKeyChar returns true if a character can be part of an extension key
+func isKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '[' || c == ']'
+}
+
+// unescapeValue replaces the escape sequences of an extension value. Unknown sequences are kept as they are.
+func unescapeValue(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var value strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			value.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case '\\', '=', '|':
+			value.WriteByte(s[i])
+		case 'n':
+			value.WriteByte('\n')
+		case 'r':
+			value.WriteByte('\r')
+		default:
+			value.WriteByte('\\')
+			value.WriteByte(s[i])
+		}
+	}
+	return value.String()
+}
+
+// promoteSeverity sets the severity of an entry from the severity of its CEF message,
+// unless a severity block is configured
+func (c *CEFParser) promoteSeverity(e *entry.Entry) error {
+	if c.SeverityParser != nil {
+		return nil
+	}
+
+	value, ok := e.Get(c.ParseTo)
+	if !ok {
+		return nil
+	}
+	parsed, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	sev, ok := parsed["severity"].(string)
+	if !ok {
+		return nil
+	}
+
+	e.Severity, e.SeverityText, _ = severityLevel(sev)
+	return nil
+}
+
+// severityLevel returns the entry severity and level name of a CEF severity, which is
+// either an integer from 0 to 10, or the name of a level
+func severityLevel(sev string) (entry.Severity, string, bool) {
+	if n, err := strconv.Atoi(sev); err == nil {
+		switch {
+		case n < 0 || n > 10:
+			return entry.Default, "", false
+		case n <= 3:
+			sev = "Low"
+		case n <= 6:
+			sev = "Medium"
+		case n <= 8:
+			sev = "High"
+		default:
+			sev = "Very-High"
+		}
+	}
+
+	switch strings.ToLower(sev) {
+	case "unknown":
+		return entry.Default, "Unknown", true
+	case "low":
+		return entry.Info, "Low", true
+	case "medium":
+		return entry.Warn, "Medium", true
+	case "high":
+		return entry.Error, "High", true
+	case "very-high", "very high":
+		return entry.Fatal, "Very-High", true
+	default:
+		return entry.Default, "", false
+	}
+}
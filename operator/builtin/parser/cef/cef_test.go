@@ -0,0 +1,300 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cef
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T) *CEFParser {
+	cfg := NewCEFParserConfig("test")
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return ops[0].(*CEFParser)
+}
+
+func TestCEFParserParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"Extension",
+			`CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 spt=1232`,
+			map[string]interface{}{
+				"version":               "0",
+				"device_vendor":         "Security",
+				"device_product":        "threatmanager",
+				"device_version":        "1.0",
+				"device_event_class_id": "100",
+				"name":                  "worm successfully stopped",
+				"severity":              "10",
+				"extensions": map[string]interface{}{
+					"src": "10.0.0.1",
+					"dst": "2.1.2.2",
+					"spt": "1232",
+				},
+			},
+		},
+		{
+			"ValuesWithSpaces",
+			`CEF:0|Vendor|Product|1.0|200|Login|Low|suser=john doe msg=User logged in from a new device  act=allow`,
+			map[string]interface{}{
+				"version":               "0",
+				"device_vendor":         "Vendor",
+				"device_product":        "Product",
+				"device_version":        "1.0",
+				"device_event_class_id": "200",
+				"name":                  "Login",
+				"severity":              "Low",
+				"extensions": map[string]interface{}{
+					"suser": "john doe",
+					"msg":   "User logged in from a new device",
+					"act":   "allow",
+				},
+			},
+		},
+		{
+			"Escapes",
+			`CEF:1|Vendor\|Inc|Product\\X|1.0|300|Path \| query|5|request=https://example.com/?a\=1&b=2 msg=line one\nline two\\ cs1Label=pipe|value cs1=a\|b`,
+			map[string]interface{}{
+				"version":               "1",
+				"device_vendor":         "Vendor|Inc",
+				"device_product":        `Product\X`,
+				"device_version":        "1.0",
+				"device_event_class_id": "300",
+				"name":                  "Path | query",
+				"severity":              "5",
+				"extensions": map[string]interface{}{
+					"request":  "https://example.com/?a=1&b=2",
+					"msg":      "line one\nline two\\",
+					"cs1Label": "pipe|value",
+					"cs1":      "a|b",
+				},
+			},
+		},
+		{
+			"EmptyExtension",
+			`CEF:0|Vendor|Product|1.0|400|Heartbeat|0|`,
+			map[string]interface{}{
+				"version":               "0",
+				"device_vendor":         "Vendor",
+				"device_product":        "Product",
+				"device_version":        "1.0",
+				"device_event_class_id": "400",
+				"name":                  "Heartbeat",
+				"severity":              "0",
+				"extensions":            map[string]interface{}{},
+			},
+		},
+		{
+			"MissingExtension",
+			`CEF:0|Vendor|Product|1.0|400|Heartbeat|Unknown`,
+			map[string]interface{}{
+				"version":               "0",
+				"device_vendor":         "Vendor",
+				"device_product":        "Product",
+				"device_version":        "1.0",
+				"device_event_class_id": "400",
+				"name":                  "Heartbeat",
+				"severity":              "Unknown",
+				"extensions":            map[string]interface{}{},
+			},
+		},
+		{
+			"EmptyValues",
+			`CEF:0|Vendor|Product|1.0|500|Name|3|src= dst=10.0.0.2 cs2=`,
+			map[string]interface{}{
+				"version":               "0",
+				"device_vendor":         "Vendor",
+				"device_product":        "Product",
+				"device_version":        "1.0",
+				"device_event_class_id": "500",
+				"name":                  "Name",
+				"severity":              "3",
+				"extensions": map[string]interface{}{
+					"src": "",
+					"dst": "10.0.0.2",
+					"cs2": "",
+				},
+			},
+		},
+	}
+
+	parser := newTestParser(t)
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestCEFParserParseFailure(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     interface{}
+		expectErr string
+	}{
+		{"Bytes", []byte("CEF:0|a|b|c|d|e|1|"), "type []uint8 cannot be parsed as CEF"},
+		{"MissingPrefix", "LEEF:1.0|a|b|c|d|", "missing 'CEF:' prefix"},
+		{"MissingHeaderFields", "CEF:0|Vendor|Product|1.0|100|Name", "expected 7 header fields, found 6"},
+		{"InvalidSeverity", "CEF:0|Vendor|Product|1.0|100|Name|11|src=10.0.0.1", "invalid severity '11'"},
+		{"InvalidSeverityName", "CEF:0|Vendor|Product|1.0|100|Name|Critical|src=10.0.0.1", "invalid severity 'Critical'"},
+		{"InvalidExtension", "CEF:0|Vendor|Product|1.0|100|Name|1|no pairs here", "invalid extension"},
+		{"TextBeforeExtension", "CEF:0|Vendor|Product|1.0|100|Name|1|garbage src=10.0.0.1", "invalid extension"},
+	}
+
+	parser := newTestParser(t)
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
+
+func TestCEFParserSeverity(t *testing.T) {
+	cases := []struct {
+		severity     string
+		expected     entry.Severity
+		expectedText string
+	}{
+		{"0", entry.Info, "Low"},
+		{"3", entry.Info, "Low"},
+		{"4", entry.Warn, "Medium"},
+		{"6", entry.Warn, "Medium"},
+		{"7", entry.Error, "High"},
+		{"8", entry.Error, "High"},
+		{"9", entry.Fatal, "Very-High"},
+		{"10", entry.Fatal, "Very-High"},
+		{"Unknown", entry.Default, "Unknown"},
+		{"low", entry.Info, "Low"},
+		{"Medium", entry.Warn, "Medium"},
+		{"High", entry.Error, "High"},
+		{"Very-High", entry.Fatal, "Very-High"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.severity, func(t *testing.T) {
+			cfg := NewCEFParserConfig("test")
+			cfg.OutputIDs = []string{"fake"}
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			fake := testutil.NewFakeOutput(t)
+			require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+			e := entry.New()
+			e.Body = "CEF:0|Vendor|Product|1.0|100|Name|" + tc.severity + "|src=10.0.0.1"
+			require.NoError(t, op.Process(context.Background(), e))
+
+			select {
+			case e := <-fake.Received:
+				require.Equal(t, tc.expected, e.Severity)
+				require.Equal(t, tc.expectedText, e.SeverityText)
+			case <-time.After(time.Second):
+				require.FailNow(t, "Timed out waiting for entry")
+			}
+		})
+	}
+}
+
+func TestCEFParserProcess(t *testing.T) {
+	cfg := NewCEFParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.ParseFrom = entry.NewBodyField("message")
+	cfg.ParseTo = entry.NewBodyField("cef")
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"hostname": "fw01",
+		"message":  "CEF:0|Fortinet|FortiGate|7.0|13|traffic forward|7|act=deny src=10.0.0.5",
+	}
+	require.NoError(t, op.Process(context.Background(), e))
+
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, map[string]interface{}{
+			"hostname": "fw01",
+			"cef": map[string]interface{}{
+				"version":               "0",
+				"device_vendor":         "Fortinet",
+				"device_product":        "FortiGate",
+				"device_version":        "7.0",
+				"device_event_class_id": "13",
+				"name":                  "traffic forward",
+				"severity":              "7",
+				"extensions": map[string]interface{}{
+					"act": "deny",
+					"src": "10.0.0.5",
+				},
+			},
+		}, e.Body)
+		require.Equal(t, entry.Error, e.Severity)
+		require.Equal(t, "High", e.SeverityText)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
+
+func TestCEFParserSeverityBlock(t *testing.T) {
+	cfg := NewCEFParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	sevCfg := helper.NewSeverityParserConfig()
+	sevField := entry.NewBodyField("extensions", "outcome")
+	sevCfg.ParseFrom = &sevField
+	cfg.SeverityParserConfig = &sevCfg
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = "CEF:0|Vendor|Product|1.0|100|Name|10|outcome=warn"
+	require.NoError(t, op.Process(context.Background(), e))
+
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, entry.Warn, e.Severity)
+		require.Equal(t, "warn", e.SeverityText)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
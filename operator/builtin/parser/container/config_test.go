@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestContainerParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "format_docker",
+			Expect: func() *ContainerParserConfig {
+				cfg := defaultCfg()
+				cfg.Format = DockerFormat
+				return cfg
+			}(),
+		},
+		{
+			Name: "source_identifier",
+			Expect: func() *ContainerParserConfig {
+				cfg := defaultCfg()
+				cfg.SourceIdentifier = entry.NewAttributeField("log.file.path")
+				cfg.AddMetadataFromFilePath = false
+				return cfg
+			}(),
+		},
+		{
+			Name: "force_flush_period",
+			Expect: func() *ContainerParserConfig {
+				cfg := defaultCfg()
+				cfg.ForceFlushTimeout = helper.NewDuration(time.Second)
+				return cfg
+			}(),
+		},
+		{
+			Name: "max_log_size",
+			Expect: func() *ContainerParserConfig {
+				cfg := defaultCfg()
+				cfg.MaxLogSize = 64 * 1024
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *ContainerParserConfig {
+	return NewContainerParserConfig("container")
+}
@@ -0,0 +1,417 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// AutoFormat detects the format of each source
+	AutoFormat = "auto"
+	// DockerFormat is the format of the docker json-file logging driver
+	DockerFormat = "docker"
+	// CRIFormat is the format written by CRI-O and containerd
+	CRIFormat = "cri"
+)
+
+const (
+	streamAttribute       = "log.iostream"
+	namespaceResource     = "k8s.namespace.name"
+	podNameResource       = "k8s.pod.name"
+	podUIDResource        = "k8s.pod.uid"
+	containerNameResource = "k8s.container.name"
+	restartCountResource  = "k8s.container.restart_count"
+)
+
+const (
+	// defaultMaxLogSize is the default size after which a partial line is emitted
+	defaultMaxLogSize = 1024 * 1024
+	// maxCachedFormats limits the number of sources whose detected format is remembered
+	maxCachedFormats = 1024
+)
+
+// podLogPath matches the path kubelet writes container logs to: /var/log/pods/<namespace>_<pod>_<uid>/<container>/<restart count>.log
+var podLogPath = regexp.MustCompile(`/(?P<namespace>[^/_]+)_(?P<pod>[^/_]+)_(?P<uid>[a-f0-9-]+)/(?P<container>[^/]+)/(?P<restarts>\d+)\.log$`)
+
+func init() {
+	operator.Register("container", func() operator.Builder { return NewContainerParserConfig("") })
+}
+
+// NewContainerParserConfig creates a new container parser config with default values
+func NewContainerParserConfig(operatorID string) *ContainerParserConfig {
+	return &ContainerParserConfig{
+		TransformerConfig:       helper.NewTransformerConfig(operatorID, "container"),
+		Format:                  AutoFormat,
+		SourceIdentifier:        entry.NewAttributeField("file.path"),
+		AddMetadataFromFilePath: true,
+		ForceFlushTimeout:       helper.NewDuration(5 * time.Second),
+		MaxLogSize:              defaultMaxLogSize,
+	}
+}
+
+// ContainerParserConfig is the configuration of a container parser operator.
+type ContainerParserConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+
+	Format                  string          `mapstructure:"format"                      json:"format"                      yaml:"format"`
+	SourceIdentifier        entry.Field     `mapstructure:"source_identifier"           json:"source_identifier"           yaml:"source_identifier"`
+	AddMetadataFromFilePath bool            `mapstructure:"add_metadata_from_file_path" json:"add_metadata_from_file_path" yaml:"add_metadata_from_file_path"`
+	ForceFlushTimeout       helper.Duration `mapstructure:"force_flush_period"          json:"force_flush_period"          yaml:"force_flush_period"`
+	MaxLogSize              helper.ByteSize `mapstructure:"max_log_size"                json:"max_log_size"                yaml:"max_log_size"`
+}
+
+// Build will build a container parser operator.
+func (c ContainerParserConfig) Build(bc operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(bc)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Format {
+	case AutoFormat, DockerFormat, CRIFormat:
+	default:
+		return nil, fmt.Errorf("invalid `format` '%s'", c.Format)
+	}
+
+	if c.ForceFlushTimeout.Raw() <= 0 {
+		return nil, fmt.Errorf("`force_flush_period` must be positive")
+	}
+
+	if c.MaxLogSize <= 0 {
+		return nil, fmt.Errorf("`max_log_size` must be positive")
+	}
+
+	containerParser := &ContainerParser{
+		TransformerOperator:     transformer,
+		format:                  c.Format,
+		sourceIdentifier:        c.SourceIdentifier,
+		addMetadataFromFilePath: c.AddMetadataFromFilePath,
+		forceFlushTimeout:       c.ForceFlushTimeout.Raw(),
+		maxLogSize:              int(c.MaxLogSize),
+		json:                    jsoniter.ConfigFastest,
+		partials:                make(map[partialKey]*partialLog),
+		formats:                 make(map[string]string),
+	}
+
+	return []operator.Operator{containerParser}, nil
+}
+
+// ContainerParser is an operator that parses the log files written by container runtimes,
+// reassembling lines that were split by the runtime.
+type ContainerParser struct {
+	helper.TransformerOperator
+	format                  string
+	sourceIdentifier        entry.Field
+	addMetadataFromFilePath bool
+	forceFlushTimeout       time.Duration
+	maxLogSize              int
+	chClose                 chan struct{}
+	json                    jsoniter.API
+
+	sync.Mutex
+	partials map[partialKey]*partialLog
+	formats  map[string]string
+}
+
+// partialKey separates the partial lines of each stream of each source
+type partialKey struct {
+	source string
+	stream string
+}
+
+// partialLog holds the parts of a line that have been read so far
+type partialLog struct {
+	base        *entry.Entry
+	time        time.Time
+	ctxs        []context.Context
+	message     strings.Builder
+	lastUpdated time.Time
+}
+
+// containerLog is a single line written by a container runtime
+type containerLog struct {
+	time    time.Time
+	stream  string
+	message string
+	partial bool
+}
+
+// dockerLog is a single line written by the docker json-file logging driver
+type dockerLog struct {
+	Log    string `json:"log"`
+	Stream string `json:"stream"`
+	Time   string `json:"time"`
+}
+
+// Start will start flushing partial lines that have not been completed in time.
+func (p *ContainerParser) Start(_ operator.Persister) error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.chClose = make(chan struct{})
+	go p.flushLoop(time.NewTicker(p.forceFlushTimeout), p.chClose)
+	return nil
+}
+
+// Stop will flush any partial lines and stop the operator.
+func (p *ContainerParser) Stop() error {
+	p.Lock()
+	flushed := make(map[partialKey]*partialLog, len(p.partials))
+	for key := range p.partials {
+		flushed[key] = p.take(key)
+	}
+	if p.chClose != nil {
+		close(p.chClose)
+		p.chClose = nil
+	}
+	p.Unlock()
+
+	for key, partial := range flushed {
+		p.flush(key, partial)
+	}
+	return nil
+}
+
+func (p *ContainerParser) flushLoop(ticker *time.Ticker, chClose chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			p.Lock()
+			now := time.Now()
+			flushed := make(map[partialKey]*partialLog)
+			for key, partial := range p.partials {
+				if now.Sub(partial.lastUpdated) < p.forceFlushTimeout {
+					continue
+				}
+				flushed[key] = p.take(key)
+			}
+			p.Unlock()
+
+			for key, partial := range flushed {
+				p.flush(key, partial)
+			}
+		case <-chClose:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// Process will parse an entry as a container log line.
+func (p *ContainerParser) Process(ctx context.Context, e *entry.Entry) error {
+	start := time.Now()
+	p.Telemetry.Received()
+
+	skip, err := p.Skip(ctx, e)
+	if err != nil {
+		return p.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		p.Write(ctx, e)
+		return nil
+	}
+
+	line, ok := e.Body.(string)
+	if !ok {
+		return p.HandleEntryError(ctx, e, fmt.Errorf("type %T cannot be parsed as a container log", e.Body))
+	}
+
+	var source string
+	_ = e.Read(p.sourceIdentifier, &source)
+
+	// Entries are written after unlocking, so that a slow output does not block the other sources
+	p.Lock()
+	log, err := p.parse(source, line)
+	if err != nil {
+		p.Unlock()
+		return p.HandleEntryError(ctx, e, err)
+	}
+	key := partialKey{source: source, stream: log.stream}
+
+	partial, ok := p.partials[key]
+	if !ok && !log.partial {
+		p.Unlock()
+		p.Telemetry.ObserveSince(start)
+		p.emit(ctx, e, log.time, log.stream, log.message)
+		return nil
+	}
+	if !ok {
+		partial = &partialLog{base: e, time: log.time}
+		p.partials[key] = partial
+	}
+
+	partial.ctxs = append(partial.ctxs, ctx)
+	partial.message.WriteString(log.message)
+	partial.lastUpdated = time.Now()
+	var flushed *partialLog
+	if !log.partial || partial.message.Len() >= p.maxLogSize {
+		flushed = p.take(key)
+	}
+	p.Unlock()
+
+	if flushed != nil {
+		p.Telemetry.ObserveSince(start)
+		p.flush(key, flushed)
+	}
+	return nil
+}
+
+// parse parses a line in the configured format. In auto mode, the format is detected from
+// the first line of a source, and detected again only if a line of the source does not parse.
+func (p *ContainerParser) parse(source, line string) (containerLog, error) {
+	if p.format != AutoFormat {
+		return p.parseFormat(p.format, line)
+	}
+
+	if format, ok := p.formats[source]; ok {
+		if log, err := p.parseFormat(format, line); err == nil {
+			return log, nil
+		}
+	}
+
+	format := detectFormat(line)
+	log, err := p.parseFormat(format, line)
+	if err != nil {
+		return log, err
+	}
+	if _, ok := p.formats[source]; !ok && len(p.formats) >= maxCachedFormats {
+		p.formats = make(map[string]string)
+	}
+	p.formats[source] = format
+	return log, nil
+}
+
+// detectFormat detects the format of a line
+func detectFormat(line string) string {
+	if strings.HasPrefix(line, "{") {
+		return DockerFormat
+	}
+	return CRIFormat
+}
+
+// parseFormat parses a line in the given format
+func (p *ContainerParser) parseFormat(format, line string) (containerLog, error) {
+	if format == DockerFormat {
+		return p.parseDocker(line)
+	}
+	return parseCRI(line)
+}
+
+// parseDocker parses a line written by the docker json-file logging driver. Lines that
+// do not end with a newline were split by docker, and are continued by the next line.
+func (p *ContainerParser) parseDocker(line string) (containerLog, error) {
+	var parsed dockerLog
+	if err := p.json.UnmarshalFromString(line, &parsed); err != nil {
+		return containerLog{}, fmt.Errorf("parse docker log: %s", err)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, parsed.Time)
+	if err != nil {
+		return containerLog{}, fmt.Errorf("parse docker log time: %s", err)
+	}
+
+	message := strings.TrimSuffix(parsed.Log, "\n")
+	return containerLog{
+		time:    t,
+		stream:  parsed.Stream,
+		message: message,
+		partial: len(message) == len(parsed.Log),
+	}, nil
+}
+
+// parseCRI parses a line in the format `<time> <stream> <tag> <message>`, where the first
+// tag is P for a partial line, which is continued by the next line, or F for a full line.
+func parseCRI(line string) (containerLog, error) {
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) < 3 {
+		return containerLog{}, fmt.Errorf("parse cri log: expected `<time> <stream> <tag> <message>`")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return containerLog{}, fmt.Errorf("parse cri log time: %s", err)
+	}
+
+	log := containerLog{time: t, stream: parts[1]}
+	switch strings.SplitN(parts[2], ":", 2)[0] {
+	case "P":
+		log.partial = true
+	case "F":
+	default:
+		return containerLog{}, fmt.Errorf("parse cri log: invalid tag '%s'", parts[2])
+	}
+
+	if len(parts) == 4 {
+		log.message = parts[3]
+	}
+	return log, nil
+}
+
+// take removes the parts of a line read so far, to be flushed once the lock is released
+func (p *ContainerParser) take(key partialKey) *partialLog {
+	partial := p.partials[key]
+	delete(p.partials, key)
+	return partial
+}
+
+// flush emits the parts of a line read so far as a single entry
+func (p *ContainerParser) flush(key partialKey, partial *partialLog) {
+	ctx := helper.MergeAcknowledgements(context.Background(), partial.ctxs)
+	p.emit(ctx, partial.base, partial.time, key.stream, partial.message.String())
+}
+
+// emit replaces the body of an entry with the message of a line, then writes it
+func (p *ContainerParser) emit(ctx context.Context, e *entry.Entry, t time.Time, stream, message string) {
+	e.Body = message
+	e.Timestamp = t
+	e.AddAttribute(streamAttribute, stream)
+
+	if p.addMetadataFromFilePath {
+		var path string
+		if err := e.Read(p.sourceIdentifier, &path); err == nil {
+			addMetadataFromFilePath(e, path)
+		}
+	}
+
+	p.Write(ctx, e)
+}
+
+// addMetadataFromFilePath adds the resource of the pod and container that wrote a log file
+func addMetadataFromFilePath(e *entry.Entry, path string) {
+	matches := podLogPath.FindStringSubmatch(path)
+	if matches == nil {
+		return
+	}
+	e.AddResourceKey(namespaceResource, matches[podLogPath.SubexpIndex("namespace")])
+	e.AddResourceKey(podNameResource, matches[podLogPath.SubexpIndex("pod")])
+	e.AddResourceKey(podUIDResource, matches[podLogPath.SubexpIndex("uid")])
+	e.AddResourceKey(containerNameResource, matches[podLogPath.SubexpIndex("container")])
+	if restarts, err := strconv.Atoi(matches[podLogPath.SubexpIndex("restarts")]); err == nil {
+		e.AddResourceKey(restartCountResource, restarts)
+	}
+}
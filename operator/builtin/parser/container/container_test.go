@@ -0,0 +1,396 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+const podLogFile = "/var/log/pods/kube-system_coredns-558bd4d5db-abcde_9a1b2c3d-0000-4e5f-8a9b-0123456789ab/coredns/2.log"
+
+func newTestParser(t *testing.T, mod func(*ContainerParserConfig)) (*ContainerParser, *testutil.FakeOutput) {
+	cfg := NewContainerParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	if mod != nil {
+		mod(cfg)
+	}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*ContainerParser)
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{fake}))
+	return parser, fake
+}
+
+func newLine(path, line string) *entry.Entry {
+	e := entry.New()
+	e.Body = line
+	e.AddAttribute("file.path", path)
+	return e
+}
+
+func TestContainerParserBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		mod       func(*ContainerParserConfig)
+		expectErr bool
+	}{
+		{"Default", nil, false},
+		{"Docker", func(cfg *ContainerParserConfig) { cfg.Format = DockerFormat }, false},
+		{"CRI", func(cfg *ContainerParserConfig) { cfg.Format = CRIFormat }, false},
+		{"InvalidFormat", func(cfg *ContainerParserConfig) { cfg.Format = "podman" }, true},
+		{"ZeroForceFlushPeriod", func(cfg *ContainerParserConfig) { cfg.ForceFlushTimeout = helper.NewDuration(0) }, true},
+		{"ZeroMaxLogSize", func(cfg *ContainerParserConfig) { cfg.MaxLogSize = 0 }, true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewContainerParserConfig("test")
+			if tc.mod != nil {
+				tc.mod(cfg)
+			}
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestContainerParserFormats(t *testing.T) {
+	ts := time.Date(2021, time.June, 22, 10, 27, 25, 813799277, time.UTC)
+
+	cases := []struct {
+		name    string
+		format  string
+		line    string
+		stream  string
+		message string
+		time    time.Time
+	}{
+		{
+			name:    "Docker",
+			format:  AutoFormat,
+			line:    `{"log":"docker message\n","stream":"stderr","time":"2021-06-22T10:27:25.813799277Z"}`,
+			stream:  "stderr",
+			message: "docker message",
+			time:    ts,
+		},
+		{
+			name:    "Containerd",
+			format:  AutoFormat,
+			line:    "2021-06-22T10:27:25.813799277Z stdout F containerd message",
+			stream:  "stdout",
+			message: "containerd message",
+			time:    ts,
+		},
+		{
+			name:    "CRIO",
+			format:  AutoFormat,
+			line:    "2021-06-22T12:27:25.813799277+02:00 stdout F cri-o message",
+			stream:  "stdout",
+			message: "cri-o message",
+			time:    ts,
+		},
+		{
+			name:    "CRIEmptyMessage",
+			format:  CRIFormat,
+			line:    "2021-06-22T10:27:25.813799277Z stdout F",
+			stream:  "stdout",
+			message: "",
+			time:    ts,
+		},
+		{
+			name:    "CRIMessageWithSpaces",
+			format:  CRIFormat,
+			line:    "2021-06-22T10:27:25.813799277Z stdout F  indented  message ",
+			stream:  "stdout",
+			message: " indented  message ",
+			time:    ts,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			parser, fake := newTestParser(t, func(cfg *ContainerParserConfig) {
+				cfg.Format = tc.format
+			})
+
+			require.NoError(t, parser.Process(context.Background(), newLine("/var/log/app.log", tc.line)))
+			e := <-fake.Received
+			require.Equal(t, tc.message, e.Body)
+			require.Equal(t, tc.stream, e.Attributes["log.iostream"])
+			require.True(t, tc.time.Equal(e.Timestamp))
+			require.Empty(t, e.Resource)
+		})
+	}
+}
+
+func TestContainerParserErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		format string
+		body   interface{}
+	}{
+		{"NotString", AutoFormat, map[string]interface{}{"log": "message"}},
+		{"InvalidJSON", AutoFormat, `{"log":`},
+		{"InvalidDockerTime", AutoFormat, `{"log":"message\n","stream":"stdout","time":"yesterday"}`},
+		{"MissingCRITag", AutoFormat, "2021-06-22T10:27:25.813799277Z stdout"},
+		{"InvalidCRITag", AutoFormat, "2021-06-22T10:27:25.813799277Z stdout X message"},
+		{"InvalidCRITime", AutoFormat, "yesterday stdout F message"},
+		{"DockerFormatWithCRILine", DockerFormat, "2021-06-22T10:27:25.813799277Z stdout F message"},
+		{"CRIFormatWithDockerLine", CRIFormat, `{"log":"message\n","stream":"stdout","time":"2021-06-22T10:27:25.813799277Z"}`},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			parser, fake := newTestParser(t, func(cfg *ContainerParserConfig) {
+				cfg.Format = tc.format
+			})
+
+			e := entry.New()
+			e.Body = tc.body
+			require.Error(t, parser.Process(context.Background(), e))
+			fake.ExpectBody(t, tc.body)
+		})
+	}
+}
+
+func TestContainerParserPartialCRI(t *testing.T) {
+	parser, fake := newTestParser(t, nil)
+	ctx := context.Background()
+
+	require.NoError(t, parser.Process(ctx, newLine(podLogFile, "2021-06-22T10:27:25.000000000Z stdout P This is a very very long line th")))
+	require.NoError(t, parser.Process(ctx, newLine(podLogFile, "2021-06-22T10:27:26.000000000Z stderr F an error")))
+	require.NoError(t, parser.Process(ctx, newLine(podLogFile, "2021-06-22T10:27:27.000000000Z stdout P at is really really long and spa")))
+	require.NoError(t, parser.Process(ctx, newLine(podLogFile, "2021-06-22T10:27:28.000000000Z stdout F ns across multiple log entries")))
+
+	// Lines of other streams are not combined with the partial line
+	e := <-fake.Received
+	require.Equal(t, "an error", e.Body)
+	require.Equal(t, "stderr", e.Attributes["log.iostream"])
+
+	e = <-fake.Received
+	require.Equal(t, "This is a very very long line that is really really long and spans across multiple log entries", e.Body)
+	require.Equal(t, "stdout", e.Attributes["log.iostream"])
+	require.Equal(t, time.Date(2021, time.June, 22, 10, 27, 25, 0, time.UTC), e.Timestamp)
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+}
+
+func TestContainerParserPartialDocker(t *testing.T) {
+	parser, fake := newTestParser(t, nil)
+	ctx := context.Background()
+
+	require.NoError(t, parser.Process(ctx, newLine(podLogFile, `{"log":"first ","stream":"stdout","time":"2021-06-22T10:27:25Z"}`)))
+	require.NoError(t, parser.Process(ctx, newLine("/var/log/other.log", `{"log":"other\n","stream":"stdout","time":"2021-06-22T10:27:26Z"}`)))
+	require.NoError(t, parser.Process(ctx, newLine(podLogFile, `{"log":"second\n","stream":"stdout","time":"2021-06-22T10:27:27Z"}`)))
+
+	// Lines of other files are not combined with the partial line
+	fake.ExpectBody(t, "other")
+	fake.ExpectBody(t, "first second")
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+}
+
+// ackingOutput acknowledges each entry it receives
+type ackingOutput struct {
+	*testutil.FakeOutput
+}
+
+func (o *ackingOutput) Process(ctx context.Context, e *entry.Entry) error {
+	helper.Ack(ctx)
+	return o.FakeOutput.Process(ctx, e)
+}
+
+func TestContainerParserPartialAcknowledged(t *testing.T) {
+	parser, fake := newTestParser(t, nil)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{&ackingOutput{fake}}))
+
+	acked := 0
+	ack := func(delivered bool) {
+		require.True(t, delivered)
+		acked++
+	}
+	require.NoError(t, parser.Process(helper.WithAcknowledgement(context.Background(), ack), newLine(podLogFile, "2021-06-22T10:27:25Z stdout P first ")))
+	require.NoError(t, parser.Process(helper.WithAcknowledgement(context.Background(), ack), newLine(podLogFile, "2021-06-22T10:27:25Z stdout F second")))
+
+	fake.ExpectBody(t, "first second")
+	require.Equal(t, 2, acked)
+}
+
+// lockingOutput takes the lock of the parser for each entry it receives
+type lockingOutput struct {
+	*testutil.FakeOutput
+	parser *ContainerParser
+}
+
+func (o *lockingOutput) Process(ctx context.Context, e *entry.Entry) error {
+	o.parser.Lock()
+	defer o.parser.Unlock()
+	return o.FakeOutput.Process(ctx, e)
+}
+
+func TestContainerParserWritesWithoutLock(t *testing.T) {
+	parser, fake := newTestParser(t, nil)
+	require.NoError(t, parser.SetOutputs([]operator.Operator{&lockingOutput{fake, parser}}))
+	require.NoError(t, parser.Start(nil))
+
+	// Each entry is written once the parser has released its lock, whether it is a full line,
+	// a completed partial line, or a partial line flushed when stopping
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		require.NoError(t, parser.Process(context.Background(), newLine(podLogFile, "2021-06-22T10:27:25Z stdout F full")))
+		require.NoError(t, parser.Process(context.Background(), newLine(podLogFile, "2021-06-22T10:27:25Z stdout P first ")))
+		require.NoError(t, parser.Process(context.Background(), newLine(podLogFile, "2021-06-22T10:27:25Z stdout F second")))
+		require.NoError(t, parser.Process(context.Background(), newLine(podLogFile, "2021-06-22T10:27:25Z stdout P incomplete")))
+		require.NoError(t, parser.Stop())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for the parser, which wrote while holding its lock")
+	}
+	fake.ExpectBody(t, "full")
+	fake.ExpectBody(t, "first second")
+	fake.ExpectBody(t, "incomplete")
+}
+
+func TestContainerParserForceFlush(t *testing.T) {
+	t.Parallel()
+	parser, fake := newTestParser(t, func(cfg *ContainerParserConfig) {
+		cfg.ForceFlushTimeout = helper.NewDuration(50 * time.Millisecond)
+	})
+	require.NoError(t, parser.Start(nil))
+	defer parser.Stop()
+
+	require.NoError(t, parser.Process(context.Background(), newLine(podLogFile, "2021-06-22T10:27:25Z stdout P incomplete")))
+	fake.ExpectNoEntry(t, 25*time.Millisecond)
+	fake.ExpectBody(t, "incomplete")
+}
+
+func TestContainerParserFlushOnStop(t *testing.T) {
+	parser, fake := newTestParser(t, nil)
+	require.NoError(t, parser.Start(nil))
+
+	require.NoError(t, parser.Process(context.Background(), newLine(podLogFile, "2021-06-22T10:27:25Z stdout P incomplete")))
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+	require.NoError(t, parser.Stop())
+	fake.ExpectBody(t, "incomplete")
+}
+
+func TestContainerParserMaxLogSize(t *testing.T) {
+	parser, fake := newTestParser(t, func(cfg *ContainerParserConfig) {
+		cfg.MaxLogSize = 10
+	})
+	ctx := context.Background()
+
+	require.NoError(t, parser.Process(ctx, newLine(podLogFile, "2021-06-22T10:27:25Z stdout P 12345")))
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+	require.NoError(t, parser.Process(ctx, newLine(podLogFile, "2021-06-22T10:27:25Z stdout P 67890")))
+	fake.ExpectBody(t, "1234567890")
+
+	require.NoError(t, parser.Process(ctx, newLine(podLogFile, "2021-06-22T10:27:25Z stdout F end")))
+	fake.ExpectBody(t, "end")
+}
+
+func TestContainerParserRestart(t *testing.T) {
+	parser, fake := newTestParser(t, func(cfg *ContainerParserConfig) {
+		cfg.ForceFlushTimeout = helper.NewDuration(50 * time.Millisecond)
+	})
+	require.NoError(t, parser.Start(nil))
+	require.NoError(t, parser.Stop())
+	require.NoError(t, parser.Start(nil))
+	defer parser.Stop()
+
+	require.NoError(t, parser.Process(context.Background(), newLine(podLogFile, "2021-06-22T10:27:25Z stdout P incomplete")))
+	fake.ExpectBody(t, "incomplete")
+}
+
+func TestContainerParserDetectsFormatPerSource(t *testing.T) {
+	parser, fake := newTestParser(t, nil)
+	ctx := context.Background()
+
+	require.NoError(t, parser.Process(ctx, newLine("/var/log/docker.log", `{"log":"docker\n","stream":"stdout","time":"2021-06-22T10:27:25Z"}`)))
+	fake.ExpectBody(t, "docker")
+	require.NoError(t, parser.Process(ctx, newLine("/var/log/cri.log", "2021-06-22T10:27:25Z stdout F cri")))
+	fake.ExpectBody(t, "cri")
+	require.Equal(t, map[string]string{"/var/log/docker.log": DockerFormat, "/var/log/cri.log": CRIFormat}, parser.formats)
+
+	// A source is detected again if its lines stop matching its format
+	require.NoError(t, parser.Process(ctx, newLine("/var/log/docker.log", "2021-06-22T10:27:26Z stdout F now cri")))
+	fake.ExpectBody(t, "now cri")
+	require.Equal(t, CRIFormat, parser.formats["/var/log/docker.log"])
+}
+
+func TestContainerParserMetadataFromFilePath(t *testing.T) {
+	cases := []struct {
+		name     string
+		path     string
+		enabled  bool
+		expected map[string]interface{}
+	}{
+		{
+			name:    "PodLogFile",
+			path:    podLogFile,
+			enabled: true,
+			expected: map[string]interface{}{
+				"k8s.namespace.name":          "kube-system",
+				"k8s.pod.name":                "coredns-558bd4d5db-abcde",
+				"k8s.pod.uid":                 "9a1b2c3d-0000-4e5f-8a9b-0123456789ab",
+				"k8s.container.name":          "coredns",
+				"k8s.container.restart_count": 2,
+			},
+		},
+		{
+			name:    "Disabled",
+			path:    podLogFile,
+			enabled: false,
+		},
+		{
+			name:    "OtherFile",
+			path:    "/var/log/containers/coredns.log",
+			enabled: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			parser, fake := newTestParser(t, func(cfg *ContainerParserConfig) {
+				cfg.AddMetadataFromFilePath = tc.enabled
+			})
+
+			require.NoError(t, parser.Process(context.Background(), newLine(tc.path, "2021-06-22T10:27:25Z stdout F message")))
+			e := <-fake.Received
+			require.Equal(t, tc.expected, e.Resource)
+		})
+	}
+}
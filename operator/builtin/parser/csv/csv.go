@@ -107,7 +107,7 @@ func (r *CSVParser) Process(ctx context.Context, e *entry.Entry) error {
 
 	// If we have a headerAttribute set we need to dynamically generate our parser function
 	if r.headerAttribute != "" {
-		h, ok := e.Attributes[r.headerAttribute]
+		h, ok := e.Attributes[r.headerAttribute].(string)
 		if !ok {
 			err := fmt.Errorf("failed to read dynamic header attribute %s", r.headerAttribute)
 			r.Error(err)
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grok
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestGrokParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "patterns",
+			Expect: func() *GrokParserConfig {
+				cfg := defaultCfg()
+				cfg.Patterns = []string{"%{COMBINEDAPACHELOG}", "%{COMMONAPACHELOG}"}
+				cfg.ParseTo = entry.NewAttributeField()
+				return cfg
+			}(),
+		},
+		{
+			Name: "pattern_definitions",
+			Expect: func() *GrokParserConfig {
+				cfg := defaultCfg()
+				cfg.Patterns = []string{"%{APP_LOG}"}
+				cfg.PatternDefinitions = map[string]string{
+					"APP_ID":  "app-[0-9]+",
+					"APP_LOG": "%{APP_ID:app} %{INT:status:int} %{GREEDYDATA:message}",
+				}
+				return cfg
+			}(),
+		},
+		{
+			Name: "patterns_dir",
+			Expect: func() *GrokParserConfig {
+				cfg := defaultCfg()
+				cfg.Patterns = []string{"%{APP_LOG}"}
+				cfg.PatternsDir = "/etc/grok/patterns"
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *GrokParserConfig {
+	return NewGrokParserConfig("grok_parser")
+}
@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grok
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("grok_parser", func() operator.Builder { return NewGrokParserConfig("") })
+}
+
+// NewGrokParserConfig creates a new grok parser config with default values
+func NewGrokParserConfig(operatorID string) *GrokParserConfig {
+	return &GrokParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "grok_parser"),
+	}
+}
+
+// GrokParserConfig is the configuration of a grok parser operator.
+type GrokParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	Patterns           []string          `mapstructure:"patterns"            json:"patterns"                      yaml:"patterns"`
+	PatternDefinitions map[string]string `mapstructure:"pattern_definitions" json:"pattern_definitions,omitempty" yaml:"pattern_definitions,omitempty"`
+	PatternsDir        string            `mapstructure:"patterns_dir"        json:"patterns_dir,omitempty"        yaml:"patterns_dir,omitempty"`
+}
+
+// Build will build a grok parser operator.
+func (c GrokParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Patterns) == 0 {
+		return nil, fmt.Errorf("missing required field 'patterns'")
+	}
+
+	// Definitions in the patterns directory override the bundled ones, and are overridden by the configured ones
+	definitions := make(map[string]string, len(defaultDefinitions)+len(c.PatternDefinitions))
+	for name, definition := range defaultDefinitions {
+		definitions[name] = definition
+	}
+	if c.PatternsDir != "" {
+		if err := loadPatternsDir(c.PatternsDir, definitions); err != nil {
+			return nil, fmt.Errorf("load patterns_dir: %s", err)
+		}
+	}
+	for name, definition := range c.PatternDefinitions {
+		if !patternName.MatchString(name) {
+			return nil, fmt.Errorf("invalid pattern name '%s' in 'pattern_definitions'", name)
+		}
+		definitions[name] = definition
+	}
+
+	patterns := make([]*grokPattern, 0, len(c.Patterns))
+	for _, pattern := range c.Patterns {
+		compiled, err := compilePattern(pattern, definitions)
+		if err != nil {
+			return nil, fmt.Errorf("compile pattern '%s': %s", pattern, err)
+		}
+		patterns = append(patterns, compiled)
+	}
+
+	grokParser := &GrokParser{
+		ParserOperator: parserOperator,
+		patterns:       patterns,
+	}
+
+	return []operator.Operator{grokParser}, nil
+}
+
+// GrokParser is an operator that parses an entry with the first of its grok patterns that matches.
+type GrokParser struct {
+	helper.ParserOperator
+	patterns []*grokPattern
+}
+
+// Process will parse an entry with grok patterns.
+func (g *GrokParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return g.ParserOperator.ProcessWith(ctx, entry, g.parse)
+}
+
+// parse will parse a value with the first pattern that matches.
+func (g *GrokParser) parse(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("type '%T' cannot be parsed as grok", value)
+	}
+
+	for _, pattern := range g.patterns {
+		matches := pattern.regexp.FindStringSubmatchIndex(s)
+		if matches == nil {
+			continue
+		}
+		return pattern.parse(s, matches)
+	}
+	return nil, fmt.Errorf("grok patterns do not match")
+}
+
+// parse returns the fields of the capture groups that matched. If several groups
+// are parsed to the same field, the first group that matched is used.
+func (p *grokPattern) parse(s string, matches []int) (map[string]interface{}, error) {
+	parsedValues := map[string]interface{}{}
+	for i, field := range p.fields {
+		if field.name == "" || matches[2*i] < 0 {
+			continue
+		}
+		if _, ok := parsedValues[field.name]; ok {
+			continue
+		}
+
+		value := s[matches[2*i]:matches[2*i+1]]
+		switch field.dataType {
+		case intType:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("convert field '%s' to int: %s", field.name, err)
+			}
+			parsedValues[field.name] = n
+		case floatType:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("convert field '%s' to float: %s", field.name, err)
+			}
+			parsedValues[field.name] = f
+		default:
+			parsedValues[field.name] = value
+		}
+	}
+	return parsedValues, nil
+}
@@ -0,0 +1,365 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grok
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, patterns ...string) *GrokParser {
+	cfg := NewGrokParserConfig("test")
+	cfg.Patterns = patterns
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return ops[0].(*GrokParser)
+}
+
+func TestBundledPatternsCompile(t *testing.T) {
+	for name := range defaultDefinitions {
+		_, err := compilePattern("%{"+name+"}", defaultDefinitions)
+		require.NoError(t, err, name)
+	}
+}
+
+func TestGrokParserBuild(t *testing.T) {
+	patternsDir := testutil.NewTempDir(t)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(patternsDir, "invalid"), []byte("not-a-name pattern\n"), 0600))
+
+	cases := []struct {
+		name      string
+		modify    func(cfg *GrokParserConfig)
+		expectErr string
+	}{
+		{"Valid", func(cfg *GrokParserConfig) {}, ""},
+		{"MissingPatterns", func(cfg *GrokParserConfig) { cfg.Patterns = nil }, "missing required field 'patterns'"},
+		{"UndefinedPattern", func(cfg *GrokParserConfig) { cfg.Patterns = []string{"%{MISSING:a}"} }, "pattern 'MISSING' is not defined"},
+		{"InvalidType", func(cfg *GrokParserConfig) { cfg.Patterns = []string{"%{INT:a:bool}"} }, "invalid type 'bool' for field 'a'"},
+		{"InvalidRegex", func(cfg *GrokParserConfig) { cfg.Patterns = []string{"%{INT:a}("} }, "compiling regex"},
+		{
+			"RecursivePattern",
+			func(cfg *GrokParserConfig) {
+				cfg.Patterns = []string{"%{A}"}
+				cfg.PatternDefinitions = map[string]string{"A": "a%{B}", "B": "b%{A}"}
+			},
+			"pattern 'A' references itself",
+		},
+		{
+			"InvalidDefinitionName",
+			func(cfg *GrokParserConfig) { cfg.PatternDefinitions = map[string]string{"A-B": "a"} },
+			"invalid pattern name 'A-B'",
+		},
+		{"MissingPatternsDir", func(cfg *GrokParserConfig) { cfg.PatternsDir = filepath.Join(patternsDir, "missing") }, "load patterns_dir"},
+		{"InvalidPatternsFile", func(cfg *GrokParserConfig) { cfg.PatternsDir = patternsDir }, "line 1"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewGrokParserConfig("test")
+			cfg.Patterns = []string{"%{COMMONAPACHELOG}"}
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
+
+func TestGrokParserBundledPatterns(t *testing.T) {
+	cases := []struct {
+		name     string
+		pattern  string
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"CombinedApacheLog",
+			"%{COMBINEDAPACHELOG}",
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"`,
+			map[string]interface{}{
+				"clientip":    "127.0.0.1",
+				"ident":       "-",
+				"auth":        "frank",
+				"timestamp":   "10/Oct/2000:13:55:36 -0700",
+				"verb":        "GET",
+				"request":     "/apache_pb.gif",
+				"httpversion": "1.0",
+				"response":    "200",
+				"bytes":       "2326",
+				"referrer":    `"http://www.example.com/start.html"`,
+				"agent":       `"Mozilla/4.08"`,
+			},
+		},
+		{
+			"ApacheErrorLog",
+			"%{HTTPD_ERRORLOG}",
+			`[Wed Oct 11 14:32:52 2000] [error] [client 127.0.0.1] client denied by server configuration: /export/home/live/ap/htdocs/test`,
+			map[string]interface{}{
+				"timestamp": "Wed Oct 11 14:32:52 2000",
+				"loglevel":  "error",
+				"clientip":  "127.0.0.1",
+				"message":   "client denied by server configuration: /export/home/live/ap/htdocs/test",
+			},
+		},
+		{
+			"Apache24ErrorLog",
+			"%{HTTPD_ERRORLOG}",
+			`[Fri Sep 09 10:42:29.902022 2011] [core:error] [pid 35708:tid 4328636416] [client 72.15.99.187:53654] AH00128: File does not exist: /usr/local/apache2/htdocs/favicon.ico`,
+			map[string]interface{}{
+				"timestamp":  "Fri Sep 09 10:42:29.902022 2011",
+				"module":     "core",
+				"loglevel":   "error",
+				"pid":        "35708",
+				"tid":        "4328636416",
+				"clientip":   "72.15.99.187",
+				"clientport": "53654",
+				"errorcode":  "AH00128",
+				"message":    "File does not exist: /usr/local/apache2/htdocs/favicon.ico",
+			},
+		},
+		{
+			"NginxAccessLog",
+			"%{NGINX_ACCESS}",
+			`2001:db8::1 - - [16/Oct/2022:06:25:14 +0000] "POST /api/v1/logs HTTP/1.1" 204 - "-" "curl/7.81.0"`,
+			map[string]interface{}{
+				"remote_addr":     "2001:db8::1",
+				"remote_user":     "-",
+				"time_local":      "16/Oct/2022:06:25:14 +0000",
+				"method":          "POST",
+				"request":         "/api/v1/logs",
+				"http_version":    "1.1",
+				"status":          "204",
+				"http_referer":    `"-"`,
+				"http_user_agent": `"curl/7.81.0"`,
+			},
+		},
+		{
+			"NginxErrorLog",
+			"%{NGINX_ERRORLOG}",
+			`2022/10/16 06:25:14 [warn] 12#12: *3 an upstream response is buffered to a temporary file`,
+			map[string]interface{}{
+				"timestamp":     "2022/10/16 06:25:14",
+				"loglevel":      "warn",
+				"pid":           "12",
+				"tid":           "12",
+				"connection_id": "3",
+				"message":       "an upstream response is buffered to a temporary file",
+			},
+		},
+		{
+			"HAProxyTCPLog",
+			"%{HAPROXYTCP}",
+			`Oct 16 06:25:14 lb1 haproxy[1234]: 10.0.0.1:45678 [16/Oct/2022:06:25:14.123] fe_db be_db/db1 1/0/5007 212 -- 1/1/1/1/0 0/0`,
+			map[string]interface{}{
+				"syslog_timestamp":     "Oct 16 06:25:14",
+				"syslog_server":        "lb1",
+				"program":              "haproxy",
+				"pid":                  "1234",
+				"client_ip":            "10.0.0.1",
+				"client_port":          "45678",
+				"accept_date":          "16/Oct/2022:06:25:14.123",
+				"haproxy_monthday":     "16",
+				"haproxy_month":        "Oct",
+				"haproxy_year":         "2022",
+				"haproxy_time":         "06:25:14",
+				"haproxy_hour":         "06",
+				"haproxy_minute":       "25",
+				"haproxy_second":       "14",
+				"haproxy_milliseconds": "123",
+				"frontend_name":        "fe_db",
+				"backend_name":         "be_db",
+				"server_name":          "db1",
+				"time_queue":           "1",
+				"time_backend_connect": "0",
+				"time_duration":        "5007",
+				"bytes_read":           "212",
+				"termination_state":    "--",
+				"actconn":              "1",
+				"feconn":               "1",
+				"beconn":               "1",
+				"srvconn":              "1",
+				"retries":              "0",
+				"srv_queue":            "0",
+				"backend_queue":        "0",
+			},
+		},
+		{
+			"PostgreSQLLog",
+			"%{POSTGRESQL}",
+			`2022-10-16 06:25:14.123 UTC [4321] app@orders ERROR:  relation "users" does not exist at character 15`,
+			map[string]interface{}{
+				"timestamp": "2022-10-16 06:25:14.123",
+				"timezone":  "UTC",
+				"pid":       "4321",
+				"user":      "app",
+				"database":  "orders",
+				"level":     "ERROR",
+				"message":   `relation "users" does not exist at character 15`,
+			},
+		},
+		{
+			"Syslog",
+			"%{SYSLOGBASE} %{GREEDYDATA:message}",
+			`Oct 16 06:25:14 host1 sshd[987]: Accepted publickey for root from 10.0.0.2 port 51234 ssh2`,
+			map[string]interface{}{
+				"timestamp": "Oct 16 06:25:14",
+				"logsource": "host1",
+				"program":   "sshd",
+				"pid":       "987",
+				"message":   "Accepted publickey for root from 10.0.0.2 port 51234 ssh2",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, "^"+tc.pattern+"$")
+			parsed, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestGrokParserTypes(t *testing.T) {
+	parser := newTestParser(t, `^%{WORD:method} %{INT:status:int} %{NUMBER:duration:float} %{NUMBER:size:string}$`)
+	parsed, err := parser.parse("GET 200 0.25 512")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"method":   "GET",
+		"status":   int64(200),
+		"duration": 0.25,
+		"size":     "512",
+	}, parsed)
+
+	parser = newTestParser(t, `^%{NUMBER:status:int}$`)
+	_, err = parser.parse("2.5")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "convert field 'status' to int")
+}
+
+func TestGrokParserPatternOrder(t *testing.T) {
+	parser := newTestParser(t,
+		`^%{IP:client} %{WORD:verb}$`,
+		`^%{HOSTNAME:host} %{WORD:verb}$`,
+		`^%{GREEDYDATA:message}$`,
+	)
+
+	parsed, err := parser.parse("10.0.0.1 GET")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"client": "10.0.0.1", "verb": "GET"}, parsed)
+
+	parsed, err = parser.parse("example.com GET")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"host": "example.com", "verb": "GET"}, parsed)
+
+	parsed, err = parser.parse("something else entirely")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"message": "something else entirely"}, parsed)
+}
+
+func TestGrokParserNoMatch(t *testing.T) {
+	parser := newTestParser(t, `^%{INT:a}$`, `^%{IP:b}$`)
+	_, err := parser.parse("not a number")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "grok patterns do not match")
+
+	_, err = parser.parse([]byte("1"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "type '[]uint8' cannot be parsed as grok")
+}
+
+func TestGrokParserOptionalAndNamedGroups(t *testing.T) {
+	parser := newTestParser(t, `^(?P<level>[A-Z]+)(?: \[%{WORD:thread}\])? %{GREEDYDATA:message}$`)
+
+	parsed, err := parser.parse("INFO [main] started")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"level": "INFO", "thread": "main", "message": "started"}, parsed)
+
+	// Groups that did not take part in the match are left out
+	parsed, err = parser.parse("WARN stopping")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"level": "WARN", "message": "stopping"}, parsed)
+}
+
+func TestGrokParserCustomPatterns(t *testing.T) {
+	patternsDir := testutil.NewTempDir(t)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(patternsDir, "app"), []byte(`
+# Patterns of the application
+APP_ID app-[0-9]+
+APP_LOG %{APP_ID:app} %{LOGLEVEL:level} %{GREEDYDATA:message}
+INT [0-9]+
+`), 0600))
+
+	cfg := NewGrokParserConfig("test")
+	cfg.Patterns = []string{`^%{APP_LOG} took %{DURATION:duration}$`}
+	cfg.PatternsDir = patternsDir
+	cfg.PatternDefinitions = map[string]string{
+		"DURATION": `%{INT}ms`,
+		// Configured definitions override the ones in the patterns directory
+		"APP_ID": `svc-[0-9]+`,
+	}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := ops[0].(*GrokParser)
+
+	parsed, err := parser.parse("svc-12 info request took 15ms")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"app":      "svc-12",
+		"level":    "info",
+		"message":  "request",
+		"duration": "15ms",
+	}, parsed)
+}
+
+func TestGrokParserProcess(t *testing.T) {
+	cfg := NewGrokParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Patterns = []string{`^%{TIMESTAMP_ISO8601:time} %{LOGLEVEL:level} %{GREEDYDATA:message}$`}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = "2022-10-16T06:25:14Z INFO started"
+	require.NoError(t, op.Process(context.Background(), e))
+
+	fake.ExpectBody(t, map[string]interface{}{
+		"time":    "2022-10-16T06:25:14Z",
+		"level":   "INFO",
+		"message": "started",
+	})
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
@@ -0,0 +1,209 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grok
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// bundledPatterns is the pattern library that is available to every grok parser
+//
+//go:embed patterns
+var bundledPatterns embed.FS
+
+// defaultDefinitions are the definitions of the bundled patterns
+var defaultDefinitions = mustLoadBundledPatterns()
+
+var (
+	// patternName matches the names of pattern definitions
+	patternName = regexp.MustCompile(`^\w+$`)
+	// patternReference matches a reference to a pattern in the form %{NAME}, %{NAME:field} or %{NAME:field:type}
+	patternReference = regexp.MustCompile(`%\{(\w+)(?::([^:}]+))?(?::(\w+))?\}`)
+)
+
+const (
+	stringType = "string"
+	intType    = "int"
+	floatType  = "float"
+)
+
+func mustLoadBundledPatterns() map[string]string {
+	definitions := map[string]string{}
+	files, err := fs.ReadDir(bundledPatterns, "patterns")
+	if err != nil {
+		panic(err)
+	}
+	for _, file := range files {
+		f, err := bundledPatterns.Open("patterns/" + file.Name())
+		if err != nil {
+			panic(err)
+		}
+		err = loadPatterns(f, definitions)
+		f.Close()
+		if err != nil {
+			panic(fmt.Sprintf("load bundled patterns %s: %s", file.Name(), err))
+		}
+	}
+	return definitions
+}
+
+// loadPatternsDir loads the definitions of every file in a directory
+func loadPatternsDir(dir string, definitions map[string]string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		f, err := os.Open(path) // #nosec - operator must read in pattern files specified by user
+		if err != nil {
+			return err
+		}
+		err = loadPatterns(f, definitions)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+	return nil
+}
+
+// loadPatterns reads definitions in the form `NAME pattern`, one per line. Empty lines and
+// lines starting with '#' are skipped.
+func loadPatterns(r io.Reader, definitions map[string]string) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(text, " ", 2)
+		if len(parts) != 2 || !patternName.MatchString(parts[0]) {
+			return fmt.Errorf("line %d: expected a pattern in the form 'NAME pattern'", line)
+		}
+		definitions[parts[0]] = strings.TrimSpace(parts[1])
+	}
+	return scanner.Err()
+}
+
+// grokField is the field a capture group is parsed to
+type grokField struct {
+	name     string
+	dataType string
+}
+
+// grokPattern is a compiled grok pattern
+type grokPattern struct {
+	pattern string
+	regexp  *regexp.Regexp
+	// fields holds the field of each capture group, or an empty field if the group is not parsed
+	fields []grokField
+}
+
+// compilePattern expands the references to other patterns in a pattern, then compiles it.
+// References with a field name become named capture groups, and named capture groups
+// written in the pattern are parsed as strings.
+func compilePattern(pattern string, definitions map[string]string) (*grokPattern, error) {
+	e := &expander{definitions: definitions, captures: map[string]grokField{}}
+	expanded, err := e.expand(pattern, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("compiling regex: %s", err)
+	}
+
+	fields := make([]grokField, len(r.SubexpNames()))
+	for i, name := range r.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		if field, ok := e.captures[name]; ok {
+			fields[i] = field
+			continue
+		}
+		fields[i] = grokField{name: name, dataType: stringType}
+	}
+
+	return &grokPattern{pattern: pattern, regexp: r, fields: fields}, nil
+}
+
+// expander replaces references to patterns with their definitions
+type expander struct {
+	definitions map[string]string
+	captures    map[string]grokField
+}
+
+func (e *expander) expand(pattern string, stack []string) (string, error) {
+	var err error
+	expanded := patternReference.ReplaceAllStringFunc(pattern, func(reference string) string {
+		if err != nil {
+			return ""
+		}
+
+		parts := patternReference.FindStringSubmatch(reference)
+		name, field, dataType := parts[1], parts[2], parts[3]
+
+		definition, ok := e.definitions[name]
+		if !ok {
+			err = fmt.Errorf("pattern '%s' is not defined", name)
+			return ""
+		}
+		for _, parent := range stack {
+			if parent == name {
+				err = fmt.Errorf("pattern '%s' references itself", name)
+				return ""
+			}
+		}
+
+		var inner string
+		inner, err = e.expand(definition, append(stack, name))
+		if err != nil {
+			return ""
+		}
+		if field == "" {
+			return "(?:" + inner + ")"
+		}
+
+		switch dataType {
+		case "":
+			dataType = stringType
+		case stringType, intType, floatType:
+		default:
+			err = fmt.Errorf("invalid type '%s' for field '%s'", dataType, field)
+			return ""
+		}
+
+		group := fmt.Sprintf("grok%d", len(e.captures))
+		e.captures[group] = grokField{name: field, dataType: dataType}
+		return "(?P<" + group + ">" + inner + ")"
+	})
+	return expanded, err
+}
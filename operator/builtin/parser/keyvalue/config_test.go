@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyvalue
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestKeyValueParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "parse_from",
+			Expect: func() *KeyValueParserConfig {
+				cfg := defaultCfg()
+				cfg.ParseFrom = entry.NewBodyField("message")
+				cfg.ParseTo = entry.NewBodyField("pairs")
+				return cfg
+			}(),
+		},
+		{
+			Name: "delimiters",
+			Expect: func() *KeyValueParserConfig {
+				cfg := defaultCfg()
+				cfg.Delimiter = ":"
+				cfg.PairDelimiter = ","
+				cfg.Quotes = `"'`
+				cfg.Escape = ""
+				return cfg
+			}(),
+		},
+		{
+			Name: "strict",
+			Expect: func() *KeyValueParserConfig {
+				cfg := defaultCfg()
+				cfg.Strict = true
+				return cfg
+			}(),
+		},
+		{
+			Name: "timestamp",
+			Expect: func() *KeyValueParserConfig {
+				cfg := defaultCfg()
+				parseField := entry.NewBodyField("ts")
+				cfg.TimeParser = &helper.TimeParser{
+					LayoutType: "strptime",
+					Layout:     "%Y-%m-%d",
+					ParseFrom:  &parseField,
+				}
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *KeyValueParserConfig {
+	return NewKeyValueParserConfig("key_value_parser")
+}
@@ -0,0 +1,286 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyvalue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("key_value_parser", func() operator.Builder { return NewKeyValueParserConfig("") })
+}
+
+// NewKeyValueParserConfig creates a new key value parser config with default values
+func NewKeyValueParserConfig(operatorID string) *KeyValueParserConfig {
+	return &KeyValueParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "key_value_parser"),
+		Delimiter:    "=",
+		Quotes:       `"`,
+		Escape:       `\`,
+	}
+}
+
+// KeyValueParserConfig is the configuration of a key value parser operator.
+type KeyValueParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	Delimiter     string `mapstructure:"delimiter"      json:"delimiter"      yaml:"delimiter"`
+	PairDelimiter string `mapstructure:"pair_delimiter" json:"pair_delimiter" yaml:"pair_delimiter"`
+	Quotes        string `mapstructure:"quotes"         json:"quotes"         yaml:"quotes"`
+	Escape        string `mapstructure:"escape"         json:"escape"         yaml:"escape"`
+	Strict        bool   `mapstructure:"strict"         json:"strict"         yaml:"strict"`
+}
+
+// Build will build a key value parser operator.
+func (c KeyValueParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Delimiter == "" {
+		return nil, fmt.Errorf("missing required field `delimiter`")
+	}
+	if c.Delimiter == c.PairDelimiter {
+		return nil, fmt.Errorf("`delimiter` and `pair_delimiter` must differ")
+	}
+	if utf8.RuneCountInString(c.Escape) > 1 {
+		return nil, fmt.Errorf("invalid `escape` '%s': must be a single character", c.Escape)
+	}
+	special := c.Quotes + c.Escape
+	if strings.ContainsAny(c.Delimiter, special) || strings.ContainsAny(c.PairDelimiter, special) {
+		return nil, fmt.Errorf("`delimiter` and `pair_delimiter` must not contain the `quotes` or `escape` characters")
+	}
+	if c.Escape != "" && strings.Contains(c.Quotes, c.Escape) {
+		return nil, fmt.Errorf("`quotes` must not contain the `escape` character")
+	}
+
+	keyValueParser := &KeyValueParser{
+		ParserOperator: parserOperator,
+		delimiter:      c.Delimiter,
+		pairDelimiter:  c.PairDelimiter,
+		quotes:         c.Quotes,
+		strict:         c.Strict,
+	}
+	if c.Escape != "" {
+		keyValueParser.escape, _ = utf8.DecodeRuneInString(c.Escape)
+	}
+
+	return []operator.Operator{keyValueParser}, nil
+}
+
+// KeyValueParser is an operator that parses key value pairs, such as logfmt, in an entry.
+type KeyValueParser struct {
+	helper.ParserOperator
+	delimiter     string
+	pairDelimiter string
+	quotes        string
+	escape        rune
+	strict        bool
+}
+
+// Process will parse an entry for key value pairs.
+func (p *KeyValueParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ParserOperator.ProcessWith(ctx, entry, p.parse)
+}
+
+// parse will parse a value as key value pairs. Words that are not pairs are skipped,
+// unless the parser is strict, in which case they are an error.
+func (p *KeyValueParser) parse(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("type %T cannot be parsed as key value pairs", value)
+	}
+
+	parsed := make(map[string]interface{})
+	for i := p.skipPairDelimiters(s, 0); i < len(s); i = p.skipPairDelimiters(s, i) {
+		key, next, err := p.readToken(s, i, true)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(s[next:], p.delimiter) {
+			if p.strict {
+				return nil, fmt.Errorf("parse key value: '%s' is not a key value pair", s[i:next])
+			}
+			i = next
+			continue
+		}
+
+		// A strict parser requires a delimiter within a value to be quoted
+		value, end, err := p.readToken(s, next+len(p.delimiter), p.strict)
+		if err != nil {
+			return nil, err
+		}
+		if p.strict && strings.HasPrefix(s[end:], p.delimiter) {
+			return nil, fmt.Errorf("parse key value: unexpected '%s' in the value of '%s'", p.delimiter, key)
+		}
+		i = end
+
+		if key == "" {
+			if p.strict {
+				return nil, fmt.Errorf("parse key value: empty key")
+			}
+			continue
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
+
+// readToken reads a key or value starting at i, and returns it along with the index after it.
+// A token ends at a pair delimiter, at the end of the string, or at the delimiter if
+// stopAtDelimiter is set. Quoted tokens are unquoted, and spaces around tokens are ignored.
+func (p *KeyValueParser) readToken(s string, i int, stopAtDelimiter bool) (string, int, error) {
+	i = p.skipSpaces(s, i)
+	start := i
+
+	var token strings.Builder
+	var quote rune
+	quoted := false
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if quote == 0 {
+			if p.pairDelimiterAt(s, i) > 0 || stopAtDelimiter && strings.HasPrefix(s[i:], p.delimiter) {
+				break
+			}
+			if quoted && unicode.IsSpace(r) {
+				i += size
+				continue
+			}
+			if p.strict && quoted {
+				return "", 0, fmt.Errorf("parse key value: unexpected '%c' after quoted token at %d", r, i)
+			}
+			if strings.ContainsRune(p.quotes, r) {
+				switch {
+				case i == start:
+					quote = r
+					quoted = true
+					i += size
+					continue
+				case p.strict:
+					return "", 0, fmt.Errorf("parse key value: unexpected quote at %d", i)
+				}
+			}
+		} else if r == quote {
+			quote = 0
+			i += size
+			continue
+		}
+
+		if p.escape != 0 && r == p.escape {
+			n, err := p.unescape(&token, s, i+size)
+			if err != nil {
+				return "", 0, err
+			}
+			i += size + n
+			continue
+		}
+
+		token.WriteRune(r)
+		i += size
+	}
+
+	if quote != 0 && p.strict {
+		return "", 0, fmt.Errorf("parse key value: unterminated quote at %d", start)
+	}
+	if !quoted {
+		return strings.TrimSpace(token.String()), i, nil
+	}
+	return token.String(), i, nil
+}
+
+// unescape writes the character escaped at i to the token, and returns the size of the escaped character.
+// Escaping a character that has no special meaning is an error if the parser is strict, and otherwise
+// leaves the escape character in place.
+func (p *KeyValueParser) unescape(token *strings.Builder, s string, i int) (int, error) {
+	if i >= len(s) {
+		if p.strict {
+			return 0, fmt.Errorf("parse key value: trailing escape")
+		}
+		token.WriteRune(p.escape)
+		return 0, nil
+	}
+
+	r, size := utf8.DecodeRuneInString(s[i:])
+	switch {
+	case r == 'n':
+		token.WriteByte('\n')
+	case r == 't':
+		token.WriteByte('\t')
+	case r == 'r':
+		token.WriteByte('\r')
+	case r == p.escape || strings.ContainsRune(p.quotes, r) || strings.HasPrefix(s[i:], p.delimiter) || p.pairDelimiterAt(s, i) > 0:
+		token.WriteRune(r)
+	case p.strict:
+		return 0, fmt.Errorf("parse key value: invalid escape '%c%c'", p.escape, r)
+	default:
+		token.WriteRune(p.escape)
+		token.WriteRune(r)
+	}
+	return size, nil
+}
+
+// pairDelimiterAt returns the size of the pair delimiter at i, or 0 if there is none.
+// Without a pair delimiter, pairs are delimited by any amount of whitespace.
+func (p *KeyValueParser) pairDelimiterAt(s string, i int) int {
+	if p.pairDelimiter != "" {
+		if strings.HasPrefix(s[i:], p.pairDelimiter) {
+			return len(p.pairDelimiter)
+		}
+		return 0
+	}
+
+	n := 0
+	for i+n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i+n:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+// skipSpaces returns the index after the spaces at i that are not part of a pair delimiter
+func (p *KeyValueParser) skipSpaces(s string, i int) int {
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !unicode.IsSpace(r) || p.pairDelimiterAt(s, i) > 0 {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// skipPairDelimiters returns the index after the pair delimiters at i
+func (p *KeyValueParser) skipPairDelimiters(s string, i int) int {
+	for i < len(s) {
+		n := p.pairDelimiterAt(s, i)
+		if n == 0 {
+			break
+		}
+		i += n
+	}
+	return i
+}
@@ -0,0 +1,291 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyvalue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, mod func(*KeyValueParserConfig)) *KeyValueParser {
+	cfg := NewKeyValueParserConfig("test")
+	if mod != nil {
+		mod(cfg)
+	}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return ops[0].(*KeyValueParser)
+}
+
+func TestKeyValueParserBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		mod       func(*KeyValueParserConfig)
+		expectErr bool
+	}{
+		{"Default", nil, false},
+		{"PairDelimiter", func(cfg *KeyValueParserConfig) { cfg.PairDelimiter = "," }, false},
+		{"NoQuotesOrEscape", func(cfg *KeyValueParserConfig) { cfg.Quotes, cfg.Escape = "", "" }, false},
+		{"MissingDelimiter", func(cfg *KeyValueParserConfig) { cfg.Delimiter = "" }, true},
+		{"SameDelimiters", func(cfg *KeyValueParserConfig) { cfg.PairDelimiter = "=" }, true},
+		{"LongEscape", func(cfg *KeyValueParserConfig) { cfg.Escape = `\\` }, true},
+		{"QuoteInDelimiter", func(cfg *KeyValueParserConfig) { cfg.PairDelimiter = `"` }, true},
+		{"EscapeInDelimiter", func(cfg *KeyValueParserConfig) { cfg.Delimiter = `\` }, true},
+		{"EscapeInQuotes", func(cfg *KeyValueParserConfig) { cfg.Quotes = `"\` }, true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewKeyValueParserConfig("test")
+			if tc.mod != nil {
+				tc.mod(cfg)
+			}
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestKeyValueParserParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		mod      func(*KeyValueParserConfig)
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"Logfmt",
+			nil,
+			`level=info msg="Stopping all fetchers" tag=stopping_fetchers id=ConsumerFetcherManager-1382721708341 module=kafka.consumer.ConsumerFetcherManager`,
+			map[string]interface{}{
+				"level":  "info",
+				"msg":    "Stopping all fetchers",
+				"tag":    "stopping_fetchers",
+				"id":     "ConsumerFetcherManager-1382721708341",
+				"module": "kafka.consumer.ConsumerFetcherManager",
+			},
+		},
+		{
+			"EmptyValues",
+			nil,
+			`a= b="" c=1`,
+			map[string]interface{}{"a": "", "b": "", "c": "1"},
+		},
+		{
+			"Escapes",
+			nil,
+			`msg="say \"hi\"\n" path=a\ b`,
+			map[string]interface{}{"msg": "say \"hi\"\n", "path": "a b"},
+		},
+		{
+			"UnknownEscapeKept",
+			nil,
+			`path="C:\dir\file"`,
+			map[string]interface{}{"path": `C:\dir\file`},
+		},
+		{
+			"EscapeDisabled",
+			func(cfg *KeyValueParserConfig) { cfg.Escape = "" },
+			`path="C:\\dir\"`,
+			map[string]interface{}{"path": `C:\\dir\`},
+		},
+		{
+			"SingleQuotes",
+			func(cfg *KeyValueParserConfig) { cfg.Quotes = `"'` },
+			`a='one "two"' b="it's"`,
+			map[string]interface{}{"a": `one "two"`, "b": "it's"},
+		},
+		{
+			"QuotedKey",
+			nil,
+			`"user name"=alice`,
+			map[string]interface{}{"user name": "alice"},
+		},
+		{
+			"CustomDelimiters",
+			func(cfg *KeyValueParserConfig) {
+				cfg.Delimiter = ":"
+				cfg.PairDelimiter = ","
+			},
+			`name:alice, role: "site admin" ,url:https://example.com`,
+			map[string]interface{}{"name": "alice", "role": "site admin", "url": "https://example.com"},
+		},
+		{
+			"MultiCharacterDelimiters",
+			func(cfg *KeyValueParserConfig) {
+				cfg.Delimiter = "=>"
+				cfg.PairDelimiter = "||"
+			},
+			`a=>1||b=>x=y|z`,
+			map[string]interface{}{"a": "1", "b": "x=y|z"},
+		},
+		{
+			"SkipsWords",
+			nil,
+			`Accepted connection from=10.0.0.1 port=22`,
+			map[string]interface{}{"from": "10.0.0.1", "port": "22"},
+		},
+		{
+			"ValueWithDelimiter",
+			nil,
+			`query=a=b&c=d`,
+			map[string]interface{}{"query": "a=b&c=d"},
+		},
+		{
+			"UnterminatedQuote",
+			nil,
+			`a=1 msg="unterminated value`,
+			map[string]interface{}{"a": "1", "msg": "unterminated value"},
+		},
+		{
+			"EmptyKeySkipped",
+			nil,
+			`=1 b=2`,
+			map[string]interface{}{"b": "2"},
+		},
+		{
+			"DuplicateKey",
+			nil,
+			`a=1 a=2`,
+			map[string]interface{}{"a": "2"},
+		},
+		{
+			"Unicode",
+			nil,
+			"città=Zürich\tnote=\"日本 語\"",
+			map[string]interface{}{"città": "Zürich", "note": "日本 語"},
+		},
+		{
+			"Empty",
+			nil,
+			"",
+			map[string]interface{}{},
+		},
+		{
+			"StrictLogfmt",
+			func(cfg *KeyValueParserConfig) { cfg.Strict = true },
+			`ts=2021-06-22T10:27:25Z level=warn msg="disk \"data\" is 90% full" used=0.9`,
+			map[string]interface{}{"ts": "2021-06-22T10:27:25Z", "level": "warn", "msg": `disk "data" is 90% full`, "used": "0.9"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, tc.mod)
+			parsed, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestKeyValueParserParseFailure(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     interface{}
+		expectErr string
+	}{
+		{"Bytes", []byte("a=1"), "type []uint8 cannot be parsed as key value pairs"},
+		{"NotPair", "a=1 word", "'word' is not a key value pair"},
+		{"EmptyKey", "=1", "empty key"},
+		{"UnquotedDelimiter", "a=b=c", "unexpected '=' in the value of 'a'"},
+		{"UnterminatedQuote", `a="b`, "unterminated quote"},
+		{"AfterQuote", `a="b"c`, "unexpected 'c' after quoted token"},
+		{"QuoteInToken", `a=b"c"`, "unexpected quote"},
+		{"InvalidEscape", `a="\d"`, `invalid escape '\d'`},
+		{"TrailingEscape", `a=b\`, "trailing escape"},
+	}
+
+	parser := newTestParser(t, func(cfg *KeyValueParserConfig) { cfg.Strict = true })
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
+
+func TestKeyValueParserProcess(t *testing.T) {
+	cfg := NewKeyValueParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.ParseFrom = entry.NewBodyField("message")
+	cfg.ParseTo = entry.NewBodyField("pairs")
+
+	timeField := entry.NewBodyField("pairs", "ts")
+	timeParser := helper.NewTimeParser()
+	timeParser.ParseFrom = &timeField
+	timeParser.LayoutType = "gotime"
+	timeParser.Layout = time.RFC3339
+	cfg.TimeParser = &timeParser
+
+	severityField := entry.NewBodyField("pairs", "level")
+	severityParser := helper.NewSeverityParserConfig()
+	severityParser.ParseFrom = &severityField
+	cfg.SeverityParserConfig = &severityParser
+
+	traceParser := helper.NewTraceParser()
+	traceID := entry.NewBodyField("pairs", "trace_id")
+	spanID := entry.NewBodyField("pairs", "span_id")
+	traceFlags := entry.NewBodyField("pairs", "trace_flags")
+	traceParser.TraceId.ParseFrom = &traceID
+	traceParser.SpanId.ParseFrom = &spanID
+	traceParser.TraceFlags.ParseFrom = &traceFlags
+	cfg.TraceParser = &traceParser
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"hostname": "app01",
+		"message":  `ts=2021-06-22T10:27:25Z level=error msg="request failed" trace_id=480140f3d770a5ae32f0a22b6a812cff span_id=92c3792d54ba94f3 trace_flags=01`,
+	}
+	require.NoError(t, op.Process(context.Background(), e))
+
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, time.Date(2021, time.June, 22, 10, 27, 25, 0, time.UTC), e.Timestamp)
+		require.Equal(t, entry.Error, e.Severity)
+		require.Equal(t, "480140f3d770a5ae32f0a22b6a812cff", fmt.Sprintf("%x", e.TraceId))
+		require.Equal(t, "92c3792d54ba94f3", fmt.Sprintf("%x", e.SpanId))
+		require.Equal(t, map[string]interface{}{
+			"hostname": "app01",
+			"pairs":    map[string]interface{}{"msg": "request failed"},
+		}, e.Body)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
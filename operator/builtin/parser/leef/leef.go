@@ -0,0 +1,195 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leef
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const leefPrefix = "LEEF:"
+
+// headerFields are the names of the fields of a LEEF header, in order
+var headerFields = [...]string{
+	"version",
+	"vendor",
+	"product",
+	"product_version",
+	"event_id",
+}
+
+func init() {
+	operator.Register("leef_parser", func() operator.Builder { return NewLEEFParserConfig("") })
+}
+
+// NewLEEFParserConfig creates a new LEEF parser config with default values
+func NewLEEFParserConfig(operatorID string) *LEEFParserConfig {
+	return &LEEFParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "leef_parser"),
+	}
+}
+
+// LEEFParserConfig is the configuration of a LEEF parser operator.
+type LEEFParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+}
+
+// Build will build a LEEF parser operator.
+func (c LEEFParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	leefParser := &LEEFParser{
+		ParserOperator: parserOperator,
+	}
+
+	return []operator.Operator{leefParser}, nil
+}
+
+// LEEFParser is an operator that parses IBM Log Event Extended Format messages in an entry.
+type LEEFParser struct {
+	helper.ParserOperator
+}
+
+// Process will parse an entry for a LEEF message.
+func (l *LEEFParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return l.ParserOperator.ProcessWith(ctx, entry, l.parse)
+}
+
+// parse will parse a value as a LEEF message, in the format `LEEF:1.0|Vendor|Product|Version|EventID|Attributes`
+// or `LEEF:2.0|Vendor|Product|Version|EventID|Delimiter|Attributes`, where the delimiter is optional.
+func (l *LEEFParser) parse(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("type %T cannot be parsed as LEEF", value)
+	}
+
+	if !strings.HasPrefix(s, leefPrefix) {
+		return nil, fmt.Errorf("parse LEEF: missing '%s' prefix", leefPrefix)
+	}
+
+	header, rest, err := splitHeader(s[len(leefPrefix):])
+	if err != nil {
+		return nil, err
+	}
+
+	delimiter := "\t"
+	switch header[0] {
+	case "1.0":
+	case "2.0":
+		if i := strings.IndexByte(rest, '|'); i >= 0 {
+			if d, ok := parseDelimiter(rest[:i]); ok {
+				delimiter = d
+				rest = rest[i+1:]
+			}
+		}
+	default:
+		return nil, fmt.Errorf("parse LEEF: unsupported version '%s'", header[0])
+	}
+
+	attributes, err := parseAttributes(rest, delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := make(map[string]interface{}, len(headerFields)+1)
+	for i, field := range headerFields {
+		parsed[field] = header[i]
+	}
+	parsed["attributes"] = attributes
+	return parsed, nil
+}
+
+// splitHeader splits a LEEF message on the unescaped pipes that separate the header fields,
+// and unescapes the fields. The rest of the message is returned as it is written.
+func splitHeader(s string) ([]string, string, error) {
+	header := make([]string, 0, len(headerFields))
+	var field strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && (s[i+1] == '|' || s[i+1] == '\\'):
+			i++
+			field.WriteByte(s[i])
+		case s[i] == '|':
+			header = append(header, field.String())
+			field.Reset()
+			if len(header) == len(headerFields) {
+				return header, s[i+1:], nil
+			}
+		default:
+			field.WriteByte(s[i])
+		}
+	}
+
+	// The attributes may be left out along with the pipe that precedes them
+	header = append(header, field.String())
+	if len(header) < len(headerFields) {
+		return nil, "", fmt.Errorf("parse LEEF: expected %d header fields, found %d", len(headerFields), len(header))
+	}
+	return header, "", nil
+}
+
+// parseDelimiter parses the delimiter field of a LEEF 2.0 header, which is either a single
+// character, or its code in hex such as `x09` or `0x09`. An empty field stands for a tab.
+func parseDelimiter(s string) (string, bool) {
+	if s == "" {
+		return "\t", true
+	}
+	if utf8.RuneCountInString(s) == 1 && s != "=" {
+		return s, true
+	}
+
+	var hex string
+	switch lower := strings.ToLower(s); {
+	case strings.HasPrefix(lower, "0x"):
+		hex = lower[2:]
+	case strings.HasPrefix(lower, "x"):
+		hex = lower[1:]
+	}
+	if len(hex) == 0 || len(hex) > 4 {
+		return "", false
+	}
+	code, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || code == '=' {
+		return "", false
+	}
+	return string(rune(code)), true
+}
+
+// parseAttributes parses the key=value pairs of a LEEF message, separated by the delimiter
+func parseAttributes(s, delimiter string) (map[string]interface{}, error) {
+	attributes := make(map[string]interface{})
+	for _, pair := range strings.Split(s, delimiter) {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || key == "" {
+			return nil, fmt.Errorf("parse LEEF: invalid attribute '%s'", pair)
+		}
+		attributes[key] = kv[1]
+	}
+	return attributes, nil
+}
@@ -0,0 +1,232 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leef
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T) *LEEFParser {
+	cfg := NewLEEFParserConfig("test")
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return ops[0].(*LEEFParser)
+}
+
+func TestLEEFParserParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"Version1",
+			"LEEF:1.0|Microsoft|MSExchange|4.0 SP1|15345|src=192.0.2.0\tdst=172.50.123.1\tsev=5\tcat=anomaly\tmsg=the system was attacked",
+			map[string]interface{}{
+				"version":         "1.0",
+				"vendor":          "Microsoft",
+				"product":         "MSExchange",
+				"product_version": "4.0 SP1",
+				"event_id":        "15345",
+				"attributes": map[string]interface{}{
+					"src": "192.0.2.0",
+					"dst": "172.50.123.1",
+					"sev": "5",
+					"cat": "anomaly",
+					"msg": "the system was attacked",
+				},
+			},
+		},
+		{
+			"Version2Delimiter",
+			"LEEF:2.0|Lancope|StealthWatch|1.0|41|^|src=10.0.1.8^dst=10.0.0.5^sev=5^srcPort=81^dstPort=21",
+			map[string]interface{}{
+				"version":         "2.0",
+				"vendor":          "Lancope",
+				"product":         "StealthWatch",
+				"product_version": "1.0",
+				"event_id":        "41",
+				"attributes": map[string]interface{}{
+					"src":     "10.0.1.8",
+					"dst":     "10.0.0.5",
+					"sev":     "5",
+					"srcPort": "81",
+					"dstPort": "21",
+				},
+			},
+		},
+		{
+			"Version2HexDelimiter",
+			"LEEF:2.0|Vendor|Product|1.0|42|0x7c|src=10.0.1.8|dst=10.0.0.5",
+			map[string]interface{}{
+				"version":         "2.0",
+				"vendor":          "Vendor",
+				"product":         "Product",
+				"product_version": "1.0",
+				"event_id":        "42",
+				"attributes": map[string]interface{}{
+					"src": "10.0.1.8",
+					"dst": "10.0.0.5",
+				},
+			},
+		},
+		{
+			"Version2ShortHexDelimiter",
+			"LEEF:2.0|Vendor|Product|1.0|42|x09|src=10.0.1.8\tdst=10.0.0.5",
+			map[string]interface{}{
+				"version":         "2.0",
+				"vendor":          "Vendor",
+				"product":         "Product",
+				"product_version": "1.0",
+				"event_id":        "42",
+				"attributes": map[string]interface{}{
+					"src": "10.0.1.8",
+					"dst": "10.0.0.5",
+				},
+			},
+		},
+		{
+			"Version2WithoutDelimiter",
+			"LEEF:2.0|Vendor|Product|1.0|43|src=10.0.1.8\tmsg=a|b",
+			map[string]interface{}{
+				"version":         "2.0",
+				"vendor":          "Vendor",
+				"product":         "Product",
+				"product_version": "1.0",
+				"event_id":        "43",
+				"attributes": map[string]interface{}{
+					"src": "10.0.1.8",
+					"msg": "a|b",
+				},
+			},
+		},
+		{
+			"EscapedHeader",
+			`LEEF:1.0|Vendor\|Inc|Product|1.0|Login\\Logout|usrName=admin	url=https://example.com/?a=b`,
+			map[string]interface{}{
+				"version":         "1.0",
+				"vendor":          "Vendor|Inc",
+				"product":         "Product",
+				"product_version": "1.0",
+				"event_id":        `Login\Logout`,
+				"attributes": map[string]interface{}{
+					"usrName": "admin",
+					"url":     "https://example.com/?a=b",
+				},
+			},
+		},
+		{
+			"EmptyAttributes",
+			"LEEF:1.0|Vendor|Product|1.0|44|\t",
+			map[string]interface{}{
+				"version":         "1.0",
+				"vendor":          "Vendor",
+				"product":         "Product",
+				"product_version": "1.0",
+				"event_id":        "44",
+				"attributes":      map[string]interface{}{},
+			},
+		},
+		{
+			"MissingAttributes",
+			"LEEF:1.0|Vendor|Product|1.0|45",
+			map[string]interface{}{
+				"version":         "1.0",
+				"vendor":          "Vendor",
+				"product":         "Product",
+				"product_version": "1.0",
+				"event_id":        "45",
+				"attributes":      map[string]interface{}{},
+			},
+		},
+	}
+
+	parser := newTestParser(t)
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestLEEFParserParseFailure(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     interface{}
+		expectErr string
+	}{
+		{"Bytes", []byte("LEEF:1.0|a|b|c|d|"), "type []uint8 cannot be parsed as LEEF"},
+		{"MissingPrefix", "CEF:0|a|b|c|d|e|1|", "missing 'LEEF:' prefix"},
+		{"MissingHeaderFields", "LEEF:1.0|Vendor|Product|1.0", "expected 5 header fields, found 4"},
+		{"UnsupportedVersion", "LEEF:3.0|Vendor|Product|1.0|41|src=10.0.0.1", "unsupported version '3.0'"},
+		{"InvalidAttribute", "LEEF:1.0|Vendor|Product|1.0|41|src=10.0.0.1\tinvalid", "invalid attribute 'invalid'"},
+		{"EmptyKey", "LEEF:1.0|Vendor|Product|1.0|41|=10.0.0.1", "invalid attribute '=10.0.0.1'"},
+	}
+
+	parser := newTestParser(t)
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
+
+func TestLEEFParserProcess(t *testing.T) {
+	cfg := NewLEEFParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.ParseFrom = entry.NewBodyField("message")
+	cfg.ParseTo = entry.NewBodyField("leef")
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = map[string]interface{}{
+		"hostname": "ids01",
+		"message":  "LEEF:2.0|Vendor|IDS|2.1|portscan|;|src=10.0.0.5;sev=8",
+	}
+	require.NoError(t, op.Process(context.Background(), e))
+
+	fake.ExpectBody(t, map[string]interface{}{
+		"hostname": "ids01",
+		"leef": map[string]interface{}{
+			"version":         "2.0",
+			"vendor":          "Vendor",
+			"product":         "IDS",
+			"product_version": "2.1",
+			"event_id":        "portscan",
+			"attributes": map[string]interface{}{
+				"src": "10.0.0.5",
+				"sev": "8",
+			},
+		},
+	})
+}
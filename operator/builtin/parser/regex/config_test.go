@@ -100,6 +100,18 @@ func TestRegexParserGoldenConfig(t *testing.T) {
 				return cfg
 			}(),
 		},
+		{
+			Name: "patterns",
+			Expect: func() *RegexParserConfig {
+				cfg := defaultCfg()
+				cfg.Patterns = []PatternConfig{
+					{Name: "access", Regex: `^(?P<ip>\S+) (?P<method>GET|POST) (?P<path>\S+)$`},
+					{Name: "error", Regex: "^ERROR (?P<message>.*)$"},
+				}
+				cfg.PatternAttribute = "log.format"
+				return cfg
+			}(),
+		},
 	}
 
 	for _, tc := range cases {
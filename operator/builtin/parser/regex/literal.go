@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regex
+
+// literalMatcher finds which of a set of literals a value contains in a single scan of the value,
+// using the Aho-Corasick algorithm. The cost of a scan depends on the length of the value, and
+// not on the number of literals.
+type literalMatcher struct {
+	// classes maps each byte to its column in the transition table. Bytes that are not in any
+	// literal share column 0.
+	classes [256]int
+	width   int
+	// next is the transition table, indexed by state*width + class
+	next []int
+	// literals are the indexes of the literals that end at each state
+	literals [][]int
+}
+
+// newLiteralMatcher builds a matcher of the literals. Empty literals are never matched.
+func newLiteralMatcher(literals []string) *literalMatcher {
+	m := &literalMatcher{width: 1}
+	for _, literal := range literals {
+		for i := 0; i < len(literal); i++ {
+			if m.classes[literal[i]] == 0 {
+				m.classes[literal[i]] = m.width
+				m.width++
+			}
+		}
+	}
+
+	// Build a trie of the literals, where 0 marks a missing transition, since no transition leads to the root
+	m.next = make([]int, m.width)
+	m.literals = make([][]int, 1)
+	for i, literal := range literals {
+		if literal == "" {
+			continue
+		}
+		state := 0
+		for j := 0; j < len(literal); j++ {
+			class := m.classes[literal[j]]
+			if m.next[state*m.width+class] == 0 {
+				m.next[state*m.width+class] = len(m.literals)
+				m.next = append(m.next, make([]int, m.width)...)
+				m.literals = append(m.literals, nil)
+			}
+			state = m.next[state*m.width+class]
+		}
+		m.literals[state] = append(m.literals[state], i)
+	}
+
+	// Turn the trie into a complete transition table breadth first, since the state a missing
+	// transition falls back to, the longest suffix that is a prefix of a literal, is shallower
+	fail := make([]int, len(m.literals))
+	queue := make([]int, 0, len(m.literals))
+	for class := 0; class < m.width; class++ {
+		if next := m.next[class]; next != 0 {
+			queue = append(queue, next)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		m.literals[state] = append(m.literals[state], m.literals[fail[state]]...)
+		for class := 0; class < m.width; class++ {
+			next := m.next[state*m.width+class]
+			if next == 0 {
+				m.next[state*m.width+class] = m.next[fail[state]*m.width+class]
+				continue
+			}
+			fail[next] = m.next[fail[state]*m.width+class]
+			queue = append(queue, next)
+		}
+	}
+	return m
+}
+
+// find sets found[i] for each literal i that the value contains
+func (m *literalMatcher) find(s string, found []bool) {
+	state := 0
+	for i := 0; i < len(s); i++ {
+		state = m.next[state*m.width+m.classes[s[i]]]
+		for _, literal := range m.literals[state] {
+			found[literal] = true
+		}
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"regexp/syntax"
+	"strings"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/errors"
@@ -25,6 +27,9 @@ import (
 	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 )
 
+// DefaultPatternAttribute is the attribute that records which of the patterns matched, if not configured
+const DefaultPatternAttribute = "regex.pattern"
+
 func init() {
 	operator.Register("regex_parser", func() operator.Builder { return NewRegexParserConfig("") })
 }
@@ -40,6 +45,14 @@ func NewRegexParserConfig(operatorID string) *RegexParserConfig {
 type RegexParserConfig struct {
 	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
 
+	Regex            string          `mapstructure:"regex"                       json:"regex"                       yaml:"regex"`
+	Patterns         []PatternConfig `mapstructure:"patterns,omitempty"          json:"patterns,omitempty"          yaml:"patterns,omitempty"`
+	PatternAttribute string          `mapstructure:"pattern_attribute,omitempty" json:"pattern_attribute,omitempty" yaml:"pattern_attribute,omitempty"`
+}
+
+// PatternConfig is a named regex, one of several tried in order
+type PatternConfig struct {
+	Name  string `mapstructure:"name"  json:"name"  yaml:"name"`
 	Regex string `mapstructure:"regex" json:"regex" yaml:"regex"`
 }
 
@@ -50,13 +63,62 @@ func (c RegexParserConfig) Build(context operator.BuildContext) ([]operator.Oper
 		return nil, err
 	}
 
-	if c.Regex == "" {
+	regexParser := &RegexParser{
+		ParserOperator: parserOperator,
+	}
+
+	switch {
+	case c.Regex != "" && len(c.Patterns) > 0:
+		return nil, fmt.Errorf("only one of 'regex' and 'patterns' can be set")
+	case c.Regex != "":
+		p, err := compilePattern("", c.Regex)
+		if err != nil {
+			return nil, err
+		}
+		regexParser.patterns = []pattern{p}
+	case len(c.Patterns) > 0:
+		names := make(map[string]bool, len(c.Patterns))
+		for i, pc := range c.Patterns {
+			if pc.Name == "" {
+				return nil, fmt.Errorf("missing required field 'name' of pattern %d", i)
+			}
+			if names[pc.Name] {
+				return nil, fmt.Errorf("duplicate pattern name '%s'", pc.Name)
+			}
+			names[pc.Name] = true
+
+			if pc.Regex == "" {
+				return nil, fmt.Errorf("missing required field 'regex' of pattern '%s'", pc.Name)
+			}
+			p, err := compilePattern(pc.Name, pc.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("pattern '%s': %s", pc.Name, err)
+			}
+			regexParser.patterns = append(regexParser.patterns, p)
+		}
+
+		regexParser.patternAttribute = c.PatternAttribute
+		if regexParser.patternAttribute == "" {
+			regexParser.patternAttribute = DefaultPatternAttribute
+		}
+
+		literals := make([]string, len(regexParser.patterns))
+		for i, p := range regexParser.patterns {
+			literals[i] = p.literal
+		}
+		regexParser.literals = newLiteralMatcher(literals)
+	default:
 		return nil, fmt.Errorf("missing required field 'regex'")
 	}
 
-	r, err := regexp.Compile(c.Regex)
+	return []operator.Operator{regexParser}, nil
+}
+
+// compilePattern compiles a regex, which must have named capture groups
+func compilePattern(name, regex string) (pattern, error) {
+	r, err := regexp.Compile(regex)
 	if err != nil {
-		return nil, fmt.Errorf("compiling regex: %s", err)
+		return pattern{}, fmt.Errorf("compiling regex: %s", err)
 	}
 
 	namedCaptureGroups := 0
@@ -66,54 +128,134 @@ func (c RegexParserConfig) Build(context operator.BuildContext) ([]operator.Oper
 		}
 	}
 	if namedCaptureGroups == 0 {
-		return nil, errors.NewError(
+		return pattern{}, errors.NewError(
 			"no named capture groups in regex pattern",
 			"use named capture groups like '^(?P<my_key>.*)$' to specify the key name for the parsed field",
 		)
 	}
 
-	regexParser := &RegexParser{
-		ParserOperator: parserOperator,
-		regexp:         r,
+	p := pattern{name: name, regexp: r}
+	if re, err := syntax.Parse(regex, syntax.Perl); err == nil {
+		p.literal = requiredLiteral(re.Simplify())
 	}
+	return p, nil
+}
 
-	return []operator.Operator{regexParser}, nil
+// requiredLiteral returns the longest literal that any match of a regex must contain,
+// or an empty string if there is none
+func requiredLiteral(re *syntax.Regexp) string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return ""
+		}
+		return string(re.Rune)
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiteral(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return requiredLiteral(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		longest := ""
+		for _, sub := range re.Sub {
+			if literal := requiredLiteral(sub); len(literal) > len(longest) {
+				longest = literal
+			}
+		}
+		return longest
+	}
+	return ""
 }
 
 // RegexParser is an operator that parses regex in an entry.
 type RegexParser struct {
 	helper.ParserOperator
-	regexp *regexp.Regexp
+	patterns         []pattern
+	patternAttribute string
+	// literals finds the literals of all patterns in one scan, when there are several patterns
+	literals *literalMatcher
+}
+
+// pattern is a compiled regex, along with a literal that its matches must contain. Values that
+// do not contain the literal are skipped without running the regex.
+type pattern struct {
+	name    string
+	regexp  *regexp.Regexp
+	literal string
 }
 
 // Process will parse an entry for regex.
 func (r *RegexParser) Process(ctx context.Context, entry *entry.Entry) error {
-	return r.ParserOperator.ProcessWith(ctx, entry, r.parse)
+	if r.patternAttribute == "" {
+		return r.ParserOperator.ProcessWith(ctx, entry, r.parse)
+	}
+
+	var matched string
+	parse := func(value interface{}) (interface{}, error) {
+		parsed, name, err := r.match(value)
+		matched = name
+		return parsed, err
+	}
+	return r.ParserOperator.ProcessWithCallback(ctx, entry, parse, r.recordPattern(&matched))
+}
+
+// recordPattern returns a callback that adds the name of the pattern that matched to an entry
+func (r *RegexParser) recordPattern(name *string) func(*entry.Entry) error {
+	return func(e *entry.Entry) error {
+		e.AddAttribute(r.patternAttribute, *name)
+		return nil
+	}
 }
 
 // parse will parse a value using the supplied regex.
 func (r *RegexParser) parse(value interface{}) (interface{}, error) {
-	var matches []string
-	switch m := value.(type) {
-	case string:
-		matches = r.regexp.FindStringSubmatch(m)
-		if matches == nil {
-			return nil, fmt.Errorf("regex pattern does not match")
-		}
-	default:
-		return nil, fmt.Errorf("type '%T' cannot be parsed as regex", value)
+	parsed, _, err := r.match(value)
+	return parsed, err
+}
+
+// match will parse a value using the first of the patterns that matches, and returns the name of the pattern.
+func (r *RegexParser) match(value interface{}) (map[string]interface{}, string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, "", fmt.Errorf("type '%T' cannot be parsed as regex", value)
 	}
 
-	parsedValues := map[string]interface{}{}
-	for i, subexp := range r.regexp.SubexpNames() {
-		if i == 0 {
-			// Skip whole match
+	var found []bool
+	if r.literals != nil {
+		found = make([]bool, len(r.patterns))
+		r.literals.find(s, found)
+	}
+
+	for i, p := range r.patterns {
+		if p.literal != "" {
+			if found != nil && !found[i] {
+				continue
+			}
+			if found == nil && !strings.Contains(s, p.literal) {
+				continue
+			}
+		}
+		matches := p.regexp.FindStringSubmatch(s)
+		if matches == nil {
 			continue
 		}
-		if subexp != "" {
-			parsedValues[subexp] = matches[i]
+
+		parsedValues := map[string]interface{}{}
+		for i, subexp := range p.regexp.SubexpNames() {
+			if i == 0 {
+				// Skip whole match
+				continue
+			}
+			if subexp != "" {
+				parsedValues[subexp] = matches[i]
+			}
 		}
+		return parsedValues, p.name, nil
 	}
 
-	return parsedValues, nil
+	if len(r.patterns) > 1 {
+		return nil, "", fmt.Errorf("none of the regex patterns match")
+	}
+	return nil, "", fmt.Errorf("regex pattern does not match")
 }
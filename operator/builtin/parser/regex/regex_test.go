@@ -16,7 +16,10 @@ package regex
 
 import (
 	"context"
+	"fmt"
+	"regexp/syntax"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
@@ -152,6 +155,234 @@ func TestBuildParserRegex(t *testing.T) {
 	})
 }
 
+func TestBuildParserPatterns(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(cfg *RegexParserConfig)
+		expectErr string
+	}{
+		{
+			"Valid",
+			func(cfg *RegexParserConfig) {
+				cfg.Patterns = []PatternConfig{{Name: "a", Regex: "^a=(?P<a>.*)$"}, {Name: "b", Regex: "^b=(?P<b>.*)$"}}
+			},
+			"",
+		},
+		{
+			"RegexAndPatterns",
+			func(cfg *RegexParserConfig) {
+				cfg.Regex = "^a=(?P<a>.*)$"
+				cfg.Patterns = []PatternConfig{{Name: "b", Regex: "^b=(?P<b>.*)$"}}
+			},
+			"only one of 'regex' and 'patterns' can be set",
+		},
+		{
+			"MissingName",
+			func(cfg *RegexParserConfig) {
+				cfg.Patterns = []PatternConfig{{Name: "a", Regex: "^a=(?P<a>.*)$"}, {Regex: "^b=(?P<b>.*)$"}}
+			},
+			"missing required field 'name' of pattern 1",
+		},
+		{
+			"DuplicateName",
+			func(cfg *RegexParserConfig) {
+				cfg.Patterns = []PatternConfig{{Name: "a", Regex: "^a=(?P<a>.*)$"}, {Name: "a", Regex: "^b=(?P<b>.*)$"}}
+			},
+			"duplicate pattern name 'a'",
+		},
+		{
+			"MissingRegex",
+			func(cfg *RegexParserConfig) {
+				cfg.Patterns = []PatternConfig{{Name: "a"}}
+			},
+			"missing required field 'regex' of pattern 'a'",
+		},
+		{
+			"InvalidRegex",
+			func(cfg *RegexParserConfig) {
+				cfg.Patterns = []PatternConfig{{Name: "a", Regex: "())()"}}
+			},
+			"pattern 'a': compiling regex",
+		},
+		{
+			"NoNamedGroups",
+			func(cfg *RegexParserConfig) {
+				cfg.Patterns = []PatternConfig{{Name: "a", Regex: "^a=(.*)$"}}
+			},
+			"no named capture groups",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRegexParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
+
+func TestParserPatterns(t *testing.T) {
+	patterns := []PatternConfig{
+		{Name: "access", Regex: `^(?P<ip>[\d.]+) (?P<method>GET|POST) (?P<path>\S+)$`},
+		{Name: "error", Regex: `^(?P<time>\S+) ERROR (?P<message>.*)$`},
+		{Name: "any_level", Regex: `^(?P<time>\S+) (?P<level>[A-Z]+) (?P<message>.*)$`},
+	}
+
+	cases := []struct {
+		name             string
+		patternAttribute string
+		input            string
+		expectBody       interface{}
+		expectAttributes map[string]interface{}
+	}{
+		{
+			"First",
+			"",
+			"10.0.0.1 GET /index.html",
+			map[string]interface{}{"ip": "10.0.0.1", "method": "GET", "path": "/index.html"},
+			map[string]interface{}{DefaultPatternAttribute: "access"},
+		},
+		{
+			"FirstMatchWins",
+			"",
+			"2022-01-01T00:00:00Z ERROR disk full",
+			map[string]interface{}{"time": "2022-01-01T00:00:00Z", "message": "disk full"},
+			map[string]interface{}{DefaultPatternAttribute: "error"},
+		},
+		{
+			"Last",
+			"",
+			"2022-01-01T00:00:00Z WARN disk almost full",
+			map[string]interface{}{"time": "2022-01-01T00:00:00Z", "level": "WARN", "message": "disk almost full"},
+			map[string]interface{}{DefaultPatternAttribute: "any_level"},
+		},
+		{
+			"PatternAttribute",
+			"log.format",
+			"10.0.0.1 POST /login",
+			map[string]interface{}{"ip": "10.0.0.1", "method": "POST", "path": "/login"},
+			map[string]interface{}{"log.format": "access"},
+		},
+		{
+			"NoMatch",
+			"",
+			"unstructured line",
+			"unstructured line",
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRegexParserConfig("test")
+			cfg.OutputIDs = []string{"fake"}
+			cfg.Patterns = patterns
+			cfg.PatternAttribute = tc.patternAttribute
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			fake := testutil.NewFakeOutput(t)
+			require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+			e := entry.New()
+			e.Body = tc.input
+			err = op.Process(context.Background(), e)
+			if tc.expectAttributes == nil {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "none of the regex patterns match")
+			} else {
+				require.NoError(t, err)
+			}
+
+			select {
+			case e := <-fake.Received:
+				require.Equal(t, tc.expectBody, e.Body)
+				require.Equal(t, tc.expectAttributes, e.Attributes)
+			case <-time.After(time.Second):
+				require.FailNow(t, "Timed out waiting for entry")
+			}
+		})
+	}
+}
+
+func TestRequiredLiteral(t *testing.T) {
+	cases := []struct {
+		regex    string
+		expected string
+	}{
+		{`^ERROR (?P<message>.*)$`, "ERROR "},
+		{`^(?P<time>\S+) level=(?P<level>\w+) msg=(?P<msg>.*)$`, " level="},
+		{`(?P<a>ab)+def`, "def"},
+		{`(?P<a>abcd){2}x`, "abcd"},
+		{`(?P<a>abc)?x`, "x"},
+		{`(?i)error (?P<message>.*)`, ""},
+		{`(?P<level>INFO|WARN)`, ""},
+		{`(?P<all>.*)`, ""},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.regex, func(t *testing.T) {
+			re, err := syntax.Parse(tc.regex, syntax.Perl)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, requiredLiteral(re.Simplify()))
+		})
+	}
+}
+
+func TestLiteralMatcher(t *testing.T) {
+	literals := []string{"he", "she", "his", "hers", "", "x", "she"}
+	cases := []struct {
+		input    string
+		expected []bool
+	}{
+		{"ushers", []bool{true, true, false, true, false, false, true}},
+		{"this", []bool{false, false, true, false, false, false, false}},
+		{"hhhx", []bool{false, false, false, false, false, true, false}},
+		{"", []bool{false, false, false, false, false, false, false}},
+	}
+
+	m := newLiteralMatcher(literals)
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			found := make([]bool, len(literals))
+			m.find(tc.input, found)
+			require.Equal(t, tc.expected, found)
+		})
+	}
+}
+
+func BenchmarkParserPatterns(b *testing.B) {
+	cfg := NewRegexParserConfig("bench")
+	for i := 0; i < 20; i++ {
+		cfg.Patterns = append(cfg.Patterns, PatternConfig{
+			Name:  fmt.Sprintf("pattern%d", i),
+			Regex: fmt.Sprintf(`^(?P<time>\S+) component%d: (?P<message>.*)$`, i),
+		})
+	}
+	ops, err := cfg.Build(testutil.NewBuildContext(b))
+	require.NoError(b, err)
+	parser := ops[0].(*RegexParser)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := parser.match("2022-01-01T00:00:00Z component19: the last pattern matches"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestRegexParserConfig(t *testing.T) {
 	expect := NewRegexParserConfig("test")
 	expect.Regex = "test123"
@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestXMLParserConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "attribute_prefix",
+			Expect: func() *XMLParserConfig {
+				cfg := defaultCfg()
+				cfg.AttributePrefix = "attr_"
+				return cfg
+			}(),
+		},
+		{
+			Name: "ignore_attributes",
+			Expect: func() *XMLParserConfig {
+				cfg := defaultCfg()
+				cfg.IgnoreAttributes = true
+				return cfg
+			}(),
+		},
+		{
+			Name: "force_array",
+			Expect: func() *XMLParserConfig {
+				cfg := defaultCfg()
+				cfg.ForceArray = true
+				return cfg
+			}(),
+		},
+		{
+			Name: "namespaces",
+			Expect: func() *XMLParserConfig {
+				cfg := defaultCfg()
+				cfg.Namespaces = PrefixNamespaces
+				return cfg
+			}(),
+		},
+		{
+			Name: "text_key",
+			Expect: func() *XMLParserConfig {
+				cfg := defaultCfg()
+				cfg.TextKey = "value"
+				cfg.ParseTo = entry.NewAttributeField()
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *XMLParserConfig {
+	return NewXMLParserConfig("xml_parser")
+}
@@ -0,0 +1,241 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/ianaindex"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// StripNamespaces removes the namespace prefixes of element and attribute names, and the namespace declarations
+	StripNamespaces = "strip"
+	// PrefixNamespaces keeps the namespace prefixes of element and attribute names, and the namespace declarations
+	PrefixNamespaces = "prefix"
+)
+
+func init() {
+	operator.Register("xml_parser", func() operator.Builder { return NewXMLParserConfig("") })
+}
+
+// NewXMLParserConfig creates a new XML parser config with default values
+func NewXMLParserConfig(operatorID string) *XMLParserConfig {
+	return &XMLParserConfig{
+		ParserConfig:    helper.NewParserConfig(operatorID, "xml_parser"),
+		AttributePrefix: "@",
+		Namespaces:      StripNamespaces,
+		TextKey:         "#text",
+	}
+}
+
+// XMLParserConfig is the configuration of an XML parser operator.
+type XMLParserConfig struct {
+	helper.ParserConfig `mapstructure:",squash" yaml:",inline"`
+
+	AttributePrefix  string `mapstructure:"attribute_prefix"  json:"attribute_prefix"  yaml:"attribute_prefix"`
+	IgnoreAttributes bool   `mapstructure:"ignore_attributes" json:"ignore_attributes" yaml:"ignore_attributes"`
+	ForceArray       bool   `mapstructure:"force_array"       json:"force_array"       yaml:"force_array"`
+	Namespaces       string `mapstructure:"namespaces"        json:"namespaces"        yaml:"namespaces"`
+	TextKey          string `mapstructure:"text_key"          json:"text_key"          yaml:"text_key"`
+}
+
+// Build will build an XML parser operator.
+func (c XMLParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Namespaces {
+	case StripNamespaces, PrefixNamespaces:
+	default:
+		return nil, fmt.Errorf("invalid value '%s' for field 'namespaces'", c.Namespaces)
+	}
+
+	if c.TextKey == "" {
+		return nil, fmt.Errorf("missing required field 'text_key'")
+	}
+	if !c.IgnoreAttributes && c.AttributePrefix != "" && strings.HasPrefix(c.TextKey, c.AttributePrefix) {
+		return nil, fmt.Errorf("'text_key' must not start with 'attribute_prefix'")
+	}
+
+	xmlParser := &XMLParser{
+		ParserOperator:   parserOperator,
+		attributePrefix:  c.AttributePrefix,
+		ignoreAttributes: c.IgnoreAttributes,
+		forceArray:       c.ForceArray,
+		namespaces:       c.Namespaces,
+		textKey:          c.TextKey,
+	}
+
+	return []operator.Operator{xmlParser}, nil
+}
+
+// XMLParser is an operator that parses XML in an entry.
+type XMLParser struct {
+	helper.ParserOperator
+	attributePrefix  string
+	ignoreAttributes bool
+	forceArray       bool
+	namespaces       string
+	textKey          string
+}
+
+// element is an element whose content is being parsed
+type element struct {
+	name   xml.Name
+	fields map[string]interface{}
+	text   strings.Builder
+}
+
+// Process will parse an entry for XML.
+func (x *XMLParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return x.ParserOperator.ProcessWith(ctx, entry, x.parse)
+}
+
+// parse will parse a value as XML. The result is a map with the name of the root element as its key.
+func (x *XMLParser) parse(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("type %T cannot be parsed as XML", value)
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(s))
+	decoder.CharsetReader = charsetReader
+
+	var parsedValue map[string]interface{}
+	var stack []*element
+	for {
+		// Namespace prefixes are kept by reading raw tokens, so elements are matched here
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse XML: %s", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if parsedValue != nil {
+				return nil, fmt.Errorf("parse XML: unexpected element <%s> after the root element", x.name(t.Name))
+			}
+			stack = append(stack, x.newElement(t))
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1].name != t.Name {
+				return nil, fmt.Errorf("parse XML: unexpected end element </%s>", x.name(t.Name))
+			}
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			name, value := x.name(e.name), x.value(e)
+			if len(stack) == 0 {
+				parsedValue = map[string]interface{}{name: value}
+				continue
+			}
+			x.addChild(stack[len(stack)-1], name, value)
+		case xml.CharData:
+			if len(stack) == 0 {
+				if strings.TrimSpace(string(t)) != "" {
+					return nil, fmt.Errorf("parse XML: unexpected text outside of the root element")
+				}
+				continue
+			}
+			stack[len(stack)-1].text.Write(t)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("parse XML: element <%s> is not closed", x.name(stack[len(stack)-1].name))
+	}
+	if parsedValue == nil {
+		return nil, fmt.Errorf("parse XML: missing root element")
+	}
+	return parsedValue, nil
+}
+
+// newElement creates an element with the attributes of its start element
+func (x *XMLParser) newElement(start xml.StartElement) *element {
+	e := &element{name: start.Name, fields: map[string]interface{}{}}
+	if x.ignoreAttributes {
+		return e
+	}
+
+	for _, attr := range start.Attr {
+		isDeclaration := attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns")
+		if isDeclaration && x.namespaces == StripNamespaces {
+			continue
+		}
+		e.fields[x.attributePrefix+x.name(attr.Name)] = attr.Value
+	}
+	return e
+}
+
+// name returns the name of an element or attribute, with its namespace prefix if they are kept
+func (x *XMLParser) name(name xml.Name) string {
+	if x.namespaces == PrefixNamespaces && name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// value returns the text of an element without attributes or children, and otherwise
+// a map of its attributes and children, with its text under the text key
+func (x *XMLParser) value(e *element) interface{} {
+	text := strings.TrimSpace(e.text.String())
+	if len(e.fields) == 0 {
+		return text
+	}
+	if text != "" {
+		e.fields[x.textKey] = text
+	}
+	return e.fields
+}
+
+// addChild adds the value of a child element to its parent. Repeated elements are collected
+// in an array, as is every element if arrays are forced.
+func (x *XMLParser) addChild(parent *element, name string, value interface{}) {
+	existing, ok := parent.fields[name]
+	switch {
+	case !ok && x.forceArray:
+		parent.fields[name] = []interface{}{value}
+	case !ok:
+		parent.fields[name] = value
+	default:
+		if values, ok := existing.([]interface{}); ok {
+			parent.fields[name] = append(values, value)
+			return
+		}
+		parent.fields[name] = []interface{}{existing, value}
+	}
+}
+
+// charsetReader decodes documents that declare an encoding other than UTF-8
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	encoding, err := ianaindex.IANA.Encoding(charset)
+	if err != nil || encoding == nil {
+		return nil, fmt.Errorf("unsupported encoding '%s'", charset)
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
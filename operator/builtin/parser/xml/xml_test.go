@@ -0,0 +1,271 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func newTestParser(t *testing.T, modify func(cfg *XMLParserConfig)) *XMLParser {
+	cfg := NewXMLParserConfig("test")
+	modify(cfg)
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return ops[0].(*XMLParser)
+}
+
+func TestXMLParserBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		modify    func(cfg *XMLParserConfig)
+		expectErr bool
+	}{
+		{"Default", func(cfg *XMLParserConfig) {}, false},
+		{"PrefixNamespaces", func(cfg *XMLParserConfig) { cfg.Namespaces = PrefixNamespaces }, false},
+		{"InvalidNamespaces", func(cfg *XMLParserConfig) { cfg.Namespaces = "keep" }, true},
+		{"MissingTextKey", func(cfg *XMLParserConfig) { cfg.TextKey = "" }, true},
+		{"TextKeyWithAttributePrefix", func(cfg *XMLParserConfig) { cfg.TextKey = "@text" }, true},
+		{"TextKeyWithIgnoredAttributes", func(cfg *XMLParserConfig) { cfg.TextKey = "@text"; cfg.IgnoreAttributes = true }, false},
+		{"EmptyAttributePrefix", func(cfg *XMLParserConfig) { cfg.AttributePrefix = "" }, false},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewXMLParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestXMLParserParse(t *testing.T) {
+	soapFault := `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">
+  <soap:Body>
+    <soap:Fault>
+      <soap:Code><soap:Value>soap:Sender</soap:Value></soap:Code>
+      <soap:Reason><soap:Text xml:lang="en">Invalid request</soap:Text></soap:Reason>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`
+
+	cases := []struct {
+		name     string
+		modify   func(cfg *XMLParserConfig)
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"Text",
+			func(cfg *XMLParserConfig) {},
+			`<message>hello</message>`,
+			map[string]interface{}{"message": "hello"},
+		},
+		{
+			"EmptyElement",
+			func(cfg *XMLParserConfig) {},
+			`<event><empty/><blank>  </blank></event>`,
+			map[string]interface{}{"event": map[string]interface{}{"empty": "", "blank": ""}},
+		},
+		{
+			"Attributes",
+			func(cfg *XMLParserConfig) {},
+			`<event id="1" level="error"><message>failed</message></event>`,
+			map[string]interface{}{
+				"event": map[string]interface{}{"@id": "1", "@level": "error", "message": "failed"},
+			},
+		},
+		{
+			"AttributePrefix",
+			func(cfg *XMLParserConfig) { cfg.AttributePrefix = "attr_" },
+			`<event id="1"/>`,
+			map[string]interface{}{"event": map[string]interface{}{"attr_id": "1"}},
+		},
+		{
+			"IgnoreAttributes",
+			func(cfg *XMLParserConfig) { cfg.IgnoreAttributes = true },
+			`<event id="1"><message level="error">failed</message></event>`,
+			map[string]interface{}{"event": map[string]interface{}{"message": "failed"}},
+		},
+		{
+			"TextWithAttributes",
+			func(cfg *XMLParserConfig) {},
+			`<message level="error">failed</message>`,
+			map[string]interface{}{"message": map[string]interface{}{"@level": "error", "#text": "failed"}},
+		},
+		{
+			"TextKey",
+			func(cfg *XMLParserConfig) { cfg.TextKey = "value" },
+			`<message level="error">failed</message>`,
+			map[string]interface{}{"message": map[string]interface{}{"@level": "error", "value": "failed"}},
+		},
+		{
+			"MixedContent",
+			func(cfg *XMLParserConfig) {},
+			`<p>Hello <b>world</b></p>`,
+			map[string]interface{}{"p": map[string]interface{}{"b": "world", "#text": "Hello"}},
+		},
+		{
+			"RepeatedElements",
+			func(cfg *XMLParserConfig) {},
+			`<frames><frame>a</frame><frame>b</frame><frame>c</frame><count>3</count></frames>`,
+			map[string]interface{}{
+				"frames": map[string]interface{}{"frame": []interface{}{"a", "b", "c"}, "count": "3"},
+			},
+		},
+		{
+			"ForceArray",
+			func(cfg *XMLParserConfig) { cfg.ForceArray = true },
+			`<frames><frame line="1">a</frame><frame>b</frame><count>2</count></frames>`,
+			map[string]interface{}{
+				"frames": map[string]interface{}{
+					"frame": []interface{}{map[string]interface{}{"@line": "1", "#text": "a"}, "b"},
+					"count": []interface{}{"2"},
+				},
+			},
+		},
+		{
+			"EntitiesAndCDATA",
+			func(cfg *XMLParserConfig) {},
+			`<query op="&lt;">a &amp; b<![CDATA[ <c> ]]></query>`,
+			map[string]interface{}{"query": map[string]interface{}{"@op": "<", "#text": "a & b <c>"}},
+		},
+		{
+			"StripNamespaces",
+			func(cfg *XMLParserConfig) {},
+			soapFault,
+			map[string]interface{}{
+				"Envelope": map[string]interface{}{
+					"Body": map[string]interface{}{
+						"Fault": map[string]interface{}{
+							"Code":   map[string]interface{}{"Value": "soap:Sender"},
+							"Reason": map[string]interface{}{"Text": map[string]interface{}{"@lang": "en", "#text": "Invalid request"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			"PrefixNamespaces",
+			func(cfg *XMLParserConfig) { cfg.Namespaces = PrefixNamespaces },
+			soapFault,
+			map[string]interface{}{
+				"soap:Envelope": map[string]interface{}{
+					"@xmlns:soap": "http://www.w3.org/2003/05/soap-envelope",
+					"soap:Body": map[string]interface{}{
+						"soap:Fault": map[string]interface{}{
+							"soap:Code":   map[string]interface{}{"soap:Value": "soap:Sender"},
+							"soap:Reason": map[string]interface{}{"soap:Text": map[string]interface{}{"@xml:lang": "en", "#text": "Invalid request"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			"DefaultNamespace",
+			func(cfg *XMLParserConfig) { cfg.Namespaces = PrefixNamespaces },
+			`<event xmlns="urn:events"><id>1</id></event>`,
+			map[string]interface{}{"event": map[string]interface{}{"@xmlns": "urn:events", "id": "1"}},
+		},
+		{
+			"Encoding",
+			func(cfg *XMLParserConfig) {},
+			"<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><name>Jos\xe9</name>",
+			map[string]interface{}{"name": "José"},
+		},
+		{
+			"CommentsAndDirectives",
+			func(cfg *XMLParserConfig) {},
+			"<!DOCTYPE note>\n<!-- before -->\n<note><!-- inside -->text</note>\n",
+			map[string]interface{}{"note": "text"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, tc.modify)
+			parsed, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestXMLParserParseFailure(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     interface{}
+		expectErr string
+	}{
+		{"Bytes", []byte("<a/>"), "type []uint8 cannot be parsed as XML"},
+		{"Empty", "", "missing root element"},
+		{"NotXML", "hello", "unexpected text outside of the root element"},
+		{"Unclosed", "<a><b></b>", "element <a> is not closed"},
+		{"Mismatched", "<a><b></a></b>", "unexpected end element </a>"},
+		{"MultipleRoots", "<a/><b/>", "unexpected element <b> after the root element"},
+		{"TrailingText", "<a/>text", "unexpected text outside of the root element"},
+		{"Malformed", "<a b=>", "parse XML"},
+		{"UnsupportedEncoding", `<?xml version="1.0" encoding="unknown"?><a/>`, "unsupported encoding 'unknown'"},
+	}
+
+	parser := newTestParser(t, func(cfg *XMLParserConfig) {})
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expectErr)
+		})
+	}
+}
+
+func TestXMLParserProcess(t *testing.T) {
+	cfg := NewXMLParserConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.ParseFrom = entry.NewBodyField("message")
+	cfg.ParseTo = entry.NewBodyField("parsed")
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Body = map[string]interface{}{"message": `<error code="500">Internal</error>`}
+	require.NoError(t, op.Process(context.Background(), e))
+
+	fake.ExpectBody(t, map[string]interface{}{
+		"parsed": map[string]interface{}{
+			"error": map[string]interface{}{"@code": "500", "#text": "Internal"},
+		},
+	})
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
@@ -120,7 +120,7 @@ func TestProcessAndBuild(t *testing.T) {
 			newTestEntry,
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{"new": "newVal"}
+				e.Attributes = map[string]interface{}{"new": "newVal"}
 				return e
 			},
 			false,
@@ -136,7 +136,7 @@ func TestProcessAndBuild(t *testing.T) {
 			newTestEntry,
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"new": "newVal"}
+				e.Resource = map[string]interface{}{"new": "newVal"}
 				return e
 			},
 			false,
@@ -152,7 +152,7 @@ func TestProcessAndBuild(t *testing.T) {
 			newTestEntry,
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"new": "val_suffix"}
+				e.Resource = map[string]interface{}{"new": "val_suffix"}
 				return e
 			},
 			false,
@@ -231,8 +231,34 @@ func TestProcessAndBuild(t *testing.T) {
 				return cfg
 			}(),
 			newTestEntry,
-			nil,
-			true,
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Resource = map[string]interface{}{
+					"new": 1,
+				}
+				return e
+			},
+			false,
+		},
+		{
+			"add_nested_attribute",
+			func() *AddOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewAttributeField("one", "two")
+				cfg.Value = true
+				return cfg
+			}(),
+			newTestEntry,
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Attributes = map[string]interface{}{
+					"one": map[string]interface{}{
+						"two": true,
+					},
+				}
+				return e
+			},
+			false,
 		},
 	}
 	for _, tc := range cases {
@@ -132,7 +132,7 @@ func TestBuildAndProcess(t *testing.T) {
 						"nestedkey": "nestedval",
 					},
 				}
-				e.Attributes = map[string]string{"key2": "val"}
+				e.Attributes = map[string]interface{}{"key2": "val"}
 				return e
 			},
 		},
@@ -147,7 +147,7 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{"key": "val"}
+				e.Attributes = map[string]interface{}{"key": "val"}
 				return e
 			},
 			func() *entry.Entry {
@@ -159,7 +159,7 @@ func TestBuildAndProcess(t *testing.T) {
 					},
 					"key2": "val",
 				}
-				e.Attributes = map[string]string{"key": "val"}
+				e.Attributes = map[string]interface{}{"key": "val"}
 				return e
 			},
 		},
@@ -174,13 +174,13 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{"key": "val"}
+				e.Attributes = map[string]interface{}{"key": "val"}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{"key": "val"}
-				e.Resource = map[string]string{"key2": "val"}
+				e.Attributes = map[string]interface{}{"key": "val"}
+				e.Resource = map[string]interface{}{"key2": "val"}
 				return e
 			},
 		},
@@ -204,28 +204,44 @@ func TestBuildAndProcess(t *testing.T) {
 			},
 		},
 		{
-			"invalid_copy_obj_to_resource",
-			true,
+			"copy_obj_to_resource",
+			false,
 			func() *CopyOperatorConfig {
 				cfg := defaultCfg()
 				cfg.From = entry.NewBodyField("nested")
-				cfg.To = entry.NewResourceField("invalid")
+				cfg.To = entry.NewResourceField("NewNested")
 				return cfg
 			}(),
 			newTestEntry,
-			nil,
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Resource = map[string]interface{}{
+					"NewNested": map[string]interface{}{
+						"nestedkey": "nestedval",
+					},
+				}
+				return e
+			},
 		},
 		{
-			"invalid_copy_obj_to_attributes",
-			true,
+			"copy_obj_to_attributes",
+			false,
 			func() *CopyOperatorConfig {
 				cfg := defaultCfg()
 				cfg.From = entry.NewBodyField("nested")
-				cfg.To = entry.NewAttributeField("invalid")
+				cfg.To = entry.NewAttributeField("NewNested")
 				return cfg
 			}(),
 			newTestEntry,
-			nil,
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Attributes = map[string]interface{}{
+					"NewNested": map[string]interface{}{
+						"nestedkey": "nestedval",
+					},
+				}
+				return e
+			},
 		},
 		{
 			"invalid_key",
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diskbuffer
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "max_segment_size",
+			Expect: func() *DiskBufferConfig {
+				cfg := defaultCfg()
+				cfg.MaxSegmentSize = 4 * 1024 * 1024
+				return cfg
+			}(),
+		},
+		{
+			Name: "max_retries",
+			Expect: func() *DiskBufferConfig {
+				cfg := defaultCfg()
+				cfg.MaxRetries = 10
+				return cfg
+			}(),
+		},
+		{
+			Name: "sync",
+			Expect: func() *DiskBufferConfig {
+				cfg := defaultCfg()
+				cfg.Sync = true
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *DiskBufferConfig {
+	cfg := NewDiskBufferConfig("disk_buffer")
+	cfg.Path = "/var/lib/stanza/buffer"
+	return cfg
+}
@@ -0,0 +1,469 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diskbuffer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jpillora/backoff"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+func init() {
+	operator.Register("disk_buffer", func() operator.Builder { return NewDiskBufferConfig("") })
+}
+
+const (
+	defaultMaxSegmentSize = 1024 * 1024
+	defaultMaxRetries     = 5
+	cursorKey             = "cursor"
+	deadLetterFile        = "dead_letter.jsonl"
+
+	// cursorSaveInterval is the number of entries delivered between saves of the cursor
+	cursorSaveInterval = 100
+
+	// syncInterval is how often the write-ahead log is synced when entries are not synced as they are written
+	syncInterval = time.Second
+)
+
+// NewDiskBufferConfig creates a new disk buffer config with default values
+func NewDiskBufferConfig(operatorID string) *DiskBufferConfig {
+	return &DiskBufferConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "disk_buffer"),
+		MaxSegmentSize:    defaultMaxSegmentSize,
+		MaxRetries:        defaultMaxRetries,
+	}
+}
+
+// DiskBufferConfig is the configuration of a disk buffer operator
+type DiskBufferConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+
+	Path           string          `mapstructure:"path"             json:"path"                       yaml:"path"`
+	MaxSegmentSize helper.ByteSize `mapstructure:"max_segment_size" json:"max_segment_size,omitempty" yaml:"max_segment_size,omitempty"`
+	MaxRetries     int             `mapstructure:"max_retries"      json:"max_retries,omitempty"      yaml:"max_retries,omitempty"`
+	Sync           bool            `mapstructure:"sync"             json:"sync,omitempty"             yaml:"sync,omitempty"`
+}
+
+// Build will build a disk buffer operator from the supplied configuration
+func (c DiskBufferConfig) Build(bc operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(bc)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Path == "" {
+		return nil, fmt.Errorf("missing required argument 'path'")
+	}
+
+	if c.MaxSegmentSize <= 0 {
+		return nil, fmt.Errorf("`max_segment_size` must be positive")
+	}
+
+	if c.MaxRetries < 0 {
+		return nil, fmt.Errorf("`max_retries` must not be negative")
+	}
+
+	diskBuffer := &DiskBuffer{
+		TransformerOperator: transformer,
+		path:                c.Path,
+		maxSegmentSize:      int64(c.MaxSegmentSize),
+		maxRetries:          c.MaxRetries,
+		sync:                c.Sync,
+		backoff: backoff.Backoff{
+			Min:    100 * time.Millisecond,
+			Max:    10 * time.Second,
+			Factor: 2,
+		},
+	}
+
+	return []operator.Operator{diskBuffer}, nil
+}
+
+// cursor is the position of the next entry to be delivered downstream
+type cursor struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+// DiskBuffer is an operator that persists entries to a write-ahead log on disk
+// before delivering them to its outputs.
+type DiskBuffer struct {
+	helper.TransformerOperator
+
+	path           string
+	maxSegmentSize int64
+	maxRetries     int
+	sync           bool
+	backoff        backoff.Backoff
+
+	wal       *wal
+	cursor    cursor
+	unsaved   int
+	persister operator.Persister
+
+	// pending holds the contexts of entries that will be acknowledged once the write-ahead log is synced
+	pendingMux sync.Mutex
+	pending    []context.Context
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+// Start will open the write-ahead log and begin delivering any entries that have not been acknowledged
+func (b *DiskBuffer) Start(persister operator.Persister) error {
+	w, err := openWAL(b.path, b.maxSegmentSize, b.sync)
+	if err != nil {
+		return fmt.Errorf("open disk buffer: %s", err)
+	}
+	b.wal = w
+	b.persister = persister
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+
+	if err := b.loadCursor(ctx); err != nil {
+		cancel()
+		_ = w.close()
+		return fmt.Errorf("load cursor: %s", err)
+	}
+
+	b.wg.Add(1)
+	go b.consume(ctx)
+
+	if !b.sync {
+		b.wg.Add(1)
+		go b.syncPeriodically(ctx)
+	}
+	return nil
+}
+
+// Stop will stop delivering entries and close the write-ahead log.
+// Entries that have not been delivered remain on disk until the next start.
+func (b *DiskBuffer) Stop() error {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.wg.Wait()
+	if b.wal != nil {
+		b.flushAcks()
+		if err := b.wal.close(); err != nil {
+			b.Errorw("Failed to close disk buffer", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Process will append an entry to the write-ahead log
+func (b *DiskBuffer) Process(ctx context.Context, entry *entry.Entry) error {
+	b.Telemetry.Received()
+	skip, err := b.Skip(ctx, entry)
+	if err != nil {
+		return b.HandleEntryError(ctx, entry, err)
+	}
+	if skip {
+		b.Write(ctx, entry)
+		return nil
+	}
+
+	record, err := json.Marshal(entry)
+	if err != nil {
+		return b.HandleEntryError(ctx, entry, fmt.Errorf("marshal entry: %s", err))
+	}
+
+	if err := b.wal.append(append(record, '\n')); err != nil {
+		return b.HandleEntryError(ctx, entry, err)
+	}
+
+	// The entry is durable once it is synced to the write-ahead log, so it can be acknowledged
+	// before it is delivered downstream
+	if b.sync {
+		helper.Ack(ctx)
+		return nil
+	}
+
+	b.pendingMux.Lock()
+	b.pending = append(b.pending, ctx)
+	b.pendingMux.Unlock()
+	return nil
+}
+
+// syncPeriodically acknowledges the entries appended to the write-ahead log each time it is synced
+func (b *DiskBuffer) syncPeriodically(ctx context.Context) {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.flushAcks()
+		}
+	}
+}
+
+// flushAcks syncs the write-ahead log and acknowledges every entry that was appended before the sync
+func (b *DiskBuffer) flushAcks() {
+	b.pendingMux.Lock()
+	pending := b.pending
+	b.pending = nil
+	b.pendingMux.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	err := b.wal.flush()
+	if err != nil {
+		b.Errorw("Failed to sync disk buffer", zap.Error(err))
+	}
+	for _, ctx := range pending {
+		helper.AckResult(ctx, err)
+	}
+}
+
+// consume delivers entries from the write-ahead log until the context is cancelled
+func (b *DiskBuffer) consume(ctx context.Context) {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		progressed, err := b.consumeSegment(ctx)
+		if err == nil {
+			// The cursor is saved in batches while delivering, so it is saved whenever the
+			// worker catches up or stops. This uses a new context so that it is saved on stop.
+			err = b.saveCursor(context.Background())
+		}
+		if err != nil {
+			b.Errorw("Failed to read from disk buffer", zap.Error(err))
+		}
+		if progressed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.wal.notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// consumeSegment delivers the entries in the segment at the cursor, and removes
+// the segment once it is complete and every entry in it has been delivered.
+func (b *DiskBuffer) consumeSegment(ctx context.Context) (bool, error) {
+	// If the segment is complete before reading, nothing can be appended to it while reading
+	complete := b.cursor.Segment < b.wal.currentSegment()
+
+	file, err := os.Open(b.wal.segmentPath(b.cursor.Segment))
+	if os.IsNotExist(err) && complete {
+		return true, b.nextSegment(ctx)
+	} else if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(b.cursor.Offset, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	progressed := false
+	reader := bufio.NewReader(file)
+	for {
+		select {
+		case <-ctx.Done():
+			return progressed, nil
+		default:
+		}
+
+		record, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A partial record at the end of a complete segment was left by an interrupted write
+			if complete {
+				// The segment must be closed before it can be removed on some platforms
+				_ = file.Close()
+				return true, b.nextSegment(ctx)
+			}
+			return progressed, nil
+		} else if err != nil {
+			return progressed, err
+		}
+
+		var e entry.Entry
+		if err := json.Unmarshal(record, &e); err != nil {
+			b.Errorw("Moving corrupt entry in disk buffer to dead letter file", zap.Error(err), "segment", b.cursor.Segment, "offset", b.cursor.Offset)
+			if err := b.deadLetter(record); err != nil {
+				b.Errorw("Failed to write dead letter file", zap.Error(err))
+			}
+		} else if !b.deliver(ctx, record, &e) {
+			return progressed, nil
+		}
+
+		b.cursor.Offset += int64(len(record))
+		b.unsaved++
+		progressed = true
+		if b.unsaved >= cursorSaveInterval {
+			if err := b.saveCursor(ctx); err != nil {
+				return progressed, err
+			}
+		}
+	}
+}
+
+// nextSegment removes the segment at the cursor and advances the cursor to the next segment
+func (b *DiskBuffer) nextSegment(ctx context.Context) error {
+	if err := b.wal.remove(b.cursor.Segment); err != nil {
+		return fmt.Errorf("remove segment: %s", err)
+	}
+
+	segments, err := b.wal.segments()
+	if err != nil {
+		return err
+	}
+
+	next := b.cursor.Segment + 1
+	for _, segment := range segments {
+		if segment > b.cursor.Segment {
+			next = segment
+			break
+		}
+	}
+
+	b.cursor = cursor{Segment: next}
+	b.unsaved++
+	return b.saveCursor(ctx)
+}
+
+// deliver writes an entry to the outputs, retrying with backoff while any output rejects it.
+// Once the configured number of retries is exhausted, the entry is moved to the dead letter file.
+// It returns false if the context was cancelled before the entry was delivered or moved.
+func (b *DiskBuffer) deliver(ctx context.Context, record []byte, e *entry.Entry) bool {
+	b.backoff.Reset()
+	for attempt := 0; ; attempt++ {
+		if b.write(ctx, e.Copy()) {
+			return true
+		}
+
+		if attempt >= b.maxRetries {
+			err := b.deadLetter(record)
+			if err == nil {
+				b.Errorw("Moved undeliverable entry to dead letter file", "attempts", attempt+1, "path", filepath.Join(b.path, deadLetterFile))
+				return true
+			}
+			// The entry is retried until it is either delivered or moved
+			b.Errorw("Failed to write dead letter file", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(b.backoff.Duration()):
+		}
+	}
+}
+
+// write writes an entry to the outputs, and returns false if an output rejected it while it was written.
+// Outputs report failures by acknowledgement, so a failure reported after write returns is not seen.
+func (b *DiskBuffer) write(ctx context.Context, e *entry.Entry) bool {
+	rejected := make(chan struct{}, 1)
+	ackCtx := helper.WithAcknowledgement(ctx, func(delivered bool) {
+		if !delivered {
+			rejected <- struct{}{}
+		}
+	})
+
+	b.Write(ackCtx, e)
+	select {
+	case <-rejected:
+		return false
+	default:
+		return true
+	}
+}
+
+// deadLetter appends a record to the dead letter file, and syncs it to stable storage
+func (b *DiskBuffer) deadLetter(record []byte) error {
+	file, err := os.OpenFile(filepath.Join(b.path, deadLetterFile), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(record); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// loadCursor loads the cursor from the persister, or points it at the oldest segment on disk
+func (b *DiskBuffer) loadCursor(ctx context.Context) error {
+	encoded, err := b.persister.Get(ctx, cursorKey)
+	if err != nil {
+		return err
+	}
+
+	if encoded != nil {
+		if err := json.Unmarshal(encoded, &b.cursor); err != nil {
+			return err
+		}
+		// The cursor is still valid unless the segments it refers to have been removed
+		if b.cursor.Segment <= b.wal.currentSegment() {
+			return nil
+		}
+		b.Warnw("Disk buffer cursor is ahead of the segments on disk, starting from the oldest segment", "segment", b.cursor.Segment)
+	}
+
+	segments, err := b.wal.segments()
+	if err != nil {
+		return err
+	}
+	b.cursor = cursor{Segment: segments[0]}
+	return nil
+}
+
+// saveCursor persists the cursor if it has moved since it was last saved
+func (b *DiskBuffer) saveCursor(ctx context.Context) error {
+	if b.unsaved == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(b.cursor)
+	if err != nil {
+		return err
+	}
+	if err := b.persister.Set(ctx, cursorKey, encoded); err != nil {
+		return err
+	}
+	b.unsaved = 0
+	return nil
+}
@@ -0,0 +1,282 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diskbuffer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// reject fails an entry in the same way that an output does when it cannot process it
+func reject(args mock.Arguments) {
+	helper.Nack(args.Get(0).(context.Context))
+}
+
+func newTestBuffer(t *testing.T, cfg *DiskBufferConfig, output operator.Operator) *DiskBuffer {
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*DiskBuffer)
+	op.OutputOperators = []operator.Operator{output}
+	return op
+}
+
+func TestBuild(t *testing.T) {
+	t.Run("MissingPath", func(t *testing.T) {
+		cfg := NewDiskBufferConfig("test")
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "path")
+	})
+
+	t.Run("InvalidSegmentSize", func(t *testing.T) {
+		cfg := NewDiskBufferConfig("test")
+		cfg.Path = testutil.NewTempDir(t)
+		cfg.MaxSegmentSize = 0
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+	})
+
+	t.Run("NegativeRetries", func(t *testing.T) {
+		cfg := NewDiskBufferConfig("test")
+		cfg.Path = testutil.NewTempDir(t)
+		cfg.MaxRetries = -1
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+	})
+}
+
+func TestDiskBufferDelivers(t *testing.T) {
+	cfg := NewDiskBufferConfig("test")
+	cfg.Path = testutil.NewTempDir(t)
+	fake := testutil.NewFakeOutput(t)
+	op := newTestBuffer(t, cfg, fake)
+
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	defer func() { require.NoError(t, op.Stop()) }()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: fmt.Sprintf("message %d", i)}))
+	}
+
+	for i := 0; i < 10; i++ {
+		fake.ExpectBody(t, fmt.Sprintf("message %d", i))
+	}
+}
+
+func TestDiskBufferRemovesDeliveredSegments(t *testing.T) {
+	cfg := NewDiskBufferConfig("test")
+	cfg.Path = testutil.NewTempDir(t)
+	cfg.MaxSegmentSize = 100
+	fake := testutil.NewFakeOutput(t)
+	op := newTestBuffer(t, cfg, fake)
+
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	defer func() { require.NoError(t, op.Stop()) }()
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: fmt.Sprintf("message %d", i)}))
+	}
+	for i := 0; i < 20; i++ {
+		fake.ExpectBody(t, fmt.Sprintf("message %d", i))
+	}
+
+	// Only the segment that is currently being written should remain
+	require.Eventually(t, func() bool {
+		files, err := ioutil.ReadDir(cfg.Path)
+		require.NoError(t, err)
+		return len(files) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestDiskBufferReplaysUndelivered(t *testing.T) {
+	cfg := NewDiskBufferConfig("test")
+	cfg.Path = testutil.NewTempDir(t)
+	persister := testutil.NewMockPersister("test")
+
+	// Hold back delivery so that entries remain on disk when the operator stops
+	blocked := &testutil.Operator{}
+	blocked.On("ID").Return("blocked")
+	blocked.On("Process", mock.Anything, mock.Anything).Run(reject).Return(fmt.Errorf("unavailable"))
+	cfg.MaxRetries = 1000
+	op := newTestBuffer(t, cfg, blocked)
+
+	require.NoError(t, op.Start(persister))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: fmt.Sprintf("message %d", i)}))
+	}
+	require.NoError(t, op.Stop())
+
+	fake := testutil.NewFakeOutput(t)
+	op = newTestBuffer(t, cfg, fake)
+	require.NoError(t, op.Start(persister))
+	defer func() { require.NoError(t, op.Stop()) }()
+
+	for i := 0; i < 3; i++ {
+		fake.ExpectBody(t, fmt.Sprintf("message %d", i))
+	}
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
+
+func TestDiskBufferDoesNotRedeliver(t *testing.T) {
+	cfg := NewDiskBufferConfig("test")
+	cfg.Path = testutil.NewTempDir(t)
+	persister := testutil.NewMockPersister("test")
+
+	fake := testutil.NewFakeOutput(t)
+	op := newTestBuffer(t, cfg, fake)
+	require.NoError(t, op.Start(persister))
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "delivered"}))
+	fake.ExpectBody(t, "delivered")
+	require.NoError(t, op.Stop())
+
+	fake = testutil.NewFakeOutput(t)
+	op = newTestBuffer(t, cfg, fake)
+	require.NoError(t, op.Start(persister))
+	defer func() { require.NoError(t, op.Stop()) }()
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+}
+
+func TestDiskBufferRetries(t *testing.T) {
+	cfg := NewDiskBufferConfig("test")
+	cfg.Path = testutil.NewTempDir(t)
+
+	received := make(chan *entry.Entry, 1)
+	flaky := &testutil.Operator{}
+	flaky.On("ID").Return("flaky")
+	flaky.On("Process", mock.Anything, mock.Anything).Run(reject).Return(fmt.Errorf("unavailable")).Once()
+	flaky.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		received <- args.Get(1).(*entry.Entry)
+	}).Return(nil)
+
+	op := newTestBuffer(t, cfg, flaky)
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	defer func() { require.NoError(t, op.Stop()) }()
+
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "retried"}))
+	select {
+	case e := <-received:
+		require.Equal(t, "retried", e.Body)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
+
+func TestDiskBufferDeadLetter(t *testing.T) {
+	cfg := NewDiskBufferConfig("test")
+	cfg.Path = testutil.NewTempDir(t)
+	cfg.MaxRetries = 1
+
+	received := make(chan *entry.Entry, 1)
+	flaky := &testutil.Operator{}
+	flaky.On("ID").Return("flaky")
+	flaky.On("Process", mock.Anything, mock.Anything).Run(reject).Return(fmt.Errorf("unavailable")).Twice()
+	flaky.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		received <- args.Get(1).(*entry.Entry)
+	}).Return(nil)
+
+	op := newTestBuffer(t, cfg, flaky)
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	defer func() { require.NoError(t, op.Stop()) }()
+
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "undeliverable"}))
+	require.NoError(t, op.Process(context.Background(), &entry.Entry{Body: "delivered"}))
+
+	select {
+	case e := <-received:
+		require.Equal(t, "delivered", e.Body)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(cfg.Path, deadLetterFile))
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "undeliverable")
+	require.NotContains(t, string(contents), `"delivered"`)
+}
+
+func TestDiskBufferAcksAfterSync(t *testing.T) {
+	cases := []struct {
+		name        string
+		sync        bool
+		ackedBefore bool
+	}{
+		{"Sync", true, true},
+		{"NoSync", false, false},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewDiskBufferConfig("test")
+			cfg.Path = testutil.NewTempDir(t)
+			cfg.Sync = tc.sync
+			op := newTestBuffer(t, cfg, testutil.NewFakeOutput(t))
+			require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+
+			acked := make(chan bool, 1)
+			ctx := helper.WithAcknowledgement(context.Background(), func(delivered bool) {
+				acked <- delivered
+			})
+			require.NoError(t, op.Process(ctx, &entry.Entry{Body: "buffered"}))
+
+			select {
+			case <-acked:
+				require.True(t, tc.ackedBefore, "acknowledged before the write-ahead log was synced")
+			default:
+				require.False(t, tc.ackedBefore, "not acknowledged after the entry was synced")
+				// Stopping syncs the write-ahead log
+				require.NoError(t, op.Stop())
+				require.True(t, <-acked)
+				return
+			}
+			require.NoError(t, op.Stop())
+		})
+	}
+}
+
+func TestDiskBufferSkipsPartialRecord(t *testing.T) {
+	dir := testutil.NewTempDir(t)
+	partial := filepath.Join(dir, fmt.Sprintf("%020d%s", 0, segmentExtension))
+	require.NoError(t, ioutil.WriteFile(partial, []byte(`{"body":"complete"}`+"\n"+`{"body":"trunc`), 0600))
+
+	cfg := NewDiskBufferConfig("test")
+	cfg.Path = dir
+	fake := testutil.NewFakeOutput(t)
+	op := newTestBuffer(t, cfg, fake)
+
+	require.NoError(t, op.Start(testutil.NewMockPersister("test")))
+	defer func() { require.NoError(t, op.Stop()) }()
+
+	fake.ExpectBody(t, "complete")
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(partial)
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+}
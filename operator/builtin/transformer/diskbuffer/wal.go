@@ -0,0 +1,201 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diskbuffer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const segmentExtension = ".wal"
+
+// wal is a write-ahead log made up of numbered segment files in a single directory.
+// Records are only ever appended to the newest segment.
+type wal struct {
+	dir            string
+	maxSegmentSize int64
+	sync           bool
+
+	mux     sync.Mutex
+	file    *os.File
+	segment uint64
+	size    int64
+
+	// notify receives a value whenever a record is appended
+	notify chan struct{}
+}
+
+// openWAL opens the write-ahead log in dir, creating the directory if it does not exist.
+func openWAL(dir string, maxSegmentSize int64, sync bool) (*wal, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("create directory: %s", err)
+	}
+
+	w := &wal{
+		dir:            dir,
+		maxSegmentSize: maxSegmentSize,
+		sync:           sync,
+		notify:         make(chan struct{}, 1),
+	}
+
+	segments, err := w.segments()
+	if err != nil {
+		return nil, err
+	}
+	// Never append to a segment left by a previous run, since it may end with a partial record
+	if len(segments) > 0 {
+		w.segment = segments[len(segments)-1] + 1
+	}
+
+	if err := w.openSegment(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// append writes a single record to the newest segment, rotating it first if it is full.
+func (w *wal) append(record []byte) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("write-ahead log is closed")
+	}
+
+	if w.size > 0 && w.size+int64(len(record)) > w.maxSegmentSize {
+		// Entries in the segment may be waiting for it to be synced before they are acknowledged
+		if !w.sync {
+			if err := w.file.Sync(); err != nil {
+				return fmt.Errorf("sync segment: %s", err)
+			}
+		}
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("close segment: %s", err)
+		}
+		w.segment++
+		if err := w.openSegment(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.Write(record)
+	w.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("write segment: %s", err)
+	}
+
+	if w.sync {
+		if err := w.file.Sync(); err != nil {
+			return fmt.Errorf("sync segment: %s", err)
+		}
+	}
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// flush syncs the segment currently being appended to.
+func (w *wal) flush() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if w.file == nil {
+		return fmt.Errorf("write-ahead log is closed")
+	}
+	return w.file.Sync()
+}
+
+// currentSegment returns the number of the segment currently being appended to.
+// Every segment with a lower number is complete.
+func (w *wal) currentSegment() uint64 {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return w.segment
+}
+
+// segments returns the numbers of all segments in the directory, in ascending order.
+func (w *wal) segments() ([]uint64, error) {
+	infos, err := ioutil.ReadDir(w.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %s", err)
+	}
+
+	segments := make([]uint64, 0, len(infos))
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, segmentExtension) {
+			continue
+		}
+		segment, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExtension), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+	return segments, nil
+}
+
+// remove deletes a complete segment.
+func (w *wal) remove(segment uint64) error {
+	err := os.Remove(w.segmentPath(segment))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// close closes the segment currently being appended to.
+func (w *wal) close() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *wal) segmentPath(segment uint64) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%020d%s", segment, segmentExtension))
+}
+
+// openSegment opens the current segment for appending. The caller must hold the lock.
+func (w *wal) openSegment() error {
+	file, err := os.OpenFile(w.segmentPath(w.segment), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("open segment: %s", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat segment: %s", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
@@ -86,18 +86,23 @@ type FilterOperator struct {
 
 // Process will drop incoming entries that match the filter expression
 func (f *FilterOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	f.Telemetry.Received()
 	env := helper.GetExprEnv(entry)
 	defer helper.PutExprEnv(env)
 
 	matches, err := vm.Run(f.expression, env)
 	if err != nil {
 		f.Errorf("Running expressing returned an error", zap.Error(err))
+		f.Telemetry.Failed()
+		helper.Ack(ctx)
 		return nil
 	}
 
 	filtered, ok := matches.(bool)
 	if !ok {
 		f.Errorf("Expression did not compile as a boolean")
+		f.Telemetry.Failed()
+		helper.Ack(ctx)
 		return nil
 	}
 
@@ -108,12 +113,18 @@ func (f *FilterOperator) Process(ctx context.Context, entry *entry.Entry) error
 
 	i, err := randInt(rand.Reader, upperBound)
 	if err != nil {
+		f.Telemetry.Failed()
+		helper.Ack(ctx)
 		return err
 	}
 
 	if i.Cmp(f.dropCutoff) >= 0 {
 		f.Write(ctx, entry)
+		return nil
 	}
 
+	// The entry was filtered out, so it does not need to be redelivered
+	f.Telemetry.Dropped()
+	helper.Ack(ctx)
 	return nil
 }
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8smetadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper/operatortest"
+)
+
+func TestK8sMetadataDecoratorConfig(t *testing.T) {
+	cases := []operatortest.ConfigUnmarshalTest{
+		{
+			Name:   "default",
+			Expect: defaultCfg(),
+		},
+		{
+			Name: "fields",
+			Expect: func() *K8sMetadataDecoratorConfig {
+				cfg := defaultCfg()
+				cfg.NamespaceField = entry.NewAttributeField("namespace")
+				cfg.PodNameField = entry.NewAttributeField("pod")
+				return cfg
+			}(),
+		},
+		{
+			Name: "node_name",
+			Expect: func() *K8sMetadataDecoratorConfig {
+				cfg := defaultCfg()
+				cfg.NodeName = "node-1"
+				cfg.ResyncPeriod = helper.NewDuration(time.Minute)
+				cfg.SyncTimeout = helper.NewDuration(10 * time.Second)
+				return cfg
+			}(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, defaultCfg())
+		})
+	}
+}
+
+func defaultCfg() *K8sMetadataDecoratorConfig {
+	return NewK8sMetadataDecoratorConfig("k8s_metadata_decorator")
+}
@@ -0,0 +1,372 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8smetadata
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/errors"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+)
+
+const (
+	// ownerCacheTTL is how long the owners of a replica set or job fetched from the API are kept
+	ownerCacheTTL = 10 * time.Minute
+	// ownerRequestTimeout limits the time spent fetching the owners of a replica set or job
+	ownerRequestTimeout = 5 * time.Second
+	// maxCachedOwners limits the number of replica sets and jobs whose owners are kept
+	maxCachedOwners = 4096
+)
+
+func init() {
+	operator.Register("k8s_metadata_decorator", func() operator.Builder { return NewK8sMetadataDecoratorConfig("") })
+}
+
+// NewK8sMetadataDecoratorConfig creates a new k8s metadata decorator config with default values
+func NewK8sMetadataDecoratorConfig(operatorID string) *K8sMetadataDecoratorConfig {
+	return &K8sMetadataDecoratorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "k8s_metadata_decorator"),
+		NamespaceField:    entry.NewResourceField("k8s.namespace.name"),
+		PodNameField:      entry.NewResourceField("k8s.pod.name"),
+		ResyncPeriod:      helper.NewDuration(10 * time.Minute),
+		SyncTimeout:       helper.NewDuration(time.Minute),
+	}
+}
+
+// K8sMetadataDecoratorConfig is the configuration of a k8s metadata decorator operator
+type K8sMetadataDecoratorConfig struct {
+	helper.TransformerConfig `mapstructure:",squash" yaml:",inline"`
+
+	NamespaceField entry.Field     `mapstructure:"namespace_field"     json:"namespace_field"     yaml:"namespace_field"`
+	PodNameField   entry.Field     `mapstructure:"pod_name_field"      json:"pod_name_field"      yaml:"pod_name_field"`
+	NodeName       string          `mapstructure:"node_name,omitempty" json:"node_name,omitempty" yaml:"node_name,omitempty"`
+	ResyncPeriod   helper.Duration `mapstructure:"resync_period"       json:"resync_period"       yaml:"resync_period"`
+	SyncTimeout    helper.Duration `mapstructure:"sync_timeout"        json:"sync_timeout"        yaml:"sync_timeout"`
+}
+
+// Build will build a k8s metadata decorator operator from the supplied configuration
+func (c K8sMetadataDecoratorConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build transformer")
+	}
+
+	if c.ResyncPeriod.Raw() < 0 {
+		return nil, fmt.Errorf("`resync_period` must not be negative")
+	}
+
+	if c.SyncTimeout.Raw() <= 0 {
+		return nil, fmt.Errorf("`sync_timeout` must be positive")
+	}
+
+	return []operator.Operator{&K8sMetadataDecorator{
+		TransformerOperator: transformerOperator,
+		namespaceField:      c.NamespaceField,
+		podNameField:        c.PodNameField,
+		nodeName:            c.NodeName,
+		resyncPeriod:        c.ResyncPeriod.Raw(),
+		syncTimeout:         c.SyncTimeout.Raw(),
+	}}, nil
+}
+
+// K8sMetadataDecorator is an operator that adds the metadata of the pod an entry came from to its resource
+type K8sMetadataDecorator struct {
+	helper.TransformerOperator
+	namespaceField entry.Field
+	podNameField   entry.Field
+	nodeName       string
+	resyncPeriod   time.Duration
+	syncTimeout    time.Duration
+
+	client     kubernetes.Interface
+	pods       corelisters.PodLister
+	namespaces corelisters.NamespaceLister
+	owners     ownerGetter
+	stop       chan struct{}
+}
+
+// Start will connect to the kubernetes API and start filling the informer cache
+func (d *K8sMetadataDecorator) Start(_ operator.Persister) error {
+	if d.client == nil {
+		// Currently, only running in the cluster is supported
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return errors.NewError(
+				"agent not in kubernetes cluster",
+				"the k8s_metadata_decorator operator only supports running in a pod inside a kubernetes cluster",
+			)
+		}
+
+		d.client, err = kubernetes.NewForConfig(config)
+		if err != nil {
+			return errors.Wrap(err, "build client")
+		}
+	}
+
+	factory := informers.NewSharedInformerFactory(d.client, d.resyncPeriod)
+
+	// Only the pods of a single node are cached when the node name is known
+	podFactory := factory
+	if d.nodeName != "" {
+		podFactory = informers.NewSharedInformerFactoryWithOptions(d.client, d.resyncPeriod,
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = "spec.nodeName=" + d.nodeName
+			}),
+		)
+	}
+
+	podInformer := podFactory.Core().V1().Pods()
+	namespaceInformer := factory.Core().V1().Namespaces()
+	d.pods = podInformer.Lister()
+	d.namespaces = namespaceInformer.Lister()
+	hasSynced := []cache.InformerSynced{
+		podInformer.Informer().HasSynced,
+		namespaceInformer.Informer().HasSynced,
+	}
+
+	// The replica sets and jobs of the whole cluster are only cached when the pods are. Otherwise,
+	// the owners of the replica sets and jobs of the node's pods are fetched when first needed.
+	if d.nodeName == "" {
+		replicaSetInformer := factory.Apps().V1().ReplicaSets()
+		jobInformer := factory.Batch().V1().Jobs()
+		d.owners = &listerOwners{
+			replicaSets: replicaSetInformer.Lister(),
+			jobs:        jobInformer.Lister(),
+		}
+		hasSynced = append(hasSynced, replicaSetInformer.Informer().HasSynced, jobInformer.Informer().HasSynced)
+	} else {
+		d.owners = &apiOwners{
+			client: d.client,
+			cache:  make(map[ownerKey]cachedOwners),
+		}
+	}
+
+	d.stop = make(chan struct{})
+	factory.Start(d.stop)
+	podFactory.Start(d.stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.syncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), hasSynced...) {
+		close(d.stop)
+		d.stop = nil
+		return fmt.Errorf("timed out waiting for the informer cache to sync")
+	}
+
+	return nil
+}
+
+// Stop will stop the informers
+func (d *K8sMetadataDecorator) Stop() error {
+	if d.stop != nil {
+		close(d.stop)
+		d.stop = nil
+	}
+	return nil
+}
+
+// Process will add the metadata of the pod to the entry
+func (d *K8sMetadataDecorator) Process(ctx context.Context, entry *entry.Entry) error {
+	return d.ProcessWith(ctx, entry, d.decorate)
+}
+
+// decorate looks up the pod of an entry and adds its metadata to the resource.
+// Entries of pods that are not in the cache are not modified.
+func (d *K8sMetadataDecorator) decorate(entry *entry.Entry) error {
+	var namespace, podName string
+	if err := entry.Read(d.namespaceField, &namespace); err != nil {
+		return errors.Wrap(err, "read namespace field")
+	}
+	if err := entry.Read(d.podNameField, &podName); err != nil {
+		return errors.Wrap(err, "read pod name field")
+	}
+
+	pod, err := d.pods.Pods(namespace).Get(podName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			d.Debugw("Pod not found in cache", "namespace", namespace, "pod", podName)
+			return nil
+		}
+		return errors.Wrap(err, "get pod")
+	}
+
+	entry.AddResourceKey("k8s.pod.uid", string(pod.UID))
+	if pod.Spec.NodeName != "" {
+		entry.AddResourceKey("k8s.node.name", pod.Spec.NodeName)
+	}
+	for k, v := range pod.Labels {
+		entry.AddResourceKey("k8s.pod.labels."+k, v)
+	}
+	for k, v := range pod.Annotations {
+		entry.AddResourceKey("k8s.pod.annotations."+k, v)
+	}
+	d.addOwners(entry, namespace, pod.OwnerReferences)
+
+	if ns, err := d.namespaces.Get(namespace); err == nil {
+		for k, v := range ns.Labels {
+			entry.AddResourceKey("k8s.namespace.labels."+k, v)
+		}
+	}
+
+	return nil
+}
+
+// addOwners adds the controllers of a pod to the resource, following a replica set to its
+// deployment, and a job to its cron job
+func (d *K8sMetadataDecorator) addOwners(entry *entry.Entry, namespace string, owners []metav1.OwnerReference) {
+	for _, owner := range owners {
+		if owner.Controller == nil || !*owner.Controller {
+			continue
+		}
+
+		switch owner.Kind {
+		case "ReplicaSet":
+			entry.AddResourceKey("k8s.replicaset.name", owner.Name)
+			entry.AddResourceKey("k8s.replicaset.uid", string(owner.UID))
+			if owners, ok := d.owners.getOwners(owner.Kind, namespace, owner.Name); ok {
+				d.addOwners(entry, namespace, owners)
+			}
+		case "Deployment":
+			entry.AddResourceKey("k8s.deployment.name", owner.Name)
+			entry.AddResourceKey("k8s.deployment.uid", string(owner.UID))
+		case "DaemonSet":
+			entry.AddResourceKey("k8s.daemonset.name", owner.Name)
+			entry.AddResourceKey("k8s.daemonset.uid", string(owner.UID))
+		case "StatefulSet":
+			entry.AddResourceKey("k8s.statefulset.name", owner.Name)
+			entry.AddResourceKey("k8s.statefulset.uid", string(owner.UID))
+		case "Job":
+			entry.AddResourceKey("k8s.job.name", owner.Name)
+			entry.AddResourceKey("k8s.job.uid", string(owner.UID))
+			if owners, ok := d.owners.getOwners(owner.Kind, namespace, owner.Name); ok {
+				d.addOwners(entry, namespace, owners)
+			}
+		case "CronJob":
+			entry.AddResourceKey("k8s.cronjob.name", owner.Name)
+			entry.AddResourceKey("k8s.cronjob.uid", string(owner.UID))
+		}
+	}
+}
+
+// ownerGetter looks up the owners of a replica set or job
+type ownerGetter interface {
+	getOwners(kind, namespace, name string) ([]metav1.OwnerReference, bool)
+}
+
+// listerOwners looks up the owners of replica sets and jobs in the informer cache
+type listerOwners struct {
+	replicaSets appslisters.ReplicaSetLister
+	jobs        batchlisters.JobLister
+}
+
+func (l *listerOwners) getOwners(kind, namespace, name string) ([]metav1.OwnerReference, bool) {
+	switch kind {
+	case "ReplicaSet":
+		if rs, err := l.replicaSets.ReplicaSets(namespace).Get(name); err == nil {
+			return rs.OwnerReferences, true
+		}
+	case "Job":
+		if job, err := l.jobs.Jobs(namespace).Get(name); err == nil {
+			return job.OwnerReferences, true
+		}
+	}
+	return nil, false
+}
+
+// apiOwners fetches the owners of replica sets and jobs from the kubernetes API,
+// and keeps them for a while, since the owners of an object rarely change
+type apiOwners struct {
+	client kubernetes.Interface
+
+	sync.Mutex
+	cache map[ownerKey]cachedOwners
+}
+
+type ownerKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+type cachedOwners struct {
+	owners  []metav1.OwnerReference
+	found   bool
+	expires time.Time
+}
+
+func (a *apiOwners) getOwners(kind, namespace, name string) ([]metav1.OwnerReference, bool) {
+	key := ownerKey{kind: kind, namespace: namespace, name: name}
+	a.Lock()
+	cached, ok := a.cache[key]
+	a.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.owners, cached.found
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ownerRequestTimeout)
+	defer cancel()
+
+	var meta metav1.Object
+	var err error
+	switch kind {
+	case "ReplicaSet":
+		meta, err = a.client.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Job":
+		meta, err = a.client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	default:
+		return nil, false
+	}
+
+	// Other errors are not cached, so the request is retried for the next entry
+	cached = cachedOwners{expires: time.Now().Add(ownerCacheTTL)}
+	switch {
+	case err == nil:
+		cached.owners = meta.GetOwnerReferences()
+		cached.found = true
+	case !k8serrors.IsNotFound(err):
+		return nil, false
+	}
+
+	a.Lock()
+	defer a.Unlock()
+	if len(a.cache) >= maxCachedOwners {
+		now := time.Now()
+		for k, v := range a.cache {
+			if now.After(v.expires) {
+				delete(a.cache, k)
+			}
+		}
+		if len(a.cache) >= maxCachedOwners {
+			a.cache = make(map[ownerKey]cachedOwners)
+		}
+	}
+	a.cache[key] = cached
+	return cached.owners, cached.found
+}
@@ -0,0 +1,291 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8smetadata
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func controller(kind, name, uid string) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, UID: types.UID(uid), Controller: &isController}}
+}
+
+func newTestDecorator(t *testing.T, objects ...runtime.Object) (*K8sMetadataDecorator, *testutil.FakeOutput) {
+	return newTestDecoratorWithConfig(t, nil, objects...)
+}
+
+func newTestDecoratorWithConfig(t *testing.T, mod func(*K8sMetadataDecoratorConfig), objects ...runtime.Object) (*K8sMetadataDecorator, *testutil.FakeOutput) {
+	cfg := NewK8sMetadataDecoratorConfig("test")
+	cfg.OutputIDs = []string{"fake"}
+	if mod != nil {
+		mod(cfg)
+	}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	decorator := ops[0].(*K8sMetadataDecorator)
+	decorator.client = fake.NewSimpleClientset(objects...)
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, decorator.SetOutputs([]operator.Operator{fake}))
+	require.NoError(t, decorator.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, decorator.Stop()) })
+	return decorator, fake
+}
+
+func newPodEntry(namespace, pod string) *entry.Entry {
+	e := entry.New()
+	e.Body = "message"
+	e.AddResourceKey("k8s.namespace.name", namespace)
+	e.AddResourceKey("k8s.pod.name", pod)
+	return e
+}
+
+func TestK8sMetadataDecoratorBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		mod       func(*K8sMetadataDecoratorConfig)
+		expectErr bool
+	}{
+		{"Default", nil, false},
+		{"NoResync", func(cfg *K8sMetadataDecoratorConfig) { cfg.ResyncPeriod = helper.NewDuration(0) }, false},
+		{"NegativeResync", func(cfg *K8sMetadataDecoratorConfig) { cfg.ResyncPeriod = helper.NewDuration(-1) }, true},
+		{"ZeroSyncTimeout", func(cfg *K8sMetadataDecoratorConfig) { cfg.SyncTimeout = helper.NewDuration(0) }, true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewK8sMetadataDecoratorConfig("test")
+			if tc.mod != nil {
+				tc.mod(cfg)
+			}
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestK8sMetadataDecoratorDeployment(t *testing.T) {
+	decorator, fake := newTestDecorator(t,
+		&apiv1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"team": "payments"}},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "api-6d4cf56db6",
+				Namespace:       "default",
+				UID:             "rs-uid",
+				OwnerReferences: controller("Deployment", "api", "deployment-uid"),
+			},
+		},
+		&apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "api-6d4cf56db6-x7k2p",
+				Namespace:       "default",
+				UID:             "pod-uid",
+				Labels:          map[string]string{"app": "api"},
+				Annotations:     map[string]string{"version": "1.2.3"},
+				OwnerReferences: controller("ReplicaSet", "api-6d4cf56db6", "rs-uid"),
+			},
+			Spec: apiv1.PodSpec{NodeName: "node-1"},
+		},
+	)
+
+	require.NoError(t, decorator.Process(context.Background(), newPodEntry("default", "api-6d4cf56db6-x7k2p")))
+	e := <-fake.Received
+	require.Equal(t, map[string]interface{}{
+		"k8s.namespace.name":          "default",
+		"k8s.pod.name":                "api-6d4cf56db6-x7k2p",
+		"k8s.pod.uid":                 "pod-uid",
+		"k8s.node.name":               "node-1",
+		"k8s.pod.labels.app":          "api",
+		"k8s.pod.annotations.version": "1.2.3",
+		"k8s.replicaset.name":         "api-6d4cf56db6",
+		"k8s.replicaset.uid":          "rs-uid",
+		"k8s.deployment.name":         "api",
+		"k8s.deployment.uid":          "deployment-uid",
+		"k8s.namespace.labels.team":   "payments",
+	}, e.Resource)
+}
+
+func TestK8sMetadataDecoratorOwners(t *testing.T) {
+	cases := []struct {
+		name     string
+		objects  []runtime.Object
+		owners   []metav1.OwnerReference
+		expected map[string]interface{}
+	}{
+		{
+			name:   "DaemonSet",
+			owners: controller("DaemonSet", "agent", "ds-uid"),
+			expected: map[string]interface{}{
+				"k8s.daemonset.name": "agent",
+				"k8s.daemonset.uid":  "ds-uid",
+			},
+		},
+		{
+			name:   "StatefulSet",
+			owners: controller("StatefulSet", "db", "sts-uid"),
+			expected: map[string]interface{}{
+				"k8s.statefulset.name": "db",
+				"k8s.statefulset.uid":  "sts-uid",
+			},
+		},
+		{
+			name: "CronJob",
+			objects: []runtime.Object{
+				&batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "backup-27345600",
+						Namespace:       "default",
+						OwnerReferences: controller("CronJob", "backup", "cronjob-uid"),
+					},
+				},
+			},
+			owners: controller("Job", "backup-27345600", "job-uid"),
+			expected: map[string]interface{}{
+				"k8s.job.name":     "backup-27345600",
+				"k8s.job.uid":      "job-uid",
+				"k8s.cronjob.name": "backup",
+				"k8s.cronjob.uid":  "cronjob-uid",
+			},
+		},
+		{
+			name:     "NotController",
+			owners:   []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent", UID: "ds-uid"}},
+			expected: map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &apiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "pod",
+					Namespace:       "default",
+					UID:             "pod-uid",
+					OwnerReferences: tc.owners,
+				},
+			}
+			decorator, fake := newTestDecorator(t, append(tc.objects, pod)...)
+
+			require.NoError(t, decorator.Process(context.Background(), newPodEntry("default", "pod")))
+			e := <-fake.Received
+			tc.expected["k8s.namespace.name"] = "default"
+			tc.expected["k8s.pod.name"] = "pod"
+			tc.expected["k8s.pod.uid"] = "pod-uid"
+			require.Equal(t, tc.expected, e.Resource)
+		})
+	}
+}
+
+func TestK8sMetadataDecoratorPodNotFound(t *testing.T) {
+	decorator, fake := newTestDecorator(t)
+
+	e := newPodEntry("default", "missing")
+	require.NoError(t, decorator.Process(context.Background(), e))
+	require.Equal(t, newPodEntry("default", "missing").Resource, (<-fake.Received).Resource)
+}
+
+func TestK8sMetadataDecoratorMissingField(t *testing.T) {
+	decorator, fake := newTestDecorator(t)
+
+	e := entry.New()
+	e.Body = "message"
+	e.AddResourceKey("k8s.namespace.name", "default")
+	require.Error(t, decorator.Process(context.Background(), e))
+	fake.ExpectBody(t, "message")
+}
+
+func TestK8sMetadataDecoratorNodeFetchesOwners(t *testing.T) {
+	decorator, output := newTestDecoratorWithConfig(t,
+		func(cfg *K8sMetadataDecoratorConfig) { cfg.NodeName = "node-1" },
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "api-6d4cf56db6",
+				Namespace:       "default",
+				OwnerReferences: controller("Deployment", "api", "deployment-uid"),
+			},
+		},
+		&apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "api-6d4cf56db6-x7k2p",
+				Namespace:       "default",
+				UID:             "pod-uid",
+				OwnerReferences: controller("ReplicaSet", "api-6d4cf56db6", "rs-uid"),
+			},
+			Spec: apiv1.PodSpec{NodeName: "node-1"},
+		},
+	)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, decorator.Process(context.Background(), newPodEntry("default", "api-6d4cf56db6-x7k2p")))
+		e := <-output.Received
+		require.Equal(t, "api", e.Resource["k8s.deployment.name"])
+		require.Equal(t, "deployment-uid", e.Resource["k8s.deployment.uid"])
+	}
+
+	// The replica sets are not cached for the whole cluster, and each is fetched once
+	var gets int
+	for _, action := range decorator.client.(*fake.Clientset).Actions() {
+		if action.GetResource().Resource != "replicasets" {
+			continue
+		}
+		require.Equal(t, "get", action.GetVerb())
+		gets++
+	}
+	require.Equal(t, 1, gets)
+}
+
+func TestK8sMetadataDecoratorSyncTimeout(t *testing.T) {
+	cfg := NewK8sMetadataDecoratorConfig("test")
+	cfg.SyncTimeout = helper.NewDuration(100 * time.Millisecond)
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	decorator := ops[0].(*K8sMetadataDecorator)
+
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("forbidden")
+	})
+	decorator.client = client
+
+	require.Error(t, decorator.Start(testutil.NewMockPersister("test")))
+	require.NoError(t, decorator.Stop())
+}
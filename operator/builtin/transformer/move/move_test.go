@@ -86,7 +86,7 @@ func TestProcessAndBuild(t *testing.T) {
 						"nestedkey": "nestedval",
 					},
 				}
-				e.Attributes = map[string]string{"new": "val"}
+				e.Attributes = map[string]interface{}{"new": "val"}
 				return e
 			},
 		},
@@ -101,7 +101,7 @@ func TestProcessAndBuild(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{"new": "val"}
+				e.Attributes = map[string]interface{}{"new": "val"}
 				return e
 			},
 			func() *entry.Entry {
@@ -113,7 +113,7 @@ func TestProcessAndBuild(t *testing.T) {
 						"nestedkey": "nestedval",
 					},
 				}
-				e.Attributes = map[string]string{}
+				e.Attributes = map[string]interface{}{}
 				return e
 			},
 		},
@@ -128,13 +128,13 @@ func TestProcessAndBuild(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{"new": "val"}
+				e.Attributes = map[string]interface{}{"new": "val"}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"new": "val"}
-				e.Attributes = map[string]string{}
+				e.Resource = map[string]interface{}{"new": "val"}
+				e.Attributes = map[string]interface{}{}
 				return e
 			},
 		},
@@ -149,13 +149,13 @@ func TestProcessAndBuild(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{"dotted.field.name": "val"}
+				e.Attributes = map[string]interface{}{"dotted.field.name": "val"}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"new": "val"}
-				e.Attributes = map[string]string{}
+				e.Resource = map[string]interface{}{"new": "val"}
+				e.Attributes = map[string]interface{}{}
 				return e
 			},
 		},
@@ -170,13 +170,13 @@ func TestProcessAndBuild(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{"dotted.field.name": "val"}
+				e.Attributes = map[string]interface{}{"dotted.field.name": "val"}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"dotted.field.name": "val"}
-				e.Attributes = map[string]string{}
+				e.Resource = map[string]interface{}{"dotted.field.name": "val"}
+				e.Attributes = map[string]interface{}{}
 				return e
 			},
 		},
@@ -191,13 +191,13 @@ func TestProcessAndBuild(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{"new": "val"}
+				e.Attributes = map[string]interface{}{"new": "val"}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"dotted.field.name": "val"}
-				e.Attributes = map[string]string{}
+				e.Resource = map[string]interface{}{"dotted.field.name": "val"}
+				e.Attributes = map[string]interface{}{}
 				return e
 			},
 		},
@@ -212,13 +212,13 @@ func TestProcessAndBuild(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"new": "val"}
+				e.Resource = map[string]interface{}{"new": "val"}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{}
-				e.Attributes = map[string]string{"new": "val"}
+				e.Resource = map[string]interface{}{}
+				e.Attributes = map[string]interface{}{"new": "val"}
 				return e
 			},
 		},
@@ -334,7 +334,7 @@ func TestProcessAndBuild(t *testing.T) {
 		},
 		{
 			"MoveNestToResource",
-			true,
+			false,
 			func() *MoveOperatorConfig {
 				cfg := defaultCfg()
 				cfg.From = entry.NewBodyField("nested")
@@ -342,20 +342,41 @@ func TestProcessAndBuild(t *testing.T) {
 				return cfg
 			}(),
 			newTestEntry,
-			nil,
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key": "val",
+				}
+				e.Resource = map[string]interface{}{
+					"NewNested": map[string]interface{}{
+						"nestedkey": "nestedval",
+					},
+				}
+				return e
+			},
 		},
 		{
 			"MoveNestToAttribute",
-			true,
+			false,
 			func() *MoveOperatorConfig {
 				cfg := defaultCfg()
 				cfg.From = entry.NewBodyField("nested")
 				cfg.To = entry.NewAttributeField("NewNested")
-
 				return cfg
 			}(),
 			newTestEntry,
-			nil,
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Body = map[string]interface{}{
+					"key": "val",
+				}
+				e.Attributes = map[string]interface{}{
+					"NewNested": map[string]interface{}{
+						"nestedkey": "nestedval",
+					},
+				}
+				return e
+			},
 		},
 		{
 			"ReplaceBodyObj",
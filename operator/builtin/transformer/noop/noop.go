@@ -59,6 +59,7 @@ type NoopOperator struct {
 
 // Process will forward the entry to the next output without any alterations.
 func (p *NoopOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	p.Telemetry.Received()
 	p.Write(ctx, entry)
 	return nil
 }
@@ -113,6 +113,7 @@ func (c *RecombineOperatorConfig) Build(bc operator.BuildContext) ([]operator.Op
 		maxSources:          c.MaxSources,
 		overwriteWithOldest: overwriteWithOldest,
 		batchMap:            make(map[string][]*entry.Entry),
+		ctxMap:              make(map[string][]context.Context),
 		combineField:        c.CombineField,
 		combineWith:         c.CombineWith,
 		forceFlushTimeout:   c.ForceFlushTimeout,
@@ -142,6 +143,9 @@ type RecombineOperator struct {
 
 	sync.Mutex
 	batchMap map[string][]*entry.Entry
+	// ctxMap holds the context each batched entry was written with, so that
+	// acknowledging the combined entry acknowledges each of the originals
+	ctxMap map[string][]context.Context
 }
 
 func (r *RecombineOperator) Start(_ operator.Persister) error {
@@ -180,9 +184,7 @@ func (r *RecombineOperator) Stop() error {
 	r.Lock()
 	defer r.Unlock()
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
-	r.flushUncombined(ctx)
+	r.flushUncombined()
 
 	close(r.chClose)
 
@@ -192,6 +194,8 @@ func (r *RecombineOperator) Stop() error {
 const DefaultSourceIdentifier = "DefaultSourceIdentifier"
 
 func (r *RecombineOperator) Process(ctx context.Context, e *entry.Entry) error {
+	r.Telemetry.Received()
+
 	// Lock the recombine operator because process can't run concurrently
 	r.Lock()
 	defer r.Unlock()
@@ -259,17 +263,19 @@ func (r *RecombineOperator) matchIndicatesLast() bool {
 }
 
 // addToBatch adds the current entry to the current batch of entries that will be combined
-func (r *RecombineOperator) addToBatch(_ context.Context, e *entry.Entry, source string) {
+func (r *RecombineOperator) addToBatch(ctx context.Context, e *entry.Entry, source string) {
 	if _, ok := r.batchMap[source]; !ok {
 		r.batchMap[source] = []*entry.Entry{e}
+		r.ctxMap[source] = []context.Context{ctx}
 		if len(r.batchMap) >= r.maxSources {
 			r.Error("Batched source exceeds max source size. Flushing all batched logs. Consider increasing max_sources parameter")
-			r.flushUncombined(context.Background())
+			r.flushUncombined()
 		}
 		return
 	}
 
 	r.batchMap[source] = append(r.batchMap[source], e)
+	r.ctxMap[source] = append(r.ctxMap[source], ctx)
 	if len(r.batchMap[source]) >= r.maxBatchSize {
 		if err := r.flushSource(source); err != nil {
 			r.Errorf("there was error flushing combined logs %s", err)
@@ -280,13 +286,14 @@ func (r *RecombineOperator) addToBatch(_ context.Context, e *entry.Entry, source
 // flushUncombined flushes all the logs in the batch individually to the
 // next output in the pipeline. This is only used when there is an error
 // or at shutdown to avoid dropping the logs.
-func (r *RecombineOperator) flushUncombined(ctx context.Context) {
+func (r *RecombineOperator) flushUncombined() {
 	for source := range r.batchMap {
-		for _, entry := range r.batchMap[source] {
-			r.Write(ctx, entry)
+		for i, entry := range r.batchMap[source] {
+			r.Write(r.ctxMap[source][i], entry)
 		}
 	}
 	r.batchMap = make(map[string][]*entry.Entry)
+	r.ctxMap = make(map[string][]context.Context)
 	r.ticker.Reset(r.forceFlushTimeout)
 }
 
@@ -331,8 +338,9 @@ func (r *RecombineOperator) flushSource(source string) error {
 		return err
 	}
 
-	r.Write(context.Background(), base)
+	r.Write(helper.MergeAcknowledgements(context.Background(), r.ctxMap[source]), base)
 
 	delete(r.batchMap, source)
+	delete(r.ctxMap, source)
 	return nil
 }
@@ -37,7 +37,7 @@ func TestRecombineOperator(t *testing.T) {
 		return e
 	}
 
-	entryWithBodyAttr := func(ts time.Time, body interface{}, Attr map[string]string) *entry.Entry {
+	entryWithBodyAttr := func(ts time.Time, body interface{}, Attr map[string]interface{}) *entry.Entry {
 		e := entryWithBody(ts, body)
 		for k, v := range Attr {
 			e.AddAttribute(k, v)
@@ -159,14 +159,14 @@ func TestRecombineOperator(t *testing.T) {
 				return cfg
 			}(),
 			[]*entry.Entry{
-				entryWithBodyAttr(t1, "file1", map[string]string{"file.path": "file1"}),
-				entryWithBodyAttr(t1, "file2", map[string]string{"file.path": "file2"}),
-				entryWithBodyAttr(t2, "end", map[string]string{"file.path": "file1"}),
-				entryWithBodyAttr(t2, "end", map[string]string{"file.path": "file2"}),
+				entryWithBodyAttr(t1, "file1", map[string]interface{}{"file.path": "file1"}),
+				entryWithBodyAttr(t1, "file2", map[string]interface{}{"file.path": "file2"}),
+				entryWithBodyAttr(t2, "end", map[string]interface{}{"file.path": "file1"}),
+				entryWithBodyAttr(t2, "end", map[string]interface{}{"file.path": "file2"}),
 			},
 			[]*entry.Entry{
-				entryWithBodyAttr(t1, "file1\nend", map[string]string{"file.path": "file1"}),
-				entryWithBodyAttr(t1, "file2\nend", map[string]string{"file.path": "file2"}),
+				entryWithBodyAttr(t1, "file1\nend", map[string]interface{}{"file.path": "file1"}),
+				entryWithBodyAttr(t1, "file2\nend", map[string]interface{}{"file.path": "file2"}),
 			},
 		},
 		{
@@ -180,14 +180,14 @@ func TestRecombineOperator(t *testing.T) {
 				return cfg
 			}(),
 			[]*entry.Entry{
-				entryWithBodyAttr(t1, "file1", map[string]string{"custom_source": "file1"}),
-				entryWithBodyAttr(t1, "file2", map[string]string{"custom_source": "file2"}),
-				entryWithBodyAttr(t2, "end", map[string]string{"custom_source": "file1"}),
-				entryWithBodyAttr(t2, "end", map[string]string{"custom_source": "file2"}),
+				entryWithBodyAttr(t1, "file1", map[string]interface{}{"custom_source": "file1"}),
+				entryWithBodyAttr(t1, "file2", map[string]interface{}{"custom_source": "file2"}),
+				entryWithBodyAttr(t2, "end", map[string]interface{}{"custom_source": "file1"}),
+				entryWithBodyAttr(t2, "end", map[string]interface{}{"custom_source": "file2"}),
 			},
 			[]*entry.Entry{
-				entryWithBodyAttr(t1, "file1\nend", map[string]string{"custom_source": "file1"}),
-				entryWithBodyAttr(t1, "file2\nend", map[string]string{"custom_source": "file2"}),
+				entryWithBodyAttr(t1, "file1\nend", map[string]interface{}{"custom_source": "file1"}),
+				entryWithBodyAttr(t1, "file2\nend", map[string]interface{}{"custom_source": "file2"}),
 			},
 		},
 		{
@@ -201,12 +201,12 @@ func TestRecombineOperator(t *testing.T) {
 				return cfg
 			}(),
 			[]*entry.Entry{
-				entryWithBodyAttr(t1, "file1", map[string]string{"file.path": "file1"}),
-				entryWithBodyAttr(t2, "end", map[string]string{"file.path": "file1"}),
+				entryWithBodyAttr(t1, "file1", map[string]interface{}{"file.path": "file1"}),
+				entryWithBodyAttr(t2, "end", map[string]interface{}{"file.path": "file1"}),
 			},
 			[]*entry.Entry{
-				entryWithBodyAttr(t1, "file1", map[string]string{"file.path": "file1"}),
-				entryWithBodyAttr(t2, "end", map[string]string{"file.path": "file1"}),
+				entryWithBodyAttr(t1, "file1", map[string]interface{}{"file.path": "file1"}),
+				entryWithBodyAttr(t2, "end", map[string]interface{}{"file.path": "file1"}),
 			},
 		},
 		{
@@ -220,16 +220,16 @@ func TestRecombineOperator(t *testing.T) {
 				return cfg
 			}(),
 			[]*entry.Entry{
-				entryWithBodyAttr(t1, "file1_event1", map[string]string{"file.path": "file1"}),
-				entryWithBodyAttr(t1, "file2_event1", map[string]string{"file.path": "file2"}),
-				entryWithBodyAttr(t2, "end", map[string]string{"file.path": "file1"}),
-				entryWithBodyAttr(t2, "file2_event2", map[string]string{"file.path": "file2"}),
-				entryWithBodyAttr(t2, "end", map[string]string{"file.path": "file2"}),
+				entryWithBodyAttr(t1, "file1_event1", map[string]interface{}{"file.path": "file1"}),
+				entryWithBodyAttr(t1, "file2_event1", map[string]interface{}{"file.path": "file2"}),
+				entryWithBodyAttr(t2, "end", map[string]interface{}{"file.path": "file1"}),
+				entryWithBodyAttr(t2, "file2_event2", map[string]interface{}{"file.path": "file2"}),
+				entryWithBodyAttr(t2, "end", map[string]interface{}{"file.path": "file2"}),
 			},
 			[]*entry.Entry{
-				entryWithBodyAttr(t1, "file1_event1\nend", map[string]string{"file.path": "file1"}),
-				entryWithBodyAttr(t1, "file2_event1\nfile2_event2", map[string]string{"file.path": "file2"}),
-				entryWithBodyAttr(t2, "end", map[string]string{"file.path": "file2"}),
+				entryWithBodyAttr(t1, "file1_event1\nend", map[string]interface{}{"file.path": "file1"}),
+				entryWithBodyAttr(t1, "file2_event1\nfile2_event2", map[string]interface{}{"file.path": "file2"}),
+				entryWithBodyAttr(t2, "end", map[string]interface{}{"file.path": "file2"}),
 			},
 		},
 	}
@@ -326,7 +326,7 @@ func BenchmarkRecombine(b *testing.B) {
 		require.NoError(b, recombine.Process(ctx, e))
 		require.NoError(b, recombine.Process(ctx, e))
 		require.NoError(b, recombine.Process(ctx, e))
-		recombine.flushUncombined(ctx)
+		recombine.flushUncombined()
 	}
 }
 
@@ -170,14 +170,14 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{
+				e.Attributes = map[string]interface{}{
 					"key": "val",
 				}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{
+				e.Attributes = map[string]interface{}{
 					"key": "val",
 				}
 				return e
@@ -194,7 +194,7 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{
+				e.Attributes = map[string]interface{}{
 					"key1": "val",
 					"key2": "val",
 					"key3": "val",
@@ -203,7 +203,7 @@ func TestBuildAndProcess(t *testing.T) {
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Attributes = map[string]string{
+				e.Attributes = map[string]interface{}{
 					"key1": "val",
 					"key2": "val",
 				}
@@ -220,14 +220,14 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key": "val",
 				}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key": "val",
 				}
 				return e
@@ -244,7 +244,7 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "val",
 					"key2": "val",
 					"key3": "val",
@@ -253,7 +253,7 @@ func TestBuildAndProcess(t *testing.T) {
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "val",
 					"key2": "val",
 				}
@@ -272,11 +272,11 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "val",
 					"key2": "val",
 				}
-				e.Attributes = map[string]string{
+				e.Attributes = map[string]interface{}{
 					"key3": "val",
 					"key4": "val",
 				}
@@ -284,10 +284,10 @@ func TestBuildAndProcess(t *testing.T) {
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "val",
 				}
-				e.Attributes = map[string]string{
+				e.Attributes = map[string]interface{}{
 					"key3": "val",
 				}
 				e.Body = map[string]interface{}{
@@ -17,6 +17,7 @@ package router
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/antonmedv/expr"
 	"github.com/antonmedv/expr/vm"
@@ -102,6 +103,9 @@ func (c RouterOperatorConfig) BuildsMultipleOps() bool { return false }
 type RouterOperator struct {
 	helper.BasicOperator
 	routes []*RouterOperatorRoute
+
+	// mux guards the outputs of the routes, which are replaced while the router runs when a pipeline is reloaded
+	mux sync.RWMutex
 }
 
 // RouterOperatorRoute is a route on a router operator
@@ -119,6 +123,7 @@ func (p *RouterOperator) CanProcess() bool {
 
 // Process will route incoming entries based on matching expressions
 func (p *RouterOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	p.Telemetry.Received()
 	env := helper.GetExprEnv(entry)
 	defer helper.PutExprEnv(env)
 
@@ -133,16 +138,33 @@ func (p *RouterOperator) Process(ctx context.Context, entry *entry.Entry) error
 		if matches.(bool) {
 			if err := route.Attribute(entry); err != nil {
 				p.Errorf("Failed to label entry: %s", err)
+				p.Telemetry.Failed()
+				helper.Nack(ctx)
 				return err
 			}
 
-			for _, output := range route.OutputOperators {
+			p.mux.RLock()
+			outputs := route.OutputOperators
+			p.mux.RUnlock()
+
+			switch n := len(outputs); {
+			case n == 0:
+				helper.Ack(ctx)
+			case n > 1:
+				helper.ExpectAcks(ctx, n-1)
+			}
+
+			p.Telemetry.Emitted()
+			for _, output := range outputs {
 				_ = output.Process(ctx, entry)
 			}
-			break
+			return nil
 		}
 	}
 
+	// The entry did not match any route, so it does not need to be redelivered
+	p.Telemetry.Dropped()
+	helper.Ack(ctx)
 	return nil
 }
 
@@ -153,6 +175,8 @@ func (p *RouterOperator) CanOutput() bool {
 
 // Outputs will return all connected operators.
 func (p *RouterOperator) Outputs() []operator.Operator {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
 	outputs := make([]operator.Operator, 0, len(p.routes))
 	for _, route := range p.routes {
 		outputs = append(outputs, route.OutputOperators...)
@@ -171,14 +195,20 @@ func (p *RouterOperator) GetOutputIDs() []string {
 
 // SetOutputs will set the outputs of the router operator.
 func (p *RouterOperator) SetOutputs(operators []operator.Operator) error {
+	routeOutputs := make([][]operator.Operator, 0, len(p.routes))
 	for _, route := range p.routes {
 		outputOperators, err := p.findOperators(operators, route.OutputIDs)
 		if err != nil {
 			return fmt.Errorf("failed to set outputs on route: %s", err)
 		}
-		route.OutputOperators = outputOperators
+		routeOutputs = append(routeOutputs, outputOperators)
 	}
 
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	for i, route := range p.routes {
+		route.OutputOperators = routeOutputs[i]
+	}
 	return nil
 }
 
@@ -0,0 +1,206 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type acknowledgementKey struct{}
+
+// acknowledgement tracks the delivery of an entry, and of any copies of it that were
+// made when it was written to more than one output.
+type acknowledgement struct {
+	pending int64
+	failed  int32
+	done    func(delivered bool)
+}
+
+// WithAcknowledgement returns a context for writing a single entry. Once the entry, and every
+// copy of it, has been acknowledged by an output or intentionally discarded, done is called.
+// The entry is considered delivered only if no copy of it failed.
+func WithAcknowledgement(ctx context.Context, done func(delivered bool)) context.Context {
+	return context.WithValue(ctx, acknowledgementKey{}, &acknowledgement{pending: 1, done: done})
+}
+
+// MergeAcknowledgements returns a context for writing an entry that was created from the entries
+// written with each of sources. Acknowledging the new entry acknowledges all of the originals.
+func MergeAcknowledgements(ctx context.Context, sources []context.Context) context.Context {
+	tracked := make([]context.Context, 0, len(sources))
+	for _, source := range sources {
+		if _, ok := source.Value(acknowledgementKey{}).(*acknowledgement); ok {
+			tracked = append(tracked, source)
+		}
+	}
+
+	if len(tracked) == 0 {
+		return ctx
+	}
+
+	return WithAcknowledgement(ctx, func(delivered bool) {
+		for _, source := range tracked {
+			if delivered {
+				Ack(source)
+			} else {
+				Nack(source)
+			}
+		}
+	})
+}
+
+// Ack marks the entry written with ctx as delivered.
+// It does nothing if delivery of the entry is not being tracked.
+func Ack(ctx context.Context) {
+	resolve(ctx, true)
+}
+
+// Nack marks the entry written with ctx as not delivered.
+// It does nothing if delivery of the entry is not being tracked.
+func Nack(ctx context.Context) {
+	resolve(ctx, false)
+}
+
+// AckResult acknowledges the entry written with ctx according to the error returned when processing it.
+func AckResult(ctx context.Context, err error) {
+	resolve(ctx, err == nil)
+}
+
+// ExpectAcks records that n additional copies of the entry written with ctx must be acknowledged.
+func ExpectAcks(ctx context.Context, n int) {
+	if ack, ok := ctx.Value(acknowledgementKey{}).(*acknowledgement); ok {
+		atomic.AddInt64(&ack.pending, int64(n))
+	}
+}
+
+func resolve(ctx context.Context, delivered bool) {
+	ack, ok := ctx.Value(acknowledgementKey{}).(*acknowledgement)
+	if !ok {
+		return
+	}
+
+	if !delivered {
+		atomic.StoreInt32(&ack.failed, 1)
+	}
+
+	if atomic.AddInt64(&ack.pending, -1) == 0 {
+		ack.done(atomic.LoadInt32(&ack.failed) == 0)
+	}
+}
+
+// CheckpointTracker commits checkpoints, such as file offsets or cursors, in the order that
+// entries were written, once each entry and all of the entries before it have been delivered.
+//
+// Once an entry fails to be delivered, the tracker stalls. An input that sees the tracker stalled
+// calls Rewind, and reads again from the returned checkpoint, so that the failed entry and the
+// entries after it are delivered again.
+type CheckpointTracker struct {
+	mux       sync.Mutex
+	pending   []*pendingCheckpoint
+	committed interface{}
+	stalled   bool
+	// generation is incremented on each rewind, so that acknowledgements of forgotten entries are ignored
+	generation uint64
+	onCommit   func(checkpoint interface{})
+}
+
+type pendingCheckpoint struct {
+	checkpoint interface{}
+	delivered  bool
+	generation uint64
+}
+
+// NewCheckpointTracker creates a tracker starting from the initial checkpoint.
+// If onCommit is not nil, it is called with each newly committed checkpoint.
+func NewCheckpointTracker(initial interface{}, onCommit func(checkpoint interface{})) *CheckpointTracker {
+	return &CheckpointTracker{
+		committed: initial,
+		onCommit:  onCommit,
+	}
+}
+
+// Track returns a context for writing an entry. The checkpoint is committed once
+// the entry and every entry tracked before it have been delivered.
+func (t *CheckpointTracker) Track(ctx context.Context, checkpoint interface{}) context.Context {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	// Once an entry has failed, no later checkpoint can be committed
+	if t.stalled {
+		return ctx
+	}
+
+	p := &pendingCheckpoint{checkpoint: checkpoint, generation: t.generation}
+	t.pending = append(t.pending, p)
+	return WithAcknowledgement(ctx, func(delivered bool) {
+		t.resolve(p, delivered)
+	})
+}
+
+// Committed returns the most recently committed checkpoint.
+func (t *CheckpointTracker) Committed() interface{} {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.committed
+}
+
+// Stalled returns true if an entry failed to be delivered, so no further checkpoints
+// will be committed until the tracker is rewound.
+func (t *CheckpointTracker) Stalled() bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.stalled
+}
+
+// Rewind forgets the entries tracked since the last committed checkpoint, and returns that
+// checkpoint, from which the entries must be read again. Acknowledgements of the forgotten
+// entries are ignored.
+func (t *CheckpointTracker) Rewind() interface{} {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.stalled = false
+	t.pending = nil
+	t.generation++
+	return t.committed
+}
+
+func (t *CheckpointTracker) resolve(p *pendingCheckpoint, delivered bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.stalled || p.generation != t.generation {
+		return
+	}
+
+	if !delivered {
+		t.stalled = true
+		t.pending = nil
+		return
+	}
+
+	p.delivered = true
+	committed := false
+	for len(t.pending) > 0 && t.pending[0].delivered {
+		t.committed = t.pending[0].checkpoint
+		t.pending = t.pending[1:]
+		committed = true
+	}
+
+	if committed && t.onCommit != nil {
+		t.onCommit(t.committed)
+	}
+}
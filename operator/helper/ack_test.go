@@ -0,0 +1,200 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestAcknowledgement(t *testing.T) {
+	t.Run("Ack", func(t *testing.T) {
+		var result []bool
+		ctx := WithAcknowledgement(context.Background(), func(delivered bool) { result = append(result, delivered) })
+		Ack(ctx)
+		require.Equal(t, []bool{true}, result)
+	})
+
+	t.Run("Nack", func(t *testing.T) {
+		var result []bool
+		ctx := WithAcknowledgement(context.Background(), func(delivered bool) { result = append(result, delivered) })
+		Nack(ctx)
+		require.Equal(t, []bool{false}, result)
+	})
+
+	t.Run("AckResult", func(t *testing.T) {
+		var result []bool
+		ctx := WithAcknowledgement(context.Background(), func(delivered bool) { result = append(result, delivered) })
+		AckResult(ctx, fmt.Errorf("failed"))
+		require.Equal(t, []bool{false}, result)
+	})
+
+	t.Run("Untracked", func(t *testing.T) {
+		require.NotPanics(t, func() {
+			Ack(context.Background())
+			Nack(context.Background())
+		})
+	})
+
+	t.Run("ExpectAcks", func(t *testing.T) {
+		var result []bool
+		ctx := WithAcknowledgement(context.Background(), func(delivered bool) { result = append(result, delivered) })
+		ExpectAcks(ctx, 2)
+		Ack(ctx)
+		Nack(ctx)
+		require.Empty(t, result)
+		Ack(ctx)
+		require.Equal(t, []bool{false}, result)
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		var result []bool
+		done := func(delivered bool) { result = append(result, delivered) }
+		sources := []context.Context{
+			WithAcknowledgement(context.Background(), done),
+			context.Background(),
+			WithAcknowledgement(context.Background(), done),
+		}
+		Ack(MergeAcknowledgements(context.Background(), sources))
+		require.Equal(t, []bool{true, true}, result)
+	})
+
+	t.Run("MergeUntracked", func(t *testing.T) {
+		ctx := context.Background()
+		require.Equal(t, ctx, MergeAcknowledgements(ctx, []context.Context{context.Background()}))
+	})
+}
+
+func TestCheckpointTracker(t *testing.T) {
+	t.Run("InOrder", func(t *testing.T) {
+		var commits []interface{}
+		tracker := NewCheckpointTracker(0, func(c interface{}) { commits = append(commits, c) })
+		first := tracker.Track(context.Background(), 1)
+		second := tracker.Track(context.Background(), 2)
+
+		Ack(first)
+		require.Equal(t, 1, tracker.Committed())
+		Ack(second)
+		require.Equal(t, 2, tracker.Committed())
+		require.Equal(t, []interface{}{1, 2}, commits)
+	})
+
+	t.Run("OutOfOrder", func(t *testing.T) {
+		var commits []interface{}
+		tracker := NewCheckpointTracker(0, func(c interface{}) { commits = append(commits, c) })
+		first := tracker.Track(context.Background(), 1)
+		second := tracker.Track(context.Background(), 2)
+		third := tracker.Track(context.Background(), 3)
+
+		Ack(third)
+		Ack(second)
+		require.Equal(t, 0, tracker.Committed())
+		Ack(first)
+		require.Equal(t, 3, tracker.Committed())
+		require.Equal(t, []interface{}{3}, commits)
+	})
+
+	t.Run("Stalled", func(t *testing.T) {
+		tracker := NewCheckpointTracker(0, nil)
+		first := tracker.Track(context.Background(), 1)
+		second := tracker.Track(context.Background(), 2)
+
+		Ack(first)
+		Nack(second)
+		require.True(t, tracker.Stalled())
+
+		third := tracker.Track(context.Background(), 3)
+		Ack(third)
+		require.Equal(t, 1, tracker.Committed())
+	})
+	t.Run("Rewind", func(t *testing.T) {
+		var commits []interface{}
+		tracker := NewCheckpointTracker(0, func(c interface{}) { commits = append(commits, c) })
+		first := tracker.Track(context.Background(), 1)
+		second := tracker.Track(context.Background(), 2)
+		third := tracker.Track(context.Background(), 3)
+
+		Ack(first)
+		Nack(second)
+		require.True(t, tracker.Stalled())
+		require.Equal(t, 1, tracker.Rewind())
+		require.False(t, tracker.Stalled())
+
+		// Acknowledgements of the entries written before the rewind are ignored
+		Ack(third)
+		require.Equal(t, 1, tracker.Committed())
+
+		// The entries written again after the rewind are committed once delivered
+		Ack(tracker.Track(context.Background(), 2))
+		Ack(tracker.Track(context.Background(), 3))
+		require.Equal(t, 3, tracker.Committed())
+		require.Equal(t, []interface{}{1, 2, 3}, commits)
+	})
+}
+
+func TestWriterOperatorAcknowledgement(t *testing.T) {
+	newWriter := func(outputs ...operator.Operator) WriterOperator {
+		return WriterOperator{
+			BasicOperator:   BasicOperator{OperatorID: "test"},
+			OutputOperators: outputs,
+		}
+	}
+
+	newOutput := func(ack bool) *testutil.Operator {
+		output := &testutil.Operator{}
+		output.On("ID").Return("output")
+		output.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			if ack {
+				Ack(ctx)
+			} else {
+				Nack(ctx)
+			}
+		}).Return(nil)
+		return output
+	}
+
+	t.Run("NoOutputs", func(t *testing.T) {
+		var result []bool
+		ctx := WithAcknowledgement(context.Background(), func(delivered bool) { result = append(result, delivered) })
+		writer := newWriter()
+		writer.Write(ctx, entry.New())
+		require.Equal(t, []bool{true}, result)
+	})
+
+	t.Run("AllOutputsAck", func(t *testing.T) {
+		var result []bool
+		ctx := WithAcknowledgement(context.Background(), func(delivered bool) { result = append(result, delivered) })
+		writer := newWriter(newOutput(true), newOutput(true))
+		writer.Write(ctx, entry.New())
+		require.Equal(t, []bool{true}, result)
+	})
+
+	t.Run("OneOutputFails", func(t *testing.T) {
+		var result []bool
+		ctx := WithAcknowledgement(context.Background(), func(delivered bool) { result = append(result, delivered) })
+		writer := newWriter(newOutput(true), newOutput(false))
+		writer.Write(ctx, entry.New())
+		require.Equal(t, []bool{false}, result)
+	})
+}
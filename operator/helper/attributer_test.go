@@ -45,7 +45,7 @@ func TestAttributer(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Attributes = map[string]string{
+				e.Attributes = map[string]interface{}{
 					"label1": "value1",
 				}
 				return e
@@ -63,7 +63,7 @@ func TestAttributer(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Attributes = map[string]string{
+				e.Attributes = map[string]interface{}{
 					"label1": "startend",
 				}
 				return e
@@ -81,7 +81,7 @@ func TestAttributer(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Attributes = map[string]string{
+				e.Attributes = map[string]interface{}{
 					"label1": "foo",
 				}
 				return e
@@ -33,9 +33,14 @@ func TestExprString(t *testing.T) {
 		e.Body = map[string]interface{}{
 			"test": "value",
 		}
-		e.Resource = map[string]string{
+		e.Resource = map[string]interface{}{
 			"id": "value",
 		}
+		e.Attributes = map[string]interface{}{
+			"http": map[string]interface{}{
+				"status": 200,
+			},
+		}
 		return e
 	}
 
@@ -99,6 +104,10 @@ func TestExprString(t *testing.T) {
 			"EXPR( $resource.id )",
 			"value",
 		},
+		{
+			"EXPR( $attributes.http.status >= 200 ? 'ok' : 'error' )",
+			"ok",
+		},
 	}
 
 	for i, tc := range cases {
@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+)
+
+const (
+	// MetricEntriesReceived counts the entries an operator has received from other operators.
+	MetricEntriesReceived = "stanza_operator_entries_received_total"
+	// MetricEntriesEmitted counts the entries an operator has written to its outputs.
+	MetricEntriesEmitted = "stanza_operator_entries_emitted_total"
+	// MetricEntriesDropped counts the entries an operator has discarded.
+	MetricEntriesDropped = "stanza_operator_entries_dropped_total"
+	// MetricErrors counts the errors an operator has encountered while processing entries.
+	MetricErrors = "stanza_operator_errors_total"
+	// MetricProcessDuration is a histogram of the time an operator spends processing each entry.
+	MetricProcessDuration = "stanza_operator_process_duration_seconds"
+	// MetricQueueLength is the number of entries waiting in an operator's queue.
+	MetricQueueLength = "stanza_operator_queue_length"
+)
+
+// defaultLatencyBounds are the upper bounds, in seconds, of the process duration histogram
+var defaultLatencyBounds = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// NewOperatorMetrics creates a new set of metrics for an operator.
+func NewOperatorMetrics() *OperatorMetrics {
+	return &OperatorMetrics{
+		latency: newHistogram(defaultLatencyBounds),
+	}
+}
+
+// OperatorMetrics collects the self-telemetry of an operator.
+// All methods may be called on a nil value, in which case nothing is recorded.
+type OperatorMetrics struct {
+	received uint64
+	emitted  uint64
+	dropped  uint64
+	errors   uint64
+	latency  *histogram
+}
+
+// Received records that an entry was received.
+func (m *OperatorMetrics) Received() {
+	if m != nil {
+		atomic.AddUint64(&m.received, 1)
+	}
+}
+
+// Emitted records that an entry was written to the operator's outputs.
+func (m *OperatorMetrics) Emitted() {
+	if m != nil {
+		atomic.AddUint64(&m.emitted, 1)
+	}
+}
+
+// Dropped records that an entry was discarded.
+func (m *OperatorMetrics) Dropped() {
+	if m != nil {
+		atomic.AddUint64(&m.dropped, 1)
+	}
+}
+
+// Failed records that an error occurred while processing an entry.
+func (m *OperatorMetrics) Failed() {
+	if m != nil {
+		atomic.AddUint64(&m.errors, 1)
+	}
+}
+
+// ObserveSince records the time taken to process an entry, starting from start.
+func (m *OperatorMetrics) ObserveSince(start time.Time) {
+	if m != nil {
+		m.latency.observe(time.Since(start).Seconds())
+	}
+}
+
+// Snapshot returns the current value of each metric, labeled with the operator's id and type.
+func (m *OperatorMetrics) Snapshot(operatorID, operatorType string) []operator.Metric {
+	if m == nil {
+		return nil
+	}
+
+	labels := func() map[string]string {
+		return map[string]string{"operator_id": operatorID, "operator_type": operatorType}
+	}
+	counter := func(name, help string, value *uint64) operator.Metric {
+		return operator.Metric{
+			Name:   name,
+			Help:   help,
+			Type:   operator.CounterMetric,
+			Labels: labels(),
+			Value:  float64(atomic.LoadUint64(value)),
+		}
+	}
+
+	return []operator.Metric{
+		counter(MetricEntriesReceived, "Entries received from other operators.", &m.received),
+		counter(MetricEntriesEmitted, "Entries written to the operator's outputs.", &m.emitted),
+		counter(MetricEntriesDropped, "Entries discarded by the operator.", &m.dropped),
+		counter(MetricErrors, "Errors encountered while processing entries.", &m.errors),
+		{
+			Name:      MetricProcessDuration,
+			Help:      "Time spent processing each entry, excluding time spent in downstream operators.",
+			Type:      operator.HistogramMetric,
+			Labels:    labels(),
+			Histogram: m.latency.snapshot(),
+		},
+	}
+}
+
+// histogram counts observations in buckets with fixed upper bounds
+type histogram struct {
+	mux    sync.Mutex
+	bounds []float64
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+}
+
+func (h *histogram) observe(value float64) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	for i, bound := range h.bounds {
+		if value <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// snapshot returns the histogram with cumulative bucket counts
+func (h *histogram) snapshot() *operator.HistogramValue {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	cumulative := make([]uint64, len(h.counts))
+	var total uint64
+	for i, count := range h.counts {
+		total += count
+		cumulative[i] = total
+	}
+
+	return &operator.HistogramValue{
+		Bounds: append([]float64(nil), h.bounds...),
+		Counts: cumulative,
+		Count:  h.count,
+		Sum:    h.sum,
+	}
+}
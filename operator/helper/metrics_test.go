@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func findMetric(t *testing.T, metrics []operator.Metric, name string) operator.Metric {
+	for _, metric := range metrics {
+		if metric.Name == name {
+			return metric
+		}
+	}
+	require.FailNow(t, "metric not found", name)
+	return operator.Metric{}
+}
+
+func TestOperatorMetricsSnapshot(t *testing.T) {
+	m := NewOperatorMetrics()
+	m.Received()
+	m.Received()
+	m.Emitted()
+	m.Dropped()
+	m.Failed()
+	m.latency.observe(0.00002)
+	m.latency.observe(0.3)
+	m.latency.observe(10)
+
+	metrics := m.Snapshot("test-id", "test-type")
+	labels := map[string]string{"operator_id": "test-id", "operator_type": "test-type"}
+
+	received := findMetric(t, metrics, MetricEntriesReceived)
+	require.Equal(t, operator.CounterMetric, received.Type)
+	require.Equal(t, labels, received.Labels)
+	require.Equal(t, 2.0, received.Value)
+	require.Equal(t, 1.0, findMetric(t, metrics, MetricEntriesEmitted).Value)
+	require.Equal(t, 1.0, findMetric(t, metrics, MetricEntriesDropped).Value)
+	require.Equal(t, 1.0, findMetric(t, metrics, MetricErrors).Value)
+
+	latency := findMetric(t, metrics, MetricProcessDuration)
+	require.Equal(t, operator.HistogramMetric, latency.Type)
+	require.Equal(t, defaultLatencyBounds, latency.Histogram.Bounds)
+	require.Equal(t, []uint64{0, 1, 1, 1, 1, 1, 1, 1, 1, 2, 2, 2}, latency.Histogram.Counts)
+	require.Equal(t, uint64(3), latency.Histogram.Count)
+	require.InDelta(t, 10.30002, latency.Histogram.Sum, 0.000001)
+}
+
+func TestOperatorMetricsNil(t *testing.T) {
+	var m *OperatorMetrics
+	m.Received()
+	m.Emitted()
+	m.Dropped()
+	m.Failed()
+	m.ObserveSince(time.Now())
+	require.Nil(t, m.Snapshot("test-id", "test-type"))
+}
+
+func TestTransformerMetrics(t *testing.T) {
+	cfg := NewTransformerConfig("test-id", "test-type")
+	cfg.OnError = DropOnError
+	transformer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	fake := testutil.NewFakeOutput(t)
+	transformer.OutputOperators = []operator.Operator{fake}
+
+	ctx := context.Background()
+	succeed := func(e *entry.Entry) error { return nil }
+	fail := func(e *entry.Entry) error { return fmt.Errorf("failure") }
+
+	require.NoError(t, transformer.ProcessWith(ctx, entry.New(), succeed))
+	require.NoError(t, transformer.ProcessWith(ctx, entry.New(), succeed))
+	require.Error(t, transformer.ProcessWith(ctx, entry.New(), fail))
+
+	metrics := transformer.Metrics()
+	require.Equal(t, 3.0, findMetric(t, metrics, MetricEntriesReceived).Value)
+	require.Equal(t, 2.0, findMetric(t, metrics, MetricEntriesEmitted).Value)
+	require.Equal(t, 1.0, findMetric(t, metrics, MetricEntriesDropped).Value)
+	require.Equal(t, 1.0, findMetric(t, metrics, MetricErrors).Value)
+	require.Equal(t, uint64(2), findMetric(t, metrics, MetricProcessDuration).Histogram.Count)
+}
+
+func TestWriterQueueMetrics(t *testing.T) {
+	cfg := NewWriterConfig("test-id", "test-type")
+	queueCfg := NewQueueConfig()
+	queueCfg.Size = 2
+	queueCfg.Overflow = DropNewestOnOverflow
+	cfg.Queue = &queueCfg
+	writer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	// The single worker blocks on the first entry, so the next two fill the queue and the last is dropped
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	writer.queue.Start(func(ctx context.Context, e *entry.Entry) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	})
+	defer func() {
+		close(release)
+		writer.queue.Stop()
+	}()
+
+	writer.Write(context.Background(), entry.New())
+	<-started
+	for i := 0; i < 3; i++ {
+		writer.Write(context.Background(), entry.New())
+	}
+
+	metrics := writer.Metrics()
+	queueLength := findMetric(t, metrics, MetricQueueLength)
+	require.Equal(t, operator.GaugeMetric, queueLength.Type)
+	require.Equal(t, 2.0, queueLength.Value)
+	require.Equal(t, 1.0, findMetric(t, metrics, MetricEntriesDropped).Value)
+}
@@ -77,6 +77,7 @@ func (c BasicConfig) Build(context operator.BuildContext) (BasicOperator, error)
 		OperatorID:    namespacedID,
 		OperatorType:  c.Type(),
 		SugaredLogger: context.Logger.With("operator_id", namespacedID, "operator_type", c.Type()),
+		Telemetry:     NewOperatorMetrics(),
 	}
 
 	return operator, nil
@@ -87,6 +88,9 @@ type BasicOperator struct {
 	OperatorID   string
 	OperatorType string
 	*zap.SugaredLogger
+
+	// Telemetry collects metrics about the entries processed by the operator. It may be nil.
+	Telemetry *OperatorMetrics
 }
 
 // ID will return the operator id.
@@ -107,6 +111,11 @@ func (p *BasicOperator) Logger() *zap.SugaredLogger {
 	return p.SugaredLogger
 }
 
+// Metrics returns the operator's current metrics.
+func (p *BasicOperator) Metrics() []operator.Metric {
+	return p.Telemetry.Snapshot(p.ID(), p.Type())
+}
+
 // Start will start the operator.
 func (p *BasicOperator) Start(_ operator.Persister) error {
 	return nil
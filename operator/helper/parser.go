@@ -16,6 +16,7 @@ package helper
 
 import (
 	"context"
+	"time"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/errors"
@@ -100,6 +101,9 @@ func (p *ParserOperator) ProcessWith(ctx context.Context, entry *entry.Entry, pa
 }
 
 func (p *ParserOperator) ProcessWithCallback(ctx context.Context, entry *entry.Entry, parse ParseFunction, cb func(*entry.Entry) error) error {
+	start := time.Now()
+	p.Telemetry.Received()
+
 	// Short circuit if the "if" condition does not match
 	skip, err := p.Skip(ctx, entry)
 	if err != nil {
@@ -120,6 +124,7 @@ func (p *ParserOperator) ProcessWithCallback(ctx context.Context, entry *entry.E
 		}
 	}
 
+	p.Telemetry.ObserveSince(start)
 	p.Write(ctx, entry)
 	return nil
 }
@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/errors"
+)
+
+const (
+	// BlockOnOverflow specifies an overflow mode that blocks the writer until space is available.
+	BlockOnOverflow = "block"
+	// DropOldestOnOverflow specifies an overflow mode that discards the oldest queued entry.
+	DropOldestOnOverflow = "drop_oldest"
+	// DropNewestOnOverflow specifies an overflow mode that discards the entry being written.
+	DropNewestOnOverflow = "drop_newest"
+
+	defaultQueueSize    = 1000
+	defaultQueueWorkers = 1
+)
+
+// NewQueueConfig creates a new queue config with default values
+func NewQueueConfig() QueueConfig {
+	return QueueConfig{
+		Size:     defaultQueueSize,
+		Workers:  defaultQueueWorkers,
+		Overflow: BlockOnOverflow,
+	}
+}
+
+// QueueConfig is the configuration of an in-memory queue between a writer and its outputs.
+// With more than one worker, entries may be delivered to the outputs out of order.
+type QueueConfig struct {
+	Size     int    `mapstructure:"size"     json:"size,omitempty"     yaml:"size,omitempty"`
+	Workers  int    `mapstructure:"workers"  json:"workers,omitempty"  yaml:"workers,omitempty"`
+	Overflow string `mapstructure:"overflow" json:"overflow,omitempty" yaml:"overflow,omitempty"`
+}
+
+// Build will build a queue from the config.
+func (c QueueConfig) Build(logger *zap.SugaredLogger) (*Queue, error) {
+	if c.Size == 0 {
+		c.Size = defaultQueueSize
+	}
+	if c.Workers == 0 {
+		c.Workers = defaultQueueWorkers
+	}
+	if c.Overflow == "" {
+		c.Overflow = BlockOnOverflow
+	}
+
+	if c.Size < 0 {
+		return nil, errors.NewError(
+			"queue config has an invalid `size` field.",
+			"ensure that the `size` field is a positive number.",
+			"size", strconv.Itoa(c.Size),
+		)
+	}
+
+	if c.Workers < 0 {
+		return nil, errors.NewError(
+			"queue config has an invalid `workers` field.",
+			"ensure that the `workers` field is a positive number.",
+			"workers", strconv.Itoa(c.Workers),
+		)
+	}
+
+	switch c.Overflow {
+	case BlockOnOverflow, DropOldestOnOverflow, DropNewestOnOverflow:
+	default:
+		return nil, errors.NewError(
+			"queue config has an invalid `overflow` field.",
+			"ensure that the `overflow` field is set to `block`, `drop_oldest` or `drop_newest`.",
+			"overflow", c.Overflow,
+		)
+	}
+
+	return &Queue{
+		SugaredLogger: logger,
+		size:          c.Size,
+		workers:       c.Workers,
+		overflow:      c.Overflow,
+	}, nil
+}
+
+// queuedEntry is an entry waiting in a queue, along with the context it was written with.
+type queuedEntry struct {
+	ctx   context.Context
+	entry *entry.Entry
+}
+
+// QueueStats is a snapshot of the state of a queue.
+type QueueStats struct {
+	Capacity int
+	Length   int
+	Dropped  uint64
+}
+
+// Queue decouples a writer from its outputs by handing entries to a pool of workers
+// through a bounded channel.
+type Queue struct {
+	*zap.SugaredLogger
+
+	size     int
+	workers  int
+	overflow string
+	dropped  uint64
+	metrics  *OperatorMetrics
+
+	entries chan queuedEntry
+	done    chan struct{}
+	running bool
+	mux     sync.RWMutex
+	wg      sync.WaitGroup
+
+	// senders tracks the calls to Enqueue that may still send to entries
+	senders sync.WaitGroup
+}
+
+// Start will start the workers of the queue, each of which will pass dequeued entries to write.
+func (q *Queue) Start(write func(context.Context, *entry.Entry)) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if q.running {
+		return
+	}
+
+	q.entries = make(chan queuedEntry, q.size)
+	q.done = make(chan struct{})
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go func(entries <-chan queuedEntry) {
+			defer q.wg.Done()
+			for item := range entries {
+				write(item.ctx, item.entry)
+			}
+		}(q.entries)
+	}
+	q.running = true
+}
+
+// Stop will stop accepting entries and wait until all queued entries have been written.
+// Writers that are blocked on a full queue are released, and their entries are nacked.
+func (q *Queue) Stop() {
+	q.mux.Lock()
+	if !q.running {
+		q.mux.Unlock()
+		return
+	}
+	q.running = false
+	close(q.done)
+	q.mux.Unlock()
+
+	q.senders.Wait()
+	close(q.entries)
+	q.wg.Wait()
+}
+
+// Enqueue will add an entry to the queue, applying the overflow policy if the queue is full.
+// It returns false if the queue is not running, in which case the caller must write the entry itself.
+// If the queue blocks on overflow, and the context is done or the queue is stopped while waiting
+// for space, the entry is nacked.
+func (q *Queue) Enqueue(ctx context.Context, e *entry.Entry) bool {
+	q.mux.RLock()
+	if !q.running {
+		q.mux.RUnlock()
+		return false
+	}
+	q.senders.Add(1)
+	q.mux.RUnlock()
+	defer q.senders.Done()
+
+	item := queuedEntry{ctx: ctx, entry: e}
+	switch q.overflow {
+	case DropNewestOnOverflow:
+		select {
+		case q.entries <- item:
+		default:
+			q.drop(item)
+		}
+	case DropOldestOnOverflow:
+		for {
+			select {
+			case q.entries <- item:
+				return true
+			default:
+			}
+
+			select {
+			case oldest := <-q.entries:
+				q.drop(oldest)
+			default:
+			}
+		}
+	default:
+		select {
+		case q.entries <- item:
+		case <-ctx.Done():
+			q.Warnw("Context done while waiting for space in the queue", zap.Error(ctx.Err()))
+			Nack(ctx)
+		case <-q.done:
+			q.Warnw("Queue stopped while waiting for space in the queue")
+			Nack(ctx)
+		}
+	}
+	return true
+}
+
+// Stats returns a snapshot of the current state of the queue.
+func (q *Queue) Stats() QueueStats {
+	q.mux.RLock()
+	defer q.mux.RUnlock()
+	return QueueStats{
+		Capacity: q.size,
+		Length:   len(q.entries),
+		Dropped:  atomic.LoadUint64(&q.dropped),
+	}
+}
+
+// drop records that an entry was discarded because the queue was full.
+func (q *Queue) drop(item queuedEntry) {
+	dropped := atomic.AddUint64(&q.dropped, 1)
+	q.metrics.Dropped()
+	q.Warnw("Queue is full, dropping entry", "overflow", q.overflow, "dropped_total", dropped)
+	q.Debugw("Dropped entry", zap.Any("entry", item.entry))
+	Nack(item.ctx)
+}
@@ -0,0 +1,260 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+func TestQueueConfigBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		config    QueueConfig
+		expectErr bool
+	}{
+		{"Default", NewQueueConfig(), false},
+		{"Empty", QueueConfig{}, false},
+		{"DropOldest", QueueConfig{Size: 10, Workers: 2, Overflow: DropOldestOnOverflow}, false},
+		{"DropNewest", QueueConfig{Size: 10, Workers: 2, Overflow: DropNewestOnOverflow}, false},
+		{"NegativeSize", QueueConfig{Size: -1}, true},
+		{"NegativeWorkers", QueueConfig{Workers: -1}, true},
+		{"InvalidOverflow", QueueConfig{Overflow: "explode"}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.config.Build(zaptest.NewLogger(t).Sugar())
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func newTestQueue(t *testing.T, size int, overflow string) *Queue {
+	cfg := QueueConfig{Size: size, Workers: 1, Overflow: overflow}
+	queue, err := cfg.Build(zaptest.NewLogger(t).Sugar())
+	require.NoError(t, err)
+	return queue
+}
+
+func TestQueueNotStarted(t *testing.T) {
+	queue := newTestQueue(t, 1, BlockOnOverflow)
+	require.False(t, queue.Enqueue(context.Background(), entry.New()))
+}
+
+func TestQueueBlock(t *testing.T) {
+	queue := newTestQueue(t, 10, BlockOnOverflow)
+
+	received := make(chan *entry.Entry, 100)
+	queue.Start(func(_ context.Context, e *entry.Entry) { received <- e })
+
+	for i := 0; i < 50; i++ {
+		require.True(t, queue.Enqueue(context.Background(), entry.New()))
+	}
+	queue.Stop()
+
+	require.Len(t, received, 50)
+	require.Equal(t, uint64(0), queue.Stats().Dropped)
+}
+
+func TestQueueBlockContextDone(t *testing.T) {
+	queue := newTestQueue(t, 1, BlockOnOverflow)
+
+	release := make(chan struct{})
+	queue.Start(func(_ context.Context, _ *entry.Entry) { <-release })
+	defer func() {
+		close(release)
+		queue.Stop()
+	}()
+
+	// The first entry is taken by the worker, and the second fills the queue
+	queue.Enqueue(context.Background(), entry.New())
+	require.Eventually(t, func() bool { return queue.Stats().Length == 0 }, time.Second, time.Millisecond)
+	queue.Enqueue(context.Background(), entry.New())
+
+	delivered := make(chan bool, 1)
+	ctx, cancel := context.WithCancel(WithAcknowledgement(context.Background(), func(d bool) { delivered <- d }))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	require.True(t, queue.Enqueue(ctx, entry.New()))
+	require.False(t, <-delivered)
+}
+
+func TestQueueStopReleasesBlockedWriter(t *testing.T) {
+	queue := newTestQueue(t, 1, BlockOnOverflow)
+
+	release := make(chan struct{})
+	queue.Start(func(_ context.Context, _ *entry.Entry) { <-release })
+
+	queue.Enqueue(context.Background(), entry.New())
+	require.Eventually(t, func() bool { return queue.Stats().Length == 0 }, time.Second, time.Millisecond)
+	queue.Enqueue(context.Background(), entry.New())
+
+	delivered := make(chan bool, 1)
+	ctx := WithAcknowledgement(context.Background(), func(d bool) { delivered <- d })
+	enqueued := make(chan struct{})
+	go func() {
+		queue.Enqueue(ctx, entry.New())
+		close(enqueued)
+	}()
+
+	// Give the writer time to block on the full queue
+	time.Sleep(10 * time.Millisecond)
+	stopped := make(chan struct{})
+	go func() {
+		queue.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-enqueued:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for the blocked writer to be released")
+	}
+	require.False(t, <-delivered)
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for the queue to stop")
+	}
+}
+
+func TestQueueDropNewest(t *testing.T) {
+	queue := newTestQueue(t, 2, DropNewestOnOverflow)
+
+	release := make(chan struct{})
+	received := make(chan interface{}, 10)
+	queue.Start(func(_ context.Context, e *entry.Entry) {
+		<-release
+		received <- e.Body
+	})
+
+	// The first entry is taken by the worker, which blocks until released
+	queue.Enqueue(context.Background(), &entry.Entry{Body: 0})
+	require.Eventually(t, func() bool { return queue.Stats().Length == 0 }, time.Second, time.Millisecond)
+
+	for i := 1; i <= 4; i++ {
+		queue.Enqueue(context.Background(), &entry.Entry{Body: i})
+	}
+	require.Equal(t, uint64(2), queue.Stats().Dropped)
+
+	close(release)
+	queue.Stop()
+
+	require.Equal(t, 0, <-received)
+	require.Equal(t, 1, <-received)
+	require.Equal(t, 2, <-received)
+	require.Len(t, received, 0)
+}
+
+func TestQueueDropOldest(t *testing.T) {
+	queue := newTestQueue(t, 2, DropOldestOnOverflow)
+
+	release := make(chan struct{})
+	received := make(chan interface{}, 10)
+	queue.Start(func(_ context.Context, e *entry.Entry) {
+		<-release
+		received <- e.Body
+	})
+
+	queue.Enqueue(context.Background(), &entry.Entry{Body: 0})
+	require.Eventually(t, func() bool { return queue.Stats().Length == 0 }, time.Second, time.Millisecond)
+
+	for i := 1; i <= 4; i++ {
+		queue.Enqueue(context.Background(), &entry.Entry{Body: i})
+	}
+	require.Equal(t, uint64(2), queue.Stats().Dropped)
+
+	close(release)
+	queue.Stop()
+
+	require.Equal(t, 0, <-received)
+	require.Equal(t, 3, <-received)
+	require.Equal(t, 4, <-received)
+	require.Len(t, received, 0)
+}
+
+func TestQueueRestart(t *testing.T) {
+	queue := newTestQueue(t, 10, BlockOnOverflow)
+
+	var mux sync.Mutex
+	count := 0
+	write := func(_ context.Context, _ *entry.Entry) {
+		mux.Lock()
+		defer mux.Unlock()
+		count++
+	}
+
+	queue.Start(write)
+	require.True(t, queue.Enqueue(context.Background(), entry.New()))
+	queue.Stop()
+	require.False(t, queue.Enqueue(context.Background(), entry.New()))
+
+	queue.Start(write)
+	require.True(t, queue.Enqueue(context.Background(), entry.New()))
+	queue.Stop()
+
+	require.Equal(t, 2, count)
+}
+
+func TestWriterOperatorQueue(t *testing.T) {
+	cfg := NewWriterConfig("test", "test")
+	queueCfg := NewQueueConfig()
+	cfg.Queue = &queueCfg
+
+	writer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	fake := testutil.NewFakeOutput(t)
+	writer.OutputOperators = []operator.Operator{fake}
+
+	stats, ok := writer.QueueStats()
+	require.True(t, ok)
+	require.Equal(t, defaultQueueSize, stats.Capacity)
+
+	writer.StartQueue()
+	writer.Write(context.Background(), &entry.Entry{Body: "test"})
+	fake.ExpectBody(t, "test")
+	writer.StopQueue()
+
+	// Once the queue is stopped, entries are written synchronously
+	writer.Write(context.Background(), &entry.Entry{Body: "sync"})
+	fake.ExpectBody(t, "sync")
+}
+
+func TestWriterOperatorNoQueue(t *testing.T) {
+	writer := WriterOperator{}
+	_, ok := writer.QueueStats()
+	require.False(t, ok)
+	writer.StartQueue()
+	writer.StopQueue()
+}
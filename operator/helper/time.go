@@ -39,6 +39,14 @@ const EpochKey = "epoch"
 // NativeKey is literally "native" and refers to Golang's native time.Time
 const NativeKey = "native" // provided for operator development
 
+// YearInferenceNow sets the current year on timestamps before 1970, or the previous year if the
+// timestamp would be more than a week in the future. It is the default year inference.
+const YearInferenceNow = "now"
+
+// YearInferenceObserved sets the year closest to the time the entry was read on timestamps
+// without a year, so that both December to January and January to December rollovers are handled
+const YearInferenceObserved = "observed"
+
 // NewTimeParser creates a new time parser with default values
 func NewTimeParser() TimeParser {
 	return TimeParser{
@@ -54,12 +62,22 @@ type TimeParser struct {
 	PreserveTo *entry.Field `mapstructure:"preserve_to,omitempty" json:"preserve_to,omitempty" yaml:"preserve_to,omitempty"`
 	Location   string       `mapstructure:"location,omitempty"    json:"location,omitempty"    yaml:"location,omitempty"`
 
-	location *time.Location
+	Layouts       []TimeLayout `mapstructure:"layouts,omitempty"        json:"layouts,omitempty"        yaml:"layouts,omitempty"`
+	YearInference string       `mapstructure:"year_inference,omitempty" json:"year_inference,omitempty" yaml:"year_inference,omitempty"`
+
+	location  *time.Location
+	fallbacks []TimeParser
+}
+
+// TimeLayout is an alternative layout, which is tried if the layouts before it do not match
+type TimeLayout struct {
+	Layout     string `mapstructure:"layout,omitempty"      json:"layout,omitempty"      yaml:"layout,omitempty"`
+	LayoutType string `mapstructure:"layout_type,omitempty" json:"layout_type,omitempty" yaml:"layout_type,omitempty"`
 }
 
 // IsZero returns true if the TimeParser is not a valid config
 func (t *TimeParser) IsZero() bool {
-	return t.Layout == ""
+	return t.Layout == "" && len(t.Layouts) == 0
 }
 
 // Validate validates a TimeParser, and reconfigures it if necessary
@@ -68,10 +86,48 @@ func (t *TimeParser) Validate(context operator.BuildContext) error {
 		return fmt.Errorf("missing required parameter 'parse_from'")
 	}
 
-	if t.Layout == "" && t.LayoutType != "native" {
+	if t.Layout == "" && t.LayoutType != "native" && len(t.Layouts) == 0 {
 		return errors.NewError("missing required configuration parameter `layout`", "")
 	}
 
+	switch t.YearInference {
+	case "", YearInferenceNow, YearInferenceObserved:
+	default:
+		return errors.NewError(
+			fmt.Sprintf("unsupported year_inference %s", t.YearInference),
+			"valid values are 'now' and 'observed'",
+		)
+	}
+
+	t.fallbacks = nil
+	for i, layout := range t.Layouts {
+		if layout.Layout == "" && layout.LayoutType != NativeKey {
+			return errors.NewError(fmt.Sprintf("missing required configuration parameter `layout` in `layouts[%d]`", i), "")
+		}
+		fallback := TimeParser{
+			Layout:     layout.Layout,
+			LayoutType: layout.LayoutType,
+			Location:   t.Location,
+		}
+		if err := fallback.validateLayout(); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("layouts[%d]", i))
+		}
+		t.fallbacks = append(t.fallbacks, fallback)
+	}
+
+	if !t.hasLayout() {
+		return nil
+	}
+	return t.validateLayout()
+}
+
+// hasLayout returns true if the layout of the TimeParser itself is configured, and not only its fallbacks
+func (t *TimeParser) hasLayout() bool {
+	return t.Layout != "" || t.LayoutType == NativeKey
+}
+
+// validateLayout validates the layout and location of a TimeParser, and converts strptime layouts to gotime layouts
+func (t *TimeParser) validateLayout() error {
 	if t.LayoutType == "" {
 		t.LayoutType = StrptimeKey
 	}
@@ -142,27 +198,16 @@ func (t *TimeParser) Parse(entry *entry.Entry) error {
 		)
 	}
 
-	switch t.LayoutType {
-	case NativeKey:
-		timeValue, ok := value.(time.Time)
-		if !ok {
-			return fmt.Errorf("native time.Time field required, but found %v of type %T", value, value)
-		}
-		entry.Timestamp = setTimestampYear(timeValue)
-	case GotimeKey:
-		timeValue, err := t.parseGotime(value)
-		if err != nil {
-			return err
-		}
-		entry.Timestamp = setTimestampYear(timeValue)
-	case EpochKey:
-		timeValue, err := t.parseEpochTime(value)
-		if err != nil {
-			return err
-		}
+	timeValue, err := t.parseLayouts(value)
+	if err != nil {
+		return err
+	}
+
+	if t.YearInference == YearInferenceObserved {
+		// Until it is parsed, the timestamp of an entry is the time it was read
+		entry.Timestamp = setTimestampYearObserved(timeValue, entry.Timestamp)
+	} else {
 		entry.Timestamp = setTimestampYear(timeValue)
-	default:
-		return fmt.Errorf("unsupported layout type: %s", t.LayoutType)
 	}
 
 	if t.PreserveTo != nil {
@@ -174,6 +219,49 @@ func (t *TimeParser) Parse(entry *entry.Entry) error {
 	return nil
 }
 
+// parseLayouts parses a value with the layout of the TimeParser, then with each fallback layout
+// in order, until one of them succeeds
+func (t *TimeParser) parseLayouts(value interface{}) (time.Time, error) {
+	if len(t.fallbacks) == 0 {
+		return t.parse(value)
+	}
+
+	errs := make([]string, 0, len(t.fallbacks)+1)
+	if t.hasLayout() {
+		timeValue, err := t.parse(value)
+		if err == nil {
+			return timeValue, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	for i := range t.fallbacks {
+		timeValue, err := t.fallbacks[i].parse(value)
+		if err == nil {
+			return timeValue, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return time.Time{}, fmt.Errorf("no layout matches: %s", strings.Join(errs, "; "))
+}
+
+// parse parses a value with the layout of the TimeParser
+func (t *TimeParser) parse(value interface{}) (time.Time, error) {
+	switch t.LayoutType {
+	case NativeKey:
+		timeValue, ok := value.(time.Time)
+		if !ok {
+			return time.Time{}, fmt.Errorf("native time.Time field required, but found %v of type %T", value, value)
+		}
+		return timeValue, nil
+	case GotimeKey:
+		return t.parseGotime(value)
+	case EpochKey:
+		return t.parseEpochTime(value)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported layout type: %s", t.LayoutType)
+	}
+}
+
 func (t *TimeParser) parseGotime(value interface{}) (time.Time, error) {
 	var str string
 	switch v := value.(type) {
@@ -299,5 +387,33 @@ func setTimestampYear(t time.Time) time.Time {
 	return d
 }
 
+// setTimestampYearObserved sets the year of a timestamp without a year to the year that puts it closest
+// to the observed time, which is the current time if it is not set. A timestamp from December observed
+// in January is given the previous year, and a timestamp from January observed in December the next year.
+func setTimestampYearObserved(t time.Time, observed time.Time) time.Time {
+	if t.Year() != 0 {
+		return t
+	}
+	if observed.IsZero() {
+		observed = now()
+	}
+
+	var closest time.Time
+	for year := observed.Year() - 1; year <= observed.Year()+1; year++ {
+		d := time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		if closest.IsZero() || absDuration(d.Sub(observed)) < absDuration(closest.Sub(observed)) {
+			closest = d
+		}
+	}
+	return closest
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
 // Allows tests to override with deterministic value
 var now = time.Now
@@ -75,9 +75,175 @@ func Test_setTimestampYear(t *testing.T) {
 	})
 }
 
+func Test_setTimestampYearObserved(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2020, 06, 16, 3, 31, 34, 525, time.UTC)
+	}
+
+	cases := []struct {
+		name     string
+		noYear   time.Time
+		observed time.Time
+		expected time.Time
+	}{
+		{
+			"SameYear",
+			time.Date(0, 06, 16, 3, 31, 34, 525, time.UTC),
+			time.Date(2021, 06, 16, 3, 31, 35, 0, time.UTC),
+			time.Date(2021, 06, 16, 3, 31, 34, 525, time.UTC),
+		},
+		{
+			"DecemberObservedInJanuary",
+			time.Date(0, 12, 31, 23, 59, 59, 0, time.UTC),
+			time.Date(2021, 01, 01, 0, 0, 2, 0, time.UTC),
+			time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			"JanuaryObservedInDecember",
+			time.Date(0, 01, 01, 0, 0, 1, 0, time.UTC),
+			time.Date(2020, 12, 31, 23, 59, 58, 0, time.UTC),
+			time.Date(2021, 01, 01, 0, 0, 1, 0, time.UTC),
+		},
+		{
+			"FutureMonth",
+			time.Date(0, 03, 01, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 01, 15, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 03, 01, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"ObservedNotSet",
+			time.Date(0, 06, 15, 3, 31, 34, 525, time.UTC),
+			time.Time{},
+			time.Date(2020, 06, 15, 3, 31, 34, 525, time.UTC),
+		},
+		{
+			"HasYear",
+			time.Date(1999, 12, 31, 23, 59, 59, 0, time.UTC),
+			time.Date(2021, 01, 01, 0, 0, 0, 0, time.UTC),
+			time.Date(1999, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, setTimestampYearObserved(tc.noYear, tc.observed))
+		})
+	}
+}
+
 func TestIsZero(t *testing.T) {
 	require.True(t, (&TimeParser{}).IsZero())
 	require.False(t, (&TimeParser{Layout: "strptime"}).IsZero())
+	require.False(t, (&TimeParser{Layouts: []TimeLayout{{Layout: "%Y"}}}).IsZero())
+}
+
+func TestTimeParserLayouts(t *testing.T) {
+	field := entry.NewBodyField()
+	newParser := func() *TimeParser {
+		return &TimeParser{
+			ParseFrom:  &field,
+			LayoutType: StrptimeKey,
+			Layout:     "%Y-%m-%dT%H:%M:%S%z",
+			Layouts: []TimeLayout{
+				{LayoutType: EpochKey, Layout: "s"},
+				{Layout: "%d/%b/%Y:%H:%M:%S %z"},
+			},
+		}
+	}
+	expected := time.Date(2022, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		modify   func(*TimeParser)
+		sample   interface{}
+		parseErr bool
+	}{
+		{"Layout", func(tp *TimeParser) {}, "2022-03-04T05:06:07+0000", false},
+		{"FirstFallback", func(tp *TimeParser) {}, expected.Unix(), false},
+		{"SecondFallback", func(tp *TimeParser) {}, "04/Mar/2022:05:06:07 +0000", false},
+		{"NoMatch", func(tp *TimeParser) {}, "March 4th 2022", true},
+		{"OnlyLayouts", func(tp *TimeParser) { tp.Layout = ""; tp.LayoutType = "" }, "04/Mar/2022:05:06:07 +0000", false},
+		{"OnlyLayoutsNoMatch", func(tp *TimeParser) { tp.Layout = ""; tp.LayoutType = "" }, "2022-03-04T05:06:07+0000", true},
+		{"NativeFallback", func(tp *TimeParser) { tp.Layouts = []TimeLayout{{LayoutType: NativeKey}} }, expected, false},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tp := newParser()
+			tc.modify(tp)
+			runTimeParseTest(tp, makeTestEntry(field, tc.sample), false, tc.parseErr, expected)(t)
+		})
+	}
+}
+
+func TestTimeParserLayoutsError(t *testing.T) {
+	field := entry.NewBodyField()
+	tp := &TimeParser{
+		ParseFrom: &field,
+		Layout:    "%Y-%m-%d",
+		Layouts:   []TimeLayout{{LayoutType: EpochKey, Layout: "s"}},
+	}
+	require.NoError(t, tp.Validate(testutil.NewBuildContext(t)))
+
+	err := tp.Parse(makeTestEntry(field, "yesterday"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no layout matches")
+	require.Contains(t, err.Error(), `parsing time "yesterday"`)
+	require.Contains(t, err.Error(), "invalid value 'yesterday' for layout 's'")
+}
+
+func TestTimeParserYearInference(t *testing.T) {
+	now = func() time.Time { return time.Date(2021, 01, 01, 0, 0, 5, 0, time.UTC) }
+	field := entry.NewBodyField()
+
+	cases := []struct {
+		name          string
+		yearInference string
+		observed      time.Time
+		expected      time.Time
+	}{
+		{
+			"Now",
+			YearInferenceNow,
+			time.Date(2021, 12, 31, 23, 59, 58, 0, time.UTC),
+			time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			"Default",
+			"",
+			time.Date(2021, 12, 31, 23, 59, 58, 0, time.UTC),
+			time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			"Observed",
+			YearInferenceObserved,
+			time.Date(2021, 12, 31, 23, 59, 58, 0, time.UTC),
+			time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			"ObservedRollover",
+			YearInferenceObserved,
+			time.Date(2022, 01, 01, 0, 0, 3, 0, time.UTC),
+			time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tp := &TimeParser{
+				ParseFrom:     &field,
+				Layout:        "%b %d %H:%M:%S",
+				Location:      "UTC",
+				YearInference: tc.yearInference,
+			}
+			e := makeTestEntry(field, "Dec 31 23:59:59")
+			e.Timestamp = tc.observed
+			runTimeParseTest(tp, e, false, false, tc.expected)(t)
+		})
+	}
 }
 
 func TestTimeParser(t *testing.T) {
@@ -500,13 +666,15 @@ func TestTimeEpochs(t *testing.T) {
 
 func TestTimeErrors(t *testing.T) {
 	testCases := []struct {
-		name       string
-		sample     interface{}
-		layoutType string
-		layout     string
-		location   string
-		buildErr   bool
-		parseErr   bool
+		name          string
+		sample        interface{}
+		layoutType    string
+		layout        string
+		location      string
+		layouts       []TimeLayout
+		yearInference string
+		buildErr      bool
+		parseErr      bool
 	}{
 		{
 			name:       "bad-layout-type",
@@ -550,6 +718,34 @@ func TestTimeErrors(t *testing.T) {
 			sample:     1,
 			parseErr:   true,
 		},
+		{
+			name:       "bad-fallback-layout-type",
+			layoutType: "strptime",
+			layout:     "%Y",
+			layouts:    []TimeLayout{{LayoutType: "fake", Layout: "%Y"}},
+			buildErr:   true,
+		},
+		{
+			name:       "bad-fallback-layout",
+			layoutType: "strptime",
+			layout:     "%Y",
+			layouts:    []TimeLayout{{LayoutType: "epoch", Layout: "years"}},
+			buildErr:   true,
+		},
+		{
+			name:       "missing-fallback-layout",
+			layoutType: "strptime",
+			layout:     "%Y",
+			layouts:    []TimeLayout{{LayoutType: "epoch"}},
+			buildErr:   true,
+		},
+		{
+			name:          "bad-year-inference",
+			layoutType:    "strptime",
+			layout:        "%Y",
+			yearInference: "guess",
+			buildErr:      true,
+		},
 		{
 			name:       "bad-epoch-value",
 			layoutType: "epoch",
@@ -565,9 +761,13 @@ func TestTimeErrors(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			rootCfg := parseTimeTestConfig(tc.layoutType, tc.layout, tc.location, rootField)
+			rootCfg.Layouts = tc.layouts
+			rootCfg.YearInference = tc.yearInference
 			t.Run("err-root", runTimeParseTest(rootCfg, makeTestEntry(rootField, tc.sample), tc.buildErr, tc.parseErr, time.Now()))
 
 			nonRootCfg := parseTimeTestConfig(tc.layoutType, tc.layout, tc.location, someField)
+			nonRootCfg.Layouts = tc.layouts
+			nonRootCfg.YearInference = tc.yearInference
 			t.Run("err-non-root", runTimeParseTest(nonRootCfg, makeTestEntry(someField, tc.sample), tc.buildErr, tc.parseErr, time.Now()))
 		})
 	}
@@ -674,6 +874,28 @@ func TestGoldenTimeParserConfig(t *testing.T) {
 				return cfg
 			}(),
 		},
+		{
+			"layouts",
+			false,
+			func() *TimeParser {
+				cfg := defaultTimeCfg()
+				cfg.Layouts = []TimeLayout{
+					{Layout: "%Y-%m-%dT%H:%M:%S%z"},
+					{LayoutType: "epoch", Layout: "ms"},
+				}
+				return cfg
+			}(),
+		},
+		{
+			"year_inference",
+			false,
+			func() *TimeParser {
+				cfg := defaultTimeCfg()
+				cfg.Layout = "%b %d %H:%M:%S"
+				cfg.YearInference = YearInferenceObserved
+				return cfg
+			}(),
+		},
 	}
 
 	for _, tc := range cases {
@@ -37,3 +37,22 @@ func (t *TLSServerConfig) UnmarshalYAML(unmarshal func(interface{}) error) error
 	}
 	return mapstructure.Decode(tlsConfig, &t.TLSServerSetting)
 }
+
+type TLSClientConfig struct {
+	*configtls.TLSClientSetting `mapstructure:",squash" json:",inline" yaml:",inline"`
+}
+
+func NewTLSClientConfig(setting *configtls.TLSClientSetting) *TLSClientConfig {
+	return &TLSClientConfig{
+		TLSClientSetting: setting,
+	}
+}
+
+func (t *TLSClientConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tlsConfig map[string]interface{}
+	err := unmarshal(&tlsConfig)
+	if err != nil {
+		return err
+	}
+	return mapstructure.Decode(tlsConfig, &t.TLSClientSetting)
+}
@@ -17,6 +17,7 @@ package helper
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/antonmedv/expr"
 	"github.com/antonmedv/expr/vm"
@@ -89,6 +90,9 @@ func (t *TransformerOperator) CanProcess() bool {
 
 // ProcessWith will process an entry with a transform function.
 func (t *TransformerOperator) ProcessWith(ctx context.Context, entry *entry.Entry, transform TransformFunction) error {
+	start := time.Now()
+	t.Telemetry.Received()
+
 	// Short circuit if the "if" condition does not match
 	skip, err := t.Skip(ctx, entry)
 	if err != nil {
@@ -102,6 +106,7 @@ func (t *TransformerOperator) ProcessWith(ctx context.Context, entry *entry.Entr
 	if err := transform(entry); err != nil {
 		return t.HandleEntryError(ctx, entry, err)
 	}
+	t.Telemetry.ObserveSince(start)
 	t.Write(ctx, entry)
 	return nil
 }
@@ -109,8 +114,13 @@ func (t *TransformerOperator) ProcessWith(ctx context.Context, entry *entry.Entr
 // HandleEntryError will handle an entry error using the on_error strategy.
 func (t *TransformerOperator) HandleEntryError(ctx context.Context, entry *entry.Entry, err error) error {
 	t.Errorw("Failed to process entry", zap.Any("error", err), zap.Any("action", t.OnError), zap.Any("entry", entry))
+	t.Telemetry.Failed()
 	if t.OnError == SendOnError {
 		t.Write(ctx, entry)
+	} else {
+		t.Telemetry.Dropped()
+		// The entry was discarded as configured, so it does not need to be redelivered
+		Ack(ctx)
 	}
 	return err
 }
@@ -18,8 +18,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/errors"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
 )
 
@@ -33,7 +35,8 @@ func NewWriterConfig(operatorID, operatorType string) WriterConfig {
 // WriterConfig is the configuration of a writer operator.
 type WriterConfig struct {
 	BasicConfig `mapstructure:",squash" yaml:",inline"`
-	OutputIDs   OutputIDs `mapstructure:"output" json:"output" yaml:"output"`
+	OutputIDs   OutputIDs    `mapstructure:"output" json:"output"          yaml:"output"`
+	Queue       *QueueConfig `mapstructure:"queue"  json:"queue,omitempty" yaml:"queue,omitempty"`
 }
 
 // Build will build a writer operator from the config.
@@ -49,7 +52,18 @@ func (c WriterConfig) Build(bc operator.BuildContext) (WriterOperator, error) {
 	writer := WriterOperator{
 		OutputIDs:     namespacedIDs,
 		BasicOperator: basicOperator,
+		outputsMux:    &sync.RWMutex{},
 	}
+
+	if c.Queue != nil {
+		queue, err := c.Queue.Build(basicOperator.SugaredLogger)
+		if err != nil {
+			return WriterOperator{}, errors.WithDetails(err, "operator_id", c.ID())
+		}
+		queue.metrics = basicOperator.Telemetry
+		writer.queue = queue
+	}
+
 	return writer, nil
 }
 
@@ -63,12 +77,34 @@ type WriterOperator struct {
 	BasicOperator
 	OutputIDs       OutputIDs
 	OutputOperators []operator.Operator
+
+	// outputsMux guards the outputs, which are replaced while the operator runs when a pipeline is reloaded
+	outputsMux *sync.RWMutex
+	queue      *Queue
 }
 
 // Write will write an entry to the outputs of the operator.
+// If the operator has a running queue, the entry is handed to the queue instead.
 func (w *WriterOperator) Write(ctx context.Context, e *entry.Entry) {
-	for i, operator := range w.OutputOperators {
-		if i == len(w.OutputOperators)-1 {
+	if w.queue != nil && w.queue.Enqueue(ctx, e) {
+		return
+	}
+	w.write(ctx, e)
+}
+
+// write will synchronously pass an entry to each of the outputs of the operator.
+func (w *WriterOperator) write(ctx context.Context, e *entry.Entry) {
+	w.Telemetry.Emitted()
+	outputs := w.Outputs()
+	switch n := len(outputs); {
+	case n == 0:
+		Ack(ctx)
+	case n > 1:
+		ExpectAcks(ctx, n-1)
+	}
+
+	for i, operator := range outputs {
+		if i == len(outputs)-1 {
 			_ = operator.Process(ctx, e)
 			return
 		}
@@ -76,6 +112,43 @@ func (w *WriterOperator) Write(ctx context.Context, e *entry.Entry) {
 	}
 }
 
+// StartQueue will start the workers of the operator's queue, if one is configured.
+func (w *WriterOperator) StartQueue() {
+	if w.queue != nil {
+		w.queue.Start(w.write)
+	}
+}
+
+// StopQueue will wait for the operator's queue to drain and stop its workers.
+func (w *WriterOperator) StopQueue() {
+	if w.queue != nil {
+		w.queue.Stop()
+	}
+}
+
+// QueueStats returns a snapshot of the operator's queue, and false if no queue is configured.
+func (w *WriterOperator) QueueStats() (QueueStats, bool) {
+	if w.queue == nil {
+		return QueueStats{}, false
+	}
+	return w.queue.Stats(), true
+}
+
+// Metrics returns the operator's current metrics, including the length of its queue if one is configured.
+func (w *WriterOperator) Metrics() []operator.Metric {
+	metrics := w.BasicOperator.Metrics()
+	if stats, ok := w.QueueStats(); ok && w.Telemetry != nil {
+		metrics = append(metrics, operator.Metric{
+			Name:   MetricQueueLength,
+			Help:   "Entries waiting in the operator's queue.",
+			Type:   operator.GaugeMetric,
+			Labels: map[string]string{"operator_id": w.ID(), "operator_type": w.Type()},
+			Value:  float64(stats.Length),
+		})
+	}
+	return metrics
+}
+
 // CanOutput always returns true for a writer operator.
 func (w *WriterOperator) CanOutput() bool {
 	return true
@@ -83,6 +156,10 @@ func (w *WriterOperator) CanOutput() bool {
 
 // Outputs returns the outputs of the writer operator.
 func (w *WriterOperator) Outputs() []operator.Operator {
+	if w.outputsMux != nil {
+		w.outputsMux.RLock()
+		defer w.outputsMux.RUnlock()
+	}
 	return w.OutputOperators
 }
 
@@ -108,6 +185,10 @@ func (w *WriterOperator) SetOutputs(operators []operator.Operator) error {
 		outputOperators = append(outputOperators, operator)
 	}
 
+	if w.outputsMux != nil {
+		w.outputsMux.Lock()
+		defer w.outputsMux.Unlock()
+	}
 	w.OutputOperators = outputOperators
 	return nil
 }
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+// MetricType is the kind of value that a metric holds.
+type MetricType int
+
+const (
+	// CounterMetric is a value that only increases.
+	CounterMetric MetricType = iota
+	// GaugeMetric is a value that can increase or decrease.
+	GaugeMetric
+	// HistogramMetric is a distribution of observed values.
+	HistogramMetric
+)
+
+// String returns the name of the metric type.
+func (t MetricType) String() string {
+	switch t {
+	case CounterMetric:
+		return "counter"
+	case GaugeMetric:
+		return "gauge"
+	case HistogramMetric:
+		return "histogram"
+	default:
+		return "untyped"
+	}
+}
+
+// Metric is a single measurement reported by an operator.
+type Metric struct {
+	Name   string
+	Help   string
+	Type   MetricType
+	Labels map[string]string
+
+	// Value is the value of a counter or gauge.
+	Value float64
+	// Histogram is the value of a histogram.
+	Histogram *HistogramValue
+}
+
+// HistogramValue is a snapshot of a histogram.
+type HistogramValue struct {
+	// Bounds are the upper bounds of each bucket, in increasing order.
+	Bounds []float64
+	// Counts are the cumulative number of observations less than or equal to each bound.
+	Counts []uint64
+	// Count is the total number of observations.
+	Count uint64
+	// Sum is the sum of all observations.
+	Sum float64
+}
+
+// MetricsReporter is implemented by operators that report metrics about themselves.
+type MetricsReporter interface {
+	Metrics() []Metric
+}
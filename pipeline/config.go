@@ -25,20 +25,32 @@ type Config []operator.Config
 
 // BuildOperators builds the operators from the list of configs into operators.
 func (c Config) BuildOperators(bc operator.BuildContext, defaultOperator operator.Operator) ([]operator.Operator, error) {
+	operators, _, err := c.buildOperators(bc, defaultOperator)
+	return operators, err
+}
+
+// buildOperators builds the operators from the list of configs, along with the
+// config that each operator was built from, keyed by operator ID.
+func (c Config) buildOperators(bc operator.BuildContext, defaultOperator operator.Operator) ([]operator.Operator, map[string]operator.Builder, error) {
 	c.dedeplucateIDs()
 	// buildsMulti's key represents an operator's ID that builds multiple operators, e.g. Plugins.
 	// the value is the plugin's first operator's ID.
 	buildsMulti := make(map[string]string)
 	operators := make([]operator.Operator, 0, len(c))
+	sources := make(map[string]operator.Builder)
 	for _, builder := range c {
 		op, err := builder.Build(bc)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		if builder.BuildsMultipleOps() {
 			buildsMulti[bc.PrependNamespace(builder.ID())] = op[0].ID()
 		}
+
+		for _, o := range op {
+			sources[o.ID()] = builder.Builder
+		}
 		operators = append(operators, op...)
 	}
 
@@ -47,10 +59,10 @@ func (c Config) BuildOperators(bc operator.BuildContext, defaultOperator operato
 	}
 
 	if err := SetOutputIDs(operators, buildsMulti); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return operators, nil
+	return operators, sources, nil
 }
 
 func (c Config) dedeplucateIDs() {
@@ -81,12 +93,18 @@ func (c Config) dedeplucateIDs() {
 
 // BuildPipeline will build a pipeline from the config.
 func (c Config) BuildPipeline(bc operator.BuildContext, defaultOperator operator.Operator) (*DirectedPipeline, error) {
-	operators, err := c.BuildOperators(bc, defaultOperator)
+	operators, sources, err := c.buildOperators(bc, defaultOperator)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewDirectedPipeline(operators)
+	pipeline, err := NewDirectedPipeline(operators)
+	if err != nil {
+		return nil, err
+	}
+	pipeline.sources = sources
+	pipeline.config = c
+	return pipeline, nil
 }
 
 // SetOutputIDs Loops through all the operators and sets a default output to the next operator in the slice.
@@ -16,7 +16,9 @@ package pipeline
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"gonum.org/v1/gonum/graph/encoding/dot"
 	"gonum.org/v1/gonum/graph/simple"
@@ -27,49 +29,93 @@ import (
 )
 
 var _ Pipeline = (*DirectedPipeline)(nil)
+var _ MetricsProvider = (*DirectedPipeline)(nil)
 
 // DirectedPipeline is a pipeline backed by a directed graph
 type DirectedPipeline struct {
 	Graph *simple.DirectedGraph
+
+	// sources are the configs that each operator was built from, keyed by operator ID
+	sources map[string]operator.Builder
+	// config is the config the operators were built from, if the pipeline was built from one
+	config Config
+	mux    sync.RWMutex
+}
+
+// queuedOperator is implemented by operators that can write to their outputs through a queue
+type queuedOperator interface {
+	StartQueue()
+	StopQueue()
 }
 
 // Start will start the operators in a pipeline in reverse topological order
 func (p *DirectedPipeline) Start(persister operator.Persister) error {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
 	sortedNodes, _ := topo.Sort(p.Graph)
 	for i := len(sortedNodes) - 1; i >= 0; i-- {
-		op := sortedNodes[i].(OperatorNode).Operator()
-
-		scopedPersister := operator.NewScopedPersister(op.ID(), persister)
-		op.Logger().Debug("Starting operator")
-		if err := op.Start(scopedPersister); err != nil {
+		if err := startOperator(sortedNodes[i].(OperatorNode).Operator(), persister); err != nil {
 			return err
 		}
-		op.Logger().Debug("Started operator")
 	}
 
 	return nil
 }
 
-// Stop will stop the operators in a pipeline in topological order
+// Stop will stop the operators in a pipeline in topological order.
+// Each operator's queue is drained after it stops, while its outputs are still running.
 func (p *DirectedPipeline) Stop() error {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
 	sortedNodes, _ := topo.Sort(p.Graph)
 	for _, node := range sortedNodes {
-		operator := node.(OperatorNode).Operator()
-		operator.Logger().Debug("Stopping operator")
-		_ = operator.Stop()
-		operator.Logger().Debug("Stopped operator")
+		stopOperator(node.(OperatorNode).Operator())
 	}
 
 	return nil
 }
 
+// startOperator will start an operator and its queue, scoping its persister to its ID
+func startOperator(op operator.Operator, persister operator.Persister) error {
+	scopedPersister := operator.NewScopedPersister(op.ID(), persister)
+	op.Logger().Debug("Starting operator")
+	if queued, ok := op.(queuedOperator); ok {
+		queued.StartQueue()
+	}
+	if err := op.Start(scopedPersister); err != nil {
+		return err
+	}
+	op.Logger().Debug("Started operator")
+	return nil
+}
+
+// stopOperator will stop an operator, then drain its queue
+func stopOperator(op operator.Operator) {
+	op.Logger().Debug("Stopping operator")
+	_ = op.Stop()
+	if queued, ok := op.(queuedOperator); ok {
+		queued.StopQueue()
+	}
+	op.Logger().Debug("Stopped operator")
+}
+
 // Render will render the pipeline as a dot graph
 func (p *DirectedPipeline) Render() ([]byte, error) {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
 	return dot.Marshal(p.Graph, "G", "", " ")
 }
 
 // Operators returns a slice of operators that make up the pipeline graph
 func (p *DirectedPipeline) Operators() []operator.Operator {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.operators()
+}
+
+func (p *DirectedPipeline) operators() []operator.Operator {
 	operators := make([]operator.Operator, 0)
 	nodes := p.Graph.Nodes()
 	for nodes.Next() {
@@ -78,6 +124,25 @@ func (p *DirectedPipeline) Operators() []operator.Operator {
 	return operators
 }
 
+// Metrics returns the metrics reported by the operators in the pipeline, ordered by operator id
+func (p *DirectedPipeline) Metrics() []operator.Metric {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	operators := p.operators()
+	sort.Slice(operators, func(i, j int) bool {
+		return operators[i].ID() < operators[j].ID()
+	})
+
+	metrics := make([]operator.Metric, 0)
+	for _, op := range operators {
+		if reporter, ok := op.(operator.MetricsReporter); ok {
+			metrics = append(metrics, reporter.Metrics()...)
+		}
+	}
+	return metrics
+}
+
 // addNodes will add operators as nodes to the supplied graph.
 func addNodes(graph *simple.DirectedGraph, operators []operator.Operator) error {
 	for _, operator := range operators {
@@ -153,9 +218,9 @@ func connectNode(graph *simple.DirectedGraph, inputNode OperatorNode) error {
 	return nil
 }
 
-// setOperatorOutputs will set the outputs on operators that can output.
-func setOperatorOutputs(operators []operator.Operator) error {
-	for _, operator := range operators {
+// setOperatorOutputs will set the outputs on each of the targets that can output, choosing from operators.
+func setOperatorOutputs(targets, operators []operator.Operator) error {
+	for _, operator := range targets {
 		if !operator.CanOutput() {
 			continue
 		}
@@ -169,7 +234,7 @@ func setOperatorOutputs(operators []operator.Operator) error {
 
 // NewDirectedPipeline creates a new directed pipeline
 func NewDirectedPipeline(operators []operator.Operator) (*DirectedPipeline, error) {
-	if err := setOperatorOutputs(operators); err != nil {
+	if err := setOperatorOutputs(operators, operators); err != nil {
 		return nil, err
 	}
 
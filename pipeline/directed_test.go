@@ -15,6 +15,7 @@
 package pipeline
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -25,7 +26,10 @@ import (
 	"gonum.org/v1/gonum/graph/simple"
 	"gonum.org/v1/gonum/graph/topo"
 
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
 	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/transformer/noop"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
 	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
 )
 
@@ -315,3 +319,57 @@ func TestPipelineOperators(t *testing.T) {
 	operators := pipeline.Operators()
 	require.ElementsMatch(t, []operator.Operator{mockOperator1, mockOperator2, mockOperator3}, operators)
 }
+
+func TestPipelineQueuedOperator(t *testing.T) {
+	cfg := noop.NewNoopOperatorConfig("noop")
+	cfg.OutputIDs = []string{"$.fake"}
+	queueCfg := helper.NewQueueConfig()
+	cfg.Queue = &queueCfg
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	noopOp := ops[0]
+
+	fake := testutil.NewFakeOutput(t)
+	pipeline, err := NewDirectedPipeline([]operator.Operator{noopOp, fake})
+	require.NoError(t, err)
+
+	require.NoError(t, pipeline.Start(testutil.NewMockPersister("test")))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, noopOp.Process(context.Background(), &entry.Entry{Body: i}))
+	}
+	require.NoError(t, pipeline.Stop())
+
+	// Stopping the pipeline drains the queue
+	require.Len(t, fake.Received, 10)
+}
+
+func TestPipelineMetrics(t *testing.T) {
+	cfgB := noop.NewNoopOperatorConfig("noop_b")
+	cfgB.OutputIDs = []string{"$.fake"}
+	cfgA := noop.NewNoopOperatorConfig("noop_a")
+	cfgA.OutputIDs = []string{"$.noop_b"}
+
+	opsB, err := cfgB.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	opsA, err := cfgA.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	fake := testutil.NewFakeOutput(t)
+	pipeline, err := NewDirectedPipeline([]operator.Operator{opsB[0], fake, opsA[0]})
+	require.NoError(t, err)
+
+	require.NoError(t, opsA[0].Process(context.Background(), entry.New()))
+	fake.ExpectBody(t, nil)
+
+	// The fake output does not report metrics, so only the noop operators appear, in order of id
+	ids := make([]string, 0)
+	for _, metric := range pipeline.Metrics() {
+		if metric.Name != helper.MetricEntriesReceived {
+			continue
+		}
+		ids = append(ids, metric.Labels["operator_id"])
+		require.Equal(t, 1.0, metric.Value)
+	}
+	require.Equal(t, []string{"$.noop_a", "$.noop_b"}, ids)
+}
@@ -27,3 +27,8 @@ type Pipeline interface {
 	Operators() []operator.Operator
 	Render() ([]byte, error)
 }
+
+// MetricsProvider is a pipeline that reports the metrics of its operators
+type MetricsProvider interface {
+	Metrics() []operator.Metric
+}
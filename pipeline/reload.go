@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"reflect"
+
+	"gonum.org/v1/gonum/graph/simple"
+	"gonum.org/v1/gonum/graph/topo"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/errors"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+)
+
+// Reload will replace the operators of a running pipeline with the operators built from a new config.
+//
+// An operator is kept running if its config and outputs are unchanged. Every other operator is stopped
+// and replaced. Kept operators that write to a replaced operator are connected to its replacement once
+// it has started, so an input is not restarted when only an operator downstream of it changes.
+// Replacement operators are started with the same persister, so an operator that keeps its ID also
+// keeps its persisted state, such as file offsets.
+//
+// If the new config cannot be built into a valid pipeline, an error is returned and the running
+// pipeline is left untouched. If a replacement operator fails to start, the replacements are stopped
+// and the previous operators are restored. Since an operator may not support being started again
+// once stopped, the operators that were stopped are replaced with new instances built from the
+// previous config, when the pipeline was built from one.
+func (p *DirectedPipeline) Reload(bc operator.BuildContext, cfg Config, defaultOperator operator.Operator, persister operator.Persister) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	built, sources, err := cfg.buildOperators(bc, defaultOperator)
+	if err != nil {
+		return errors.Wrap(err, "build operators")
+	}
+
+	current := make(map[string]operator.Operator)
+	for _, op := range p.operators() {
+		current[op.ID()] = op
+	}
+	kept := p.unchangedOperators(current, built, sources)
+
+	operators := make([]operator.Operator, 0, len(built))
+	replacements := make([]operator.Operator, 0, len(built))
+	for _, op := range built {
+		if _, ok := kept[op.ID()]; ok {
+			operators = append(operators, current[op.ID()])
+			continue
+		}
+		operators = append(operators, op)
+		replacements = append(replacements, op)
+	}
+
+	// The kept operators are running, so they are only connected to the replacements they write to
+	// once the replacements have started
+	if err := setOperatorOutputs(replacements, operators); err != nil {
+		return err
+	}
+	repointed := writersTo(kept, current, replacements)
+
+	graph := simple.NewDirectedGraph()
+	if err := addNodes(graph, operators); err != nil {
+		return err
+	}
+	if err := connectNodes(graph); err != nil {
+		return err
+	}
+
+	// Stop the operators that are not kept, upstream operators first
+	oldNodes, _ := topo.Sort(p.Graph)
+	stopped := make([]operator.Operator, 0, len(oldNodes))
+	for _, node := range oldNodes {
+		op := node.(OperatorNode).Operator()
+		if _, ok := kept[op.ID()]; ok {
+			continue
+		}
+		stopOperator(op)
+		stopped = append(stopped, op)
+	}
+
+	// Start the replacements, downstream operators first
+	newNodes, _ := topo.Sort(graph)
+	started := make([]operator.Operator, 0, len(replacements))
+	for i := len(newNodes) - 1; i >= 0; i-- {
+		op := newNodes[i].(OperatorNode).Operator()
+		if _, ok := kept[op.ID()]; ok {
+			continue
+		}
+
+		if err := startOperator(op, persister); err != nil {
+			p.rollback(bc, defaultOperator, started, stopped, persister)
+			return errors.WithDetails(err, "operator_id", op.ID())
+		}
+		started = append(started, op)
+	}
+
+	// The graph was validated with the outputs of the kept operators, so they can be connected
+	if err := setOperatorOutputs(repointed, operators); err != nil {
+		bc.Logger.Errorw("Failed to connect operators to their replaced outputs", "error", err)
+	}
+
+	p.Graph = graph
+	p.sources = sources
+	p.config = cfg
+	return nil
+}
+
+// unchangedOperators returns the IDs of the running operators that can be kept after a reload.
+// An operator is kept if it has the same config and outputs as the operator built to replace it.
+func (p *DirectedPipeline) unchangedOperators(current map[string]operator.Operator, built []operator.Operator, sources map[string]operator.Builder) map[string]struct{} {
+	kept := make(map[string]struct{})
+	for _, op := range built {
+		old, ok := current[op.ID()]
+		if !ok {
+			continue
+		}
+
+		// An operator that is passed to every build, such as a default output, is the same instance
+		if old != op {
+			source, ok := sources[op.ID()]
+			if !ok || !reflect.DeepEqual(source, p.sources[op.ID()]) || old.Type() != op.Type() {
+				continue
+			}
+			if !equalIDs(old.GetOutputIDs(), op.GetOutputIDs()) {
+				continue
+			}
+		}
+		kept[op.ID()] = struct{}{}
+	}
+	return kept
+}
+
+// writersTo returns the kept operators that write to any of the targets
+func writersTo(kept map[string]struct{}, current map[string]operator.Operator, targets []operator.Operator) []operator.Operator {
+	targetIDs := make(map[string]struct{}, len(targets))
+	for _, op := range targets {
+		targetIDs[op.ID()] = struct{}{}
+	}
+
+	writers := make([]operator.Operator, 0)
+	for id := range kept {
+		for _, outputID := range current[id].GetOutputIDs() {
+			if _, ok := targetIDs[outputID]; ok {
+				writers = append(writers, current[id])
+				break
+			}
+		}
+	}
+	return writers
+}
+
+// rollback will stop the operators started during a failed reload, then restore the operators it stopped
+func (p *DirectedPipeline) rollback(bc operator.BuildContext, defaultOperator operator.Operator, started, stopped []operator.Operator, persister operator.Persister) {
+	for i := len(started) - 1; i >= 0; i-- {
+		stopOperator(started[i])
+	}
+
+	if p.config != nil {
+		rebuilt, err := p.rebuildOperators(bc, defaultOperator, stopped)
+		if err != nil {
+			bc.Logger.Errorw("Failed to rebuild operators after failed reload, restarting the stopped operators instead", "error", err)
+		} else {
+			stopped = rebuilt
+		}
+	}
+
+	for i := len(stopped) - 1; i >= 0; i-- {
+		if err := startOperator(stopped[i], persister); err != nil {
+			stopped[i].Logger().Errorw("Failed to restart operator after failed reload", "error", err)
+		}
+	}
+}
+
+// rebuildOperators builds new instances of the stopped operators from the config of the pipeline,
+// and replaces the stopped operators with them in the graph. The new instances are returned in
+// the order of the stopped operators.
+func (p *DirectedPipeline) rebuildOperators(bc operator.BuildContext, defaultOperator operator.Operator, stopped []operator.Operator) ([]operator.Operator, error) {
+	built, _, err := p.config.buildOperators(bc, defaultOperator)
+	if err != nil {
+		return nil, errors.Wrap(err, "build operators")
+	}
+
+	builtByID := make(map[string]operator.Operator, len(built))
+	for _, op := range built {
+		builtByID[op.ID()] = op
+	}
+
+	replaced := make(map[string]operator.Operator, len(stopped))
+	rebuilt := make([]operator.Operator, 0, len(stopped))
+	for _, op := range stopped {
+		rebuiltOp, ok := builtByID[op.ID()]
+		if !ok {
+			return nil, errors.NewError("operator missing from rebuilt config", "", "operator_id", op.ID())
+		}
+		replaced[op.ID()] = rebuiltOp
+		rebuilt = append(rebuilt, rebuiltOp)
+	}
+
+	operators := p.operators()
+	for i, op := range operators {
+		if rebuiltOp, ok := replaced[op.ID()]; ok {
+			operators[i] = rebuiltOp
+		}
+	}
+
+	// The running operators that write to a stopped operator are connected to its new instance
+	if err := setOperatorOutputs(operators, operators); err != nil {
+		return nil, err
+	}
+
+	graph := simple.NewDirectedGraph()
+	if err := addNodes(graph, operators); err != nil {
+		return nil, err
+	}
+	if err := connectNodes(graph); err != nil {
+		return nil, err
+	}
+
+	p.Graph = graph
+	return rebuilt, nil
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
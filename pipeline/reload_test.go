@@ -0,0 +1,273 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-log-collection/entry"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/input/tcp"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/output/file"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/transformer/add"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/builtin/transformer/noop"
+	"github.com/open-telemetry/opentelemetry-log-collection/operator/helper"
+	"github.com/open-telemetry/opentelemetry-log-collection/testutil"
+)
+
+// newReloadConfig creates a pipeline that adds value to each entry, then passes it through a noop operator
+func newReloadConfig(value string) Config {
+	addCfg := add.NewAddOperatorConfig("add")
+	addCfg.Field = entry.NewAttributeField("value")
+	addCfg.Value = value
+	return Config{
+		operator.Config{Builder: addCfg},
+		operator.Config{Builder: noop.NewNoopOperatorConfig("noop")},
+	}
+}
+
+func operatorsByID(p *DirectedPipeline) map[string]operator.Operator {
+	operators := make(map[string]operator.Operator)
+	for _, op := range p.Operators() {
+		operators[op.ID()] = op
+	}
+	return operators
+}
+
+func newReloadPipeline(t *testing.T, cfg Config) (*DirectedPipeline, *testutil.FakeOutput) {
+	fake := testutil.NewFakeOutput(t)
+	p, err := cfg.BuildPipeline(testutil.NewBuildContext(t), fake)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(testutil.NewMockPersister("test")))
+	t.Cleanup(func() { require.NoError(t, p.Stop()) })
+	return p, fake
+}
+
+func expectValue(t *testing.T, p *DirectedPipeline, fake *testutil.FakeOutput, value string) {
+	require.NoError(t, operatorsByID(p)["$.add"].Process(context.Background(), entry.New()))
+	select {
+	case e := <-fake.Received:
+		require.Equal(t, value, e.Attributes["value"])
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
+
+func TestReloadUnchanged(t *testing.T) {
+	p, fake := newReloadPipeline(t, newReloadConfig("one"))
+	before := operatorsByID(p)
+
+	err := p.Reload(testutil.NewBuildContext(t), newReloadConfig("one"), fake, testutil.NewMockPersister("test"))
+	require.NoError(t, err)
+
+	require.Equal(t, before, operatorsByID(p))
+	expectValue(t, p, fake, "one")
+}
+
+func TestReloadReplacesChangedOperators(t *testing.T) {
+	p, fake := newReloadPipeline(t, newReloadConfig("one"))
+	before := operatorsByID(p)
+
+	err := p.Reload(testutil.NewBuildContext(t), newReloadConfig("two"), fake, testutil.NewMockPersister("test"))
+	require.NoError(t, err)
+
+	after := operatorsByID(p)
+	require.Len(t, after, 3)
+	require.NotSame(t, before["$.add"], after["$.add"])
+	require.Same(t, before["$.noop"], after["$.noop"])
+	require.Same(t, before["$.fake"], after["$.fake"])
+	expectValue(t, p, fake, "two")
+}
+
+func TestReloadReconnectsUpstreamOfChangedOperators(t *testing.T) {
+	p, fake := newReloadPipeline(t, newReloadConfig("one"))
+	before := operatorsByID(p)
+
+	// Inserting an operator changes the output of the noop operator, so it is replaced, and the
+	// add operator that writes to it is kept and connected to the replacement
+	cfg := newReloadConfig("one")
+	cfg = append(cfg, operator.Config{Builder: noop.NewNoopOperatorConfig("last")})
+	err := p.Reload(testutil.NewBuildContext(t), cfg, fake, testutil.NewMockPersister("test"))
+	require.NoError(t, err)
+
+	after := operatorsByID(p)
+	require.Len(t, after, 4)
+	require.Same(t, before["$.add"], after["$.add"])
+	require.NotSame(t, before["$.noop"], after["$.noop"])
+	require.Same(t, before["$.fake"], after["$.fake"])
+	require.Equal(t, []string{"$.last"}, after["$.noop"].GetOutputIDs())
+	require.Equal(t, []operator.Operator{after["$.noop"]}, after["$.add"].Outputs())
+	expectValue(t, p, fake, "one")
+}
+
+func TestReloadKeepsInputWhenOutputChanges(t *testing.T) {
+	tempDir := t.TempDir()
+	newConfig := func(address, path string) Config {
+		tcpCfg := tcp.NewTCPInputConfig("tcp")
+		tcpCfg.ListenAddress = address
+		tcpCfg.OutputIDs = []string{"file"}
+		fileCfg := file.NewFileOutputConfig("file")
+		fileCfg.Path = filepath.Join(tempDir, path)
+		return Config{operator.Config{Builder: tcpCfg}, operator.Config{Builder: fileCfg}}
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	p, fake := newReloadPipeline(t, newConfig(address, "first.log"))
+	before := operatorsByID(p)
+
+	err = p.Reload(testutil.NewBuildContext(t), newConfig(address, "second.log"), fake, testutil.NewMockPersister("test"))
+	require.NoError(t, err)
+
+	after := operatorsByID(p)
+	require.Same(t, before["$.tcp"], after["$.tcp"])
+	require.NotSame(t, before["$.file"], after["$.file"])
+
+	// The input keeps its connection, and writes to the replaced output
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("message\n"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		contents, err := ioutil.ReadFile(filepath.Join(tempDir, "second.log"))
+		return err == nil && strings.Contains(string(contents), "message")
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestReloadRemovesOperators(t *testing.T) {
+	p, fake := newReloadPipeline(t, newReloadConfig("one"))
+
+	err := p.Reload(testutil.NewBuildContext(t), newReloadConfig("one")[:1], fake, testutil.NewMockPersister("test"))
+	require.NoError(t, err)
+
+	after := operatorsByID(p)
+	require.Len(t, after, 2)
+	require.NotContains(t, after, "$.noop")
+	require.Equal(t, []string{"$.fake"}, after["$.add"].GetOutputIDs())
+	expectValue(t, p, fake, "one")
+}
+
+func TestReloadInvalidConfig(t *testing.T) {
+	p, fake := newReloadPipeline(t, newReloadConfig("one"))
+	before := operatorsByID(p)
+
+	cfg := newReloadConfig("two")
+	cfg[0].Builder.(*add.AddOperatorConfig).OutputIDs = []string{"missing"}
+	err := p.Reload(testutil.NewBuildContext(t), cfg, fake, testutil.NewMockPersister("test"))
+	require.Error(t, err)
+
+	require.Equal(t, before, operatorsByID(p))
+	expectValue(t, p, fake, "one")
+}
+
+func TestReloadStartFailure(t *testing.T) {
+	p, fake := newReloadPipeline(t, newReloadConfig("one"))
+	before := operatorsByID(p)
+
+	// The tcp input fails to start, because its address is already in use
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	tcpCfg := tcp.NewTCPInputConfig("tcp")
+	tcpCfg.ListenAddress = listener.Addr().String()
+
+	cfg := append(Config{operator.Config{Builder: tcpCfg}}, newReloadConfig("two")...)
+	err = p.Reload(testutil.NewBuildContext(t), cfg, fake, testutil.NewMockPersister("test"))
+	require.Error(t, err)
+
+	// The stopped operators are replaced with new instances of the previous config
+	after := operatorsByID(p)
+	require.Len(t, after, len(before))
+	require.NotSame(t, before["$.add"], after["$.add"])
+	require.Same(t, before["$.noop"], after["$.noop"])
+	expectValue(t, p, fake, "one")
+}
+
+// startOnceConfig builds an operator that cannot be started again once stopped
+type startOnceConfig struct {
+	helper.TransformerConfig
+	Value string
+}
+
+func (c startOnceConfig) Build(bc operator.BuildContext) ([]operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(bc)
+	if err != nil {
+		return nil, err
+	}
+	return []operator.Operator{&startOnceOperator{TransformerOperator: transformer}}, nil
+}
+
+type startOnceOperator struct {
+	helper.TransformerOperator
+	started bool
+}
+
+func (o *startOnceOperator) Start(_ operator.Persister) error {
+	if o.started {
+		return fmt.Errorf("already started")
+	}
+	o.started = true
+	return nil
+}
+
+func (o *startOnceOperator) Process(ctx context.Context, e *entry.Entry) error {
+	o.Write(ctx, e)
+	return nil
+}
+
+func TestReloadStartFailureRebuildsStoppedOperators(t *testing.T) {
+	newConfig := func(value string) Config {
+		return Config{operator.Config{Builder: &startOnceConfig{
+			TransformerConfig: helper.NewTransformerConfig("once", "once"),
+			Value:             value,
+		}}}
+	}
+	p, fake := newReloadPipeline(t, newConfig("one"))
+	before := operatorsByID(p)["$.once"]
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	tcpCfg := tcp.NewTCPInputConfig("tcp")
+	tcpCfg.ListenAddress = listener.Addr().String()
+
+	cfg := append(Config{operator.Config{Builder: tcpCfg}}, newConfig("two")...)
+	require.Error(t, p.Reload(testutil.NewBuildContext(t), cfg, fake, testutil.NewMockPersister("test")))
+
+	after := operatorsByID(p)["$.once"].(*startOnceOperator)
+	require.NotSame(t, before, after)
+	require.True(t, after.started)
+
+	require.NoError(t, after.Process(context.Background(), entry.New()))
+	select {
+	case <-fake.Received:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+	}
+}
@@ -12,6 +12,22 @@ type Pipeline struct {
 	mock.Mock
 }
 
+// Metrics provides a mock function with given fields:
+func (_m *Pipeline) Metrics() []operator.Metric {
+	ret := _m.Called()
+
+	var r0 []operator.Metric
+	if rf, ok := ret.Get(0).(func() []operator.Metric); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]operator.Metric)
+		}
+	}
+
+	return r0
+}
+
 // Operators provides a mock function with given fields:
 func (_m *Pipeline) Operators() []operator.Operator {
 	ret := _m.Called()